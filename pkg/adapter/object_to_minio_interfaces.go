@@ -19,6 +19,7 @@ import (
 
 	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/sunsingerus/tbox/pkg/minio"
+	"github.com/sunsingerus/tbox/pkg/store"
 )
 
 // ObjectToMinIOAdapter is an interface of an entity which can adapt an object to MinIO storage
@@ -43,4 +44,8 @@ type ObjectToMinIOAdapter interface {
 	FirstObject(path ...Folder) (*common.S3Address, error)
 	// WalkObjects walks files with function.
 	WalkObjects(f func(int, *minio.MinIO, *minio_go.ObjectInfo, *common.S3Address) bool, path ...Folder) error
+	// GetStore returns object store the objects are stored in
+	GetStore() (store.ObjectStore, error)
+	// WalkStoreObjects walks files with function. The same as WalkObjects, but works with any object store
+	WalkStoreObjects(f func(int, store.ObjectStore, *store.ObjectInfo, *common.S3Address) bool, path ...Folder) error
 }
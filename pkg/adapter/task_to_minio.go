@@ -23,12 +23,18 @@ import (
 	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/sunsingerus/tbox/pkg/config/sections"
 	"github.com/sunsingerus/tbox/pkg/minio"
+	"github.com/sunsingerus/tbox/pkg/store"
 )
 
-// TaskMinIO specifies task to MinIO adapter
+// TaskMinIO specifies task to MinIO adapter.
+// Despite the name, folder layout of the task can be used on top of any object store, see NewTaskStoreAdapter.
 type TaskMinIO struct {
 	Config sections.MinIOConfigurator
 	Task   *common.Task
+	// Store specifies object store. MinIO specified by Config is used in case no store specified
+	Store store.ObjectStore
+	// Bucket specifies bucket within the Store. Bucket specified by Config is used in case no bucket specified
+	Bucket string
 }
 
 // Interface compliance check
@@ -44,6 +50,35 @@ func NewTaskMinIOAdapter(config sections.MinIOConfigurator, task *common.Task) *
 	}
 }
 
+// NewTaskStoreAdapter creates new Task adapter to the bucket of the object store
+func NewTaskStoreAdapter(s store.ObjectStore, bucket string, task *common.Task) *TaskMinIO {
+	return &TaskMinIO{
+		Task:   task,
+		Store:  s,
+		Bucket: bucket,
+	}
+}
+
+// SetStore sets object store
+func (a *TaskMinIO) SetStore(s store.ObjectStore) *TaskMinIO {
+	if a == nil {
+		return nil
+	}
+	a.Store = s
+	return a
+}
+
+// GetStore is an ObjectToMinIOAdapter interface function
+func (a *TaskMinIO) GetStore() (store.ObjectStore, error) {
+	if a.Store != nil {
+		return a.Store, nil
+	}
+	if a.Config == nil {
+		return nil, fmt.Errorf("neither object store nor MinIO config specified")
+	}
+	return minio.NewObjectStoreFromConfig(a.Config)
+}
+
 // Folder specifies folders of the adapter.
 // Base functions operate with any folder, thus custom folders can be used as well
 type Folder string
@@ -59,6 +94,9 @@ const (
 
 // GetBucket is an ObjectToMinIOAdapter interface function
 func (a *TaskMinIO) GetBucket() string {
+	if (a.Bucket != "") || (a.Config == nil) {
+		return a.Bucket
+	}
 	return a.Config.GetMinIOBucket()
 }
 
@@ -134,12 +172,12 @@ func (a *TaskMinIO) GetObjectAddress(object string, base bool, path ...Folder) *
 
 // FirstObject is an ObjectToMinIOAdapter interface function
 func (a *TaskMinIO) FirstObject(path ...Folder) (*common.S3Address, error) {
-	mi, err := minio.NewMinIOFromConfig(a.Config)
+	s, err := a.GetStore()
 	if err != nil {
 		return nil, err
 	}
 	bucket := a.GetBucket()
-	list, err := mi.List(bucket, a.GetPrefix(path...), 1)
+	list, err := s.List(bucket, a.GetPrefix(path...), 1)
 	if err != nil {
 		return nil, err
 	}
@@ -148,8 +186,7 @@ func (a *TaskMinIO) FirstObject(path ...Folder) (*common.S3Address, error) {
 		return nil, fmt.Errorf("not found")
 	}
 
-	info := list[0]
-	return common.NewS3Address(bucket, info.Key), nil
+	return list[0].Address, nil
 }
 
 // WalkObjects is an ObjectToMinIOAdapter interface function
@@ -174,6 +211,25 @@ func (a *TaskMinIO) WalkObjects(f func(int, *minio.MinIO, *minioGo.ObjectInfo, *
 	return nil
 }
 
+// WalkStoreObjects is an ObjectToMinIOAdapter interface function
+func (a *TaskMinIO) WalkStoreObjects(f func(int, store.ObjectStore, *store.ObjectInfo, *common.S3Address) bool, path ...Folder) error {
+	s, err := a.GetStore()
+	if err != nil {
+		return err
+	}
+	list, err := s.List(a.GetBucket(), a.GetPrefix(path...), -1)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range list {
+		if !f(len(list), s, info, info.Address) {
+			break
+		}
+	}
+	return nil
+}
+
 /***********************/
 /*     Wrappers        */
 /***********************/
@@ -183,6 +239,16 @@ func (a *TaskMinIO) GetTaskPath() string {
 	return a.GetPath(task)
 }
 
+// GetTaskFile gets full file path, relative to the whole task
+func (a *TaskMinIO) GetTaskFile(file string) string {
+	return a.GetObjectPath(file, false, task)
+}
+
+// GetTaskFileAddress gets file S3Address, relative to the whole task
+func (a *TaskMinIO) GetTaskFileAddress(file string) *common.S3Address {
+	return a.GetObjectAddress(file, false, task)
+}
+
 // GetInPath gets `in` path
 func (a *TaskMinIO) GetInPath() string {
 	return a.GetPath(in)
@@ -218,6 +284,11 @@ func (a *TaskMinIO) WalkInFiles(f func(int, *minio.MinIO, *minioGo.ObjectInfo, *
 	return a.WalkObjects(f, in)
 }
 
+// WalkInStoreFiles walks `in` files of the object store
+func (a *TaskMinIO) WalkInStoreFiles(f func(int, store.ObjectStore, *store.ObjectInfo, *common.S3Address) bool) error {
+	return a.WalkStoreObjects(f, in)
+}
+
 // GetOutPath gets `out` path
 func (a *TaskMinIO) GetOutPath() string {
 	return a.GetPath(out)
@@ -253,6 +324,11 @@ func (a *TaskMinIO) WalkOutFiles(f func(int, *minio.MinIO, *minioGo.ObjectInfo,
 	return a.WalkObjects(f, out)
 }
 
+// WalkOutStoreFiles walks `out` files of the object store
+func (a *TaskMinIO) WalkOutStoreFiles(f func(int, store.ObjectStore, *store.ObjectInfo, *common.S3Address) bool) error {
+	return a.WalkStoreObjects(f, out)
+}
+
 // GetResultPath gets `result` path
 func (a *TaskMinIO) GetResultPath() string {
 	return a.GetPath(result)
@@ -288,6 +364,11 @@ func (a *TaskMinIO) WalkResultFiles(f func(int, *minio.MinIO, *minioGo.ObjectInf
 	return a.WalkObjects(f, result)
 }
 
+// WalkResultStoreFiles walks `result` files of the object store
+func (a *TaskMinIO) WalkResultStoreFiles(f func(int, store.ObjectStore, *store.ObjectInfo, *common.S3Address) bool) error {
+	return a.WalkStoreObjects(f, result)
+}
+
 // GetTmpPath gets `tmp` path
 func (a *TaskMinIO) GetTmpPath() string {
 	return a.GetPath(tmp)
@@ -322,3 +403,8 @@ func (a *TaskMinIO) FirstTmpFile() (*common.S3Address, error) {
 func (a *TaskMinIO) WalkTmpFiles(f func(int, *minio.MinIO, *minioGo.ObjectInfo, *common.S3Address) bool) error {
 	return a.WalkObjects(f, tmp)
 }
+
+// WalkTmpStoreFiles walks `tmp` files of the object store
+func (a *TaskMinIO) WalkTmpStoreFiles(f func(int, store.ObjectStore, *store.ObjectInfo, *common.S3Address) bool) error {
+	return a.WalkStoreObjects(f, tmp)
+}
@@ -0,0 +1,94 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import "fmt"
+
+// NewBandwidthLimit
+func NewBandwidthLimit() *BandwidthLimit {
+	return &BandwidthLimit{}
+}
+
+// HasStream
+func (x *BandwidthLimit) HasStream() bool {
+	if x == nil {
+		return false
+	}
+	return x.Stream != nil
+}
+
+// SetStream
+func (x *BandwidthLimit) SetStream(stream int64) *BandwidthLimit {
+	if x == nil {
+		return nil
+	}
+	x.Stream = new(int64)
+	*x.Stream = stream
+	return x
+}
+
+// HasProcess
+func (x *BandwidthLimit) HasProcess() bool {
+	if x == nil {
+		return false
+	}
+	return x.Process != nil
+}
+
+// SetProcess
+func (x *BandwidthLimit) SetProcess(process int64) *BandwidthLimit {
+	if x == nil {
+		return nil
+	}
+	x.Process = new(int64)
+	*x.Process = process
+	return x
+}
+
+// HasBurst
+func (x *BandwidthLimit) HasBurst() bool {
+	if x == nil {
+		return false
+	}
+	return x.Burst != nil
+}
+
+// SetBurst
+func (x *BandwidthLimit) SetBurst(burst int64) *BandwidthLimit {
+	if x == nil {
+		return nil
+	}
+	x.Burst = new(int64)
+	*x.Burst = burst
+	return x
+}
+
+// NewTask creates new TaskThrottle task, which carries the bandwidth limit
+func (x *BandwidthLimit) NewTask() (*Task, error) {
+	task := NewTask()
+	task.EnsureHeader().SetType(TaskThrottle)
+	if err := task.SetPayload(x); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// String
+func (x *BandwidthLimit) String() string {
+	if x == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("stream:%d process:%d burst:%d", x.GetStream(), x.GetProcess(), x.GetBurst())
+}
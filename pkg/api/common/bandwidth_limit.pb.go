@@ -0,0 +1,187 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//*
+// BandwidthLimit represents limits of the bandwidth of outgoing data.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: api/common/bandwidth_limit.proto
+
+package common
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BandwidthLimit represents limits of the bandwidth of outgoing data in bytes per second.
+// Zero means no limit.
+type BandwidthLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Stream limits each stream individually
+	Stream *int64 `protobuf:"varint,100,opt,name=stream,proto3,oneof" json:"stream,omitempty"`
+	// Process limits all streams of the process altogether
+	Process *int64 `protobuf:"varint,200,opt,name=process,proto3,oneof" json:"process,omitempty"`
+	// Burst specifies how many bytes can be sent at once above the limit
+	Burst *int64 `protobuf:"varint,300,opt,name=burst,proto3,oneof" json:"burst,omitempty"`
+}
+
+func (x *BandwidthLimit) Reset() {
+	*x = BandwidthLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_common_bandwidth_limit_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (*BandwidthLimit) ProtoMessage() {}
+
+func (x *BandwidthLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_common_bandwidth_limit_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BandwidthLimit.ProtoReflect.Descriptor instead.
+func (*BandwidthLimit) Descriptor() ([]byte, []int) {
+	return file_api_common_bandwidth_limit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BandwidthLimit) GetStream() int64 {
+	if x != nil && x.Stream != nil {
+		return *x.Stream
+	}
+	return 0
+}
+
+func (x *BandwidthLimit) GetProcess() int64 {
+	if x != nil && x.Process != nil {
+		return *x.Process
+	}
+	return 0
+}
+
+func (x *BandwidthLimit) GetBurst() int64 {
+	if x != nil && x.Burst != nil {
+		return *x.Burst
+	}
+	return 0
+}
+
+var File_api_common_bandwidth_limit_proto protoreflect.FileDescriptor
+
+var file_api_common_bandwidth_limit_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x62, 0x61, 0x6e,
+	0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0a, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x22, 0x8a,
+	0x01, 0x0a, 0x0e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x1b, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x64, 0x20, 0x01, 0x28,
+	0x03, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x88, 0x01, 0x01, 0x12, 0x1e,
+	0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x18, 0xc8, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x01, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1a,
+	0x0a, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x18, 0xac, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x02,
+	0x52, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x73,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x62, 0x75, 0x72, 0x73, 0x74, 0x42, 0x2c, 0x5a, 0x2a, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x75, 0x6e, 0x73, 0x69, 0x6e,
+	0x67, 0x65, 0x72, 0x75, 0x73, 0x2f, 0x74, 0x62, 0x6f, 0x78, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61,
+	0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_api_common_bandwidth_limit_proto_rawDescOnce sync.Once
+	file_api_common_bandwidth_limit_proto_rawDescData = file_api_common_bandwidth_limit_proto_rawDesc
+)
+
+func file_api_common_bandwidth_limit_proto_rawDescGZIP() []byte {
+	file_api_common_bandwidth_limit_proto_rawDescOnce.Do(func() {
+		file_api_common_bandwidth_limit_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_common_bandwidth_limit_proto_rawDescData)
+	})
+	return file_api_common_bandwidth_limit_proto_rawDescData
+}
+
+var file_api_common_bandwidth_limit_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_common_bandwidth_limit_proto_goTypes = []interface{}{
+	(*BandwidthLimit)(nil), // 0: api.common.BandwidthLimit
+}
+var file_api_common_bandwidth_limit_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_api_common_bandwidth_limit_proto_init() }
+func file_api_common_bandwidth_limit_proto_init() {
+	if File_api_common_bandwidth_limit_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_common_bandwidth_limit_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BandwidthLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_api_common_bandwidth_limit_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_common_bandwidth_limit_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_common_bandwidth_limit_proto_goTypes,
+		DependencyIndexes: file_api_common_bandwidth_limit_proto_depIdxs,
+		MessageInfos:      file_api_common_bandwidth_limit_proto_msgTypes,
+	}.Build()
+	File_api_common_bandwidth_limit_proto = out.File
+	file_api_common_bandwidth_limit_proto_rawDesc = nil
+	file_api_common_bandwidth_limit_proto_goTypes = nil
+	file_api_common_bandwidth_limit_proto_depIdxs = nil
+}
@@ -15,20 +15,26 @@
 package common
 
 const (
-	CompressionTypeNone = 0
-	CompressionTypeLZMA = 100
+	CompressionTypeNone  = 0
+	CompressionTypeLZMA  = 100
+	CompressionTypeGzip  = 200
+	CompressionTypeZlib  = 300
+	CompressionTypeFlate = 400
 )
 
 var CompressionTypeEnum = NewEnum()
 
 func init() {
 	CompressionTypeEnum.MustRegister("none", CompressionTypeNone)
-	CompressionTypeEnum.MustRegister("lzma", CompressionTypeLZMA)
+	// Compression types with codecs are registered along with their codecs
 }
 
 var (
-	CompressionNone *Compression = nil
-	CompressionLZMA *Compression = NewCompression(CompressionTypeLZMA)
+	CompressionNone  *Compression = nil
+	CompressionLZMA  *Compression = NewCompression(CompressionTypeLZMA)
+	CompressionGzip  *Compression = NewCompression(CompressionTypeGzip)
+	CompressionZlib  *Compression = NewCompression(CompressionTypeZlib)
+	CompressionFlate *Compression = NewCompression(CompressionTypeFlate)
 )
 
 // NewCompression
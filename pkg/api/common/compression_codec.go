@@ -0,0 +1,109 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// CompressionCodec describes how to compress and decompress data of particular compression type
+type CompressionCodec struct {
+	// NewReader creates reader, which reads compressed data from `reader` and provides inflated data
+	NewReader func(reader io.Reader) (io.Reader, error)
+	// NewWriter creates writer, which deflates data and writes compressed data into `writer`
+	NewWriter func(writer io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	compressionCodecsMutex sync.RWMutex
+	compressionCodecs      = make(map[int32]*CompressionCodec)
+)
+
+// RegisterCompressionCodec registers codec for the compression type specified by `_type` and `name`.
+// Name is registered in CompressionTypeEnum. Third-party codecs can be registered the same way.
+func RegisterCompressionCodec(name string, _type int32, codec *CompressionCodec) error {
+	if (codec == nil) || (codec.NewReader == nil) || (codec.NewWriter == nil) {
+		return fmt.Errorf("incomplete compression codec %s", name)
+	}
+
+	compressionCodecsMutex.Lock()
+	defer compressionCodecsMutex.Unlock()
+
+	if _, ok := compressionCodecs[_type]; ok {
+		return fmt.Errorf("compression codec %d is already registered", _type)
+	}
+	if !CompressionTypeEnum.Register(name, _type) {
+		return fmt.Errorf("unable to register compression type %s:%d", name, _type)
+	}
+	compressionCodecs[_type] = codec
+	return nil
+}
+
+// MustRegisterCompressionCodec registers compression codec and panics in case of failure
+func MustRegisterCompressionCodec(name string, _type int32, codec *CompressionCodec) {
+	if err := RegisterCompressionCodec(name, _type, codec); err != nil {
+		panic(err)
+	}
+}
+
+// GetCompressionCodec gets codec of the specified compression type. Returns nil in case no codec registered
+func GetCompressionCodec(_type int32) *CompressionCodec {
+	compressionCodecsMutex.RLock()
+	defer compressionCodecsMutex.RUnlock()
+
+	return compressionCodecs[_type]
+}
+
+func init() {
+	MustRegisterCompressionCodec("lzma", CompressionTypeLZMA, &CompressionCodec{
+		NewReader: func(reader io.Reader) (io.Reader, error) {
+			return lzma.NewReader(reader)
+		},
+		NewWriter: func(writer io.Writer) (io.WriteCloser, error) {
+			return lzma.NewWriter(writer)
+		},
+	})
+	MustRegisterCompressionCodec("gzip", CompressionTypeGzip, &CompressionCodec{
+		NewReader: func(reader io.Reader) (io.Reader, error) {
+			return gzip.NewReader(reader)
+		},
+		NewWriter: func(writer io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(writer), nil
+		},
+	})
+	MustRegisterCompressionCodec("zlib", CompressionTypeZlib, &CompressionCodec{
+		NewReader: func(reader io.Reader) (io.Reader, error) {
+			return zlib.NewReader(reader)
+		},
+		NewWriter: func(writer io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(writer), nil
+		},
+	})
+	MustRegisterCompressionCodec("flate", CompressionTypeFlate, &CompressionCodec{
+		NewReader: func(reader io.Reader) (io.Reader, error) {
+			return flate.NewReader(reader), nil
+		},
+		NewWriter: func(writer io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(writer, flate.DefaultCompression)
+		},
+	})
+}
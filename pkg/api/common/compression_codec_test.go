@@ -0,0 +1,121 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// nopWriteCloser is an io.WriteCloser, which does nothing on Close
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close is an io.Closer interface function
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestRegisterCompressionCodec(t *testing.T) {
+	identity := &CompressionCodec{
+		NewReader: func(reader io.Reader) (io.Reader, error) {
+			return reader, nil
+		},
+		NewWriter: func(writer io.Writer) (io.WriteCloser, error) {
+			return nopWriteCloser{writer}, nil
+		},
+	}
+
+	tests := []struct {
+		name  string
+		_type int32
+		codec *CompressionCodec
+		ok    bool
+	}{
+		{name: "nil", _type: 901, codec: nil},
+		{name: "no reader", _type: 902, codec: &CompressionCodec{NewWriter: identity.NewWriter}},
+		{name: "no writer", _type: 903, codec: &CompressionCodec{NewReader: identity.NewReader}},
+		{name: "identity", _type: CompressionTypeGzip, codec: identity},
+		{name: "gzip", _type: 904, codec: identity},
+		{name: "none", _type: CompressionTypeNone, codec: identity},
+		{name: "identity", _type: 905, codec: identity, ok: true},
+		{name: "identity", _type: 905, codec: identity},
+	}
+	for _, test := range tests {
+		err := RegisterCompressionCodec(test.name, test._type, test.codec)
+		if (err == nil) != test.ok {
+			t.Fatalf("%s:%d: unexpected err: %v", test.name, test._type, err)
+		}
+	}
+	// Rejected codecs leave registry intact
+	if (GetCompressionCodec(CompressionTypeGzip) == identity) || (GetCompressionCodec(904) != nil) {
+		t.Fatalf("rejected codec is registered")
+	}
+	if (GetCompressionCodec(905) != identity) || (NewCompression(905).GetName() != "identity") {
+		t.Fatalf("codec is not registered")
+	}
+}
+
+func TestCompressionCodecs(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 10*1024)
+	tests := []struct {
+		name        string
+		compression *Compression
+	}{
+		{name: "lzma", compression: CompressionLZMA},
+		{name: "gzip", compression: CompressionGzip},
+		{name: "zlib", compression: CompressionZlib},
+		{name: "flate", compression: CompressionFlate},
+	}
+	for _, test := range tests {
+		q := send(t, data, NewDataPacketFileOptions().SetChunkSize(4096).SetCompression(test.compression))
+		sent := 0
+		for _, packet := range q.packets {
+			sent += len(packet.GetData())
+		}
+		if sent >= len(data) {
+			t.Fatalf("%s: %d bytes sent for %d bytes of data", test.name, sent, len(data))
+		}
+		received, _, err := receive(t, q, NewDataPacketFileOptions().SetDecompress(true))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !bytes.Equal(received, data) {
+			t.Fatalf("%s: received data differ from sent", test.name)
+		}
+	}
+
+	// Deprecated LZMA fields are set up along with the generic ones
+	buf := &bytes.Buffer{}
+	w, err := NewCompressor(CompressionNone, nil, CompressionLZMA, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.LZMAWriter.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewCompressor(CompressionLZMA, buf, CompressionNone, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received, err := io.ReadAll(r.LZMAReader); (err != nil) || !bytes.Equal(received, data) {
+		t.Fatalf("deprecated LZMA fields are not set up. err: %v", err)
+	}
+}
@@ -24,10 +24,20 @@ import (
 
 // Compressor is a compression descriptor
 type Compressor struct {
-	ReadCompression  *Compression
+	ReadCompression *Compression
+	// Reader inflates data of any compression
+	Reader io.Reader
+	// LZMAReader is the same as Reader in case of LZMA compression.
+	//
+	// Deprecated: use Reader, which is set up for any compression
 	LZMAReader       *lzma.Reader
 	WriteCompression *Compression
-	LZMAWriter       *lzma.Writer
+	// Writer deflates data of any compression
+	Writer io.WriteCloser
+	// LZMAWriter is the same as Writer in case of LZMA compression.
+	//
+	// Deprecated: use Writer, which is set up for any compression
+	LZMAWriter *lzma.Writer
 }
 
 // NewCompressor creates new compressor(s) for provided io.Reader and io.Writer
@@ -35,6 +45,7 @@ type Compressor struct {
 // compressor reads compressed data from `reader`, inflates it and returns as the result of its (compressor's) Read()
 // When data are written into compressor by calling Write() of the compressor,
 // compressor deflates data and writes compressed data into `writer`
+// Codecs are looked up by compression type in the registry of compression codecs.
 func NewCompressor(
 	readCompression *Compression,
 	reader io.Reader,
@@ -43,42 +54,104 @@ func NewCompressor(
 ) (*Compressor, error) {
 	compressor := &Compressor{}
 
-	switch readCompression.GetType() {
-	case CompressionTypeLZMA:
-		compressor.ReadCompression = readCompression
-		lzmaReader, err := lzma.NewReader(reader)
-		if err != nil {
-			log.Warnf("FAILED to create lzma reader. err: %v", err)
-			return nil, err
-		}
-		compressor.LZMAReader = lzmaReader
-	default:
-		compressor.ReadCompression = nil
-	}
-
-	switch writeCompression.GetType() {
-	case CompressionTypeLZMA:
-		lzmaWriter, err := lzma.NewWriter(writer)
-		if err != nil {
-			log.Warnf("FAILED to create lzma writer. err: %v", err)
-			return nil, err
-		}
-		compressor.WriteCompression = writeCompression
-		compressor.LZMAWriter = lzmaWriter
-	default:
-		compressor.WriteCompression = nil
+	if err := compressor.OpenReader(readCompression, reader); err != nil {
+		return nil, err
+	}
+	if err := compressor.OpenWriter(writeCompression, writer); err != nil {
+		return nil, err
 	}
 
 	return compressor, nil
 }
 
+// OpenReader sets up compressor to inflate data read from `reader` with the specified compression.
+// Can be called later than NewCompressor(), say, as soon as compression of the incoming stream becomes known.
+func (c *Compressor) OpenReader(compression *Compression, reader io.Reader) error {
+	if c == nil {
+		return fmt.Errorf("can't open reader for empty")
+	}
+
+	c.ReadCompression = nil
+	c.Reader = nil
+	c.LZMAReader = nil
+	if compression.GetType() == CompressionTypeNone {
+		return nil
+	}
+
+	codec := GetCompressionCodec(compression.GetType())
+	if codec == nil {
+		log.Warnf("unknown compression method %v", compression.GetType())
+		return fmt.Errorf("unknown compression method %v", compression.GetType())
+	}
+	r, err := codec.NewReader(reader)
+	if err != nil {
+		log.Warnf("FAILED to create %s reader. err: %v", compression.GetName(), err)
+		return err
+	}
+
+	c.ReadCompression = compression
+	c.Reader = r
+	c.LZMAReader, _ = r.(*lzma.Reader)
+	return nil
+}
+
+// OpenWriter sets up compressor to deflate data written into `writer` with the specified compression.
+func (c *Compressor) OpenWriter(compression *Compression, writer io.Writer) error {
+	if c == nil {
+		return fmt.Errorf("can't open writer for empty")
+	}
+
+	c.WriteCompression = nil
+	c.Writer = nil
+	c.LZMAWriter = nil
+	if compression.GetType() == CompressionTypeNone {
+		return nil
+	}
+
+	codec := GetCompressionCodec(compression.GetType())
+	if codec == nil {
+		log.Warnf("unknown compression method %v", compression.GetType())
+		return fmt.Errorf("unknown compression method %v", compression.GetType())
+	}
+	w, err := codec.NewWriter(writer)
+	if err != nil {
+		log.Warnf("FAILED to create %s writer. err: %v", compression.GetName(), err)
+		return err
+	}
+
+	c.WriteCompression = compression
+	c.Writer = w
+	c.LZMAWriter, _ = w.(*lzma.Writer)
+	return nil
+}
+
+// getReader gets reader, which inflates data. Falls back to deprecated LZMAReader set up explicitly
+func (c *Compressor) getReader() io.Reader {
+	if (c.Reader == nil) && (c.LZMAReader != nil) {
+		return c.LZMAReader
+	}
+	return c.Reader
+}
+
+// getWriter gets writer, which deflates data. Falls back to deprecated LZMAWriter set up explicitly
+func (c *Compressor) getWriter() io.WriteCloser {
+	if (c.Writer == nil) && (c.LZMAWriter != nil) {
+		return c.LZMAWriter
+	}
+	return c.Writer
+}
+
 // Close is an io.Closer interface function
+// Flushes compressed data into underlying io.Writer, but does not call Close() on underlying io.Writer()
 func (c *Compressor) Close() error {
 	if c == nil {
 		return nil
 	}
-	if c.LZMAWriter != nil {
-		return c.LZMAWriter.Close()
+	if closer, ok := c.getReader().(io.Closer); ok {
+		_ = closer.Close()
+	}
+	if writer := c.getWriter(); writer != nil {
+		return writer.Close()
 	}
 
 	return nil
@@ -102,18 +175,18 @@ func (c *Compressor) ReadEnabled() bool {
 
 // Write is an io.Writer function
 func (c *Compressor) Write(p []byte) (n int, err error) {
-	if c == nil {
+	if (c == nil) || (c.getWriter() == nil) {
 		return 0, fmt.Errorf("can't write to empty")
 	}
 
-	return c.LZMAWriter.Write(p)
+	return c.getWriter().Write(p)
 }
 
 // Read is an io.Reader function
 func (c *Compressor) Read(p []byte) (n int, err error) {
-	if c == nil {
+	if (c == nil) || (c.getReader() == nil) {
 		return 0, fmt.Errorf("can't read from empty")
 	}
 
-	return c.LZMAReader.Read(p)
+	return c.getReader().Read(p)
 }
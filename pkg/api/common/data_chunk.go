@@ -67,6 +67,16 @@ func (x *DataChunk) SetOffset(offset int64) {
 	x.EnsureProperties().SetOffset(offset)
 }
 
+// GetDigest is an IDataChunk interface function
+func (x *DataChunk) GetDigest() *Digest {
+	return x.GetProperties().GetDigest()
+}
+
+// SetDigest is an IDataChunk interface function
+func (x *DataChunk) SetDigest(digest *Digest) {
+	x.EnsureProperties().SetDigest(digest)
+}
+
 // EnsureProperties is a getter with guaranteed result
 func (x *DataChunk) EnsureProperties() *DataChunkProperties {
 	if x.GetProperties() == nil {
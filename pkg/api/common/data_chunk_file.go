@@ -15,6 +15,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 	"github.com/sunsingerus/tbox/pkg/util"
 	"io"
@@ -50,6 +51,16 @@ type DataChunkFile struct {
 
 	// maxWriteIDataChunkSize limits max size of a payload within one data IDataChunk to be sent
 	maxWriteIDataChunkSize int
+	// adaptiveWriteIDataChunkSize adapts size of a payload within one data IDataChunk to be sent. Optional.
+	// Overrides maxWriteIDataChunkSize
+	adaptiveWriteIDataChunkSize *adaptiveIDataChunkSize
+
+	// chunkDigestType specifies type of the digest attached to each data IDataChunk to be sent. Optional.
+	chunkDigestType DigestType
+	// streamDigest is the digest of the whole set to be attached to the "last" IDataChunk. Optional.
+	streamDigest *Digest
+	// limiter limits bandwidth of data IDataChunk(s) to be sent
+	limiter *StreamLimiter
 
 	//
 	// Receiver section
@@ -60,6 +71,18 @@ type DataChunkFile struct {
 	receivedDataBuf []byte
 	// receivedDataErr is an error occurred upon receiving data
 	receivedDataErr error
+	// receivedAny specifies whether any IDataChunk has been received already
+	receivedAny bool
+	// receivedInitialOffset is the offset of the first received IDataChunk within something bigger
+	receivedInitialOffset int64
+	// receivedLast specifies whether the last IDataChunk of the set has been received
+	receivedLast bool
+	// receivedLen is the number of data bytes received within the set
+	receivedLen int64
+	// receivedStreamDigest is the digest of the whole set, as received with the "last" IDataChunk
+	receivedStreamDigest *Digest
+	// verifyDigest specifies whether digests of incoming data IDataChunk(s) should be verified
+	verifyDigest bool
 
 	//
 	// Log section
@@ -67,6 +90,15 @@ type DataChunkFile struct {
 
 	// lastTimeDataIDataChunkLogged specifies when last time data chunk was logged
 	lastTimeDataIDataChunkLogged time.Time
+
+	//
+	// Progress section
+	//
+
+	// sendProgress tracks progress of outgoing data. Optional.
+	sendProgress *ProgressTracker
+	// receiveProgress tracks progress of incoming data. Optional.
+	receiveProgress *ProgressTracker
 }
 
 // Ensure interface compatibility
@@ -79,6 +111,7 @@ var (
 func OpenDataChunkFile(transport IDataChunkTransport) (*DataChunkFile, error) {
 	return &DataChunkFile{
 		transport: transport,
+		limiter:   DefaultThrottle.NewStreamLimiter(),
 	}, nil
 }
 
@@ -90,6 +123,190 @@ func (f *DataChunkFile) GetOffset() int64 {
 	return f.offset
 }
 
+// SetInitialOffset sets offset of this set of IDataChunk(s) within something bigger.
+// Used to continue transmission of the data from the specified offset, say, to resume an interrupted upload.
+// Should be called before any data is sent.
+func (f *DataChunkFile) SetInitialOffset(offset int64) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.globalInitialOffset = offset
+	f.globalOffset = offset
+	return f
+}
+
+// GetInitialOffset gets offset of this set of IDataChunk(s) within something bigger
+func (f *DataChunkFile) GetInitialOffset() int64 {
+	if f == nil {
+		return 0
+	}
+	return f.globalInitialOffset
+}
+
+// GetGlobalOffset gets offset of the current IDataChunk within something bigger
+func (f *DataChunkFile) GetGlobalOffset() int64 {
+	if f == nil {
+		return 0
+	}
+	return f.globalInitialOffset + f.offset
+}
+
+// GetReceivedInitialOffset gets offset of the first received IDataChunk within something bigger.
+// Receiver can use it to check whether incoming data continues previous transmission.
+func (f *DataChunkFile) GetReceivedInitialOffset() int64 {
+	if f == nil {
+		return 0
+	}
+	return f.receivedInitialOffset
+}
+
+// IsAnyReceived checks whether any IDataChunk of the set has been received.
+// Receiver can use it to distinguish an empty set from the end of the transport.
+func (f *DataChunkFile) IsAnyReceived() bool {
+	if f == nil {
+		return false
+	}
+	return f.receivedAny
+}
+
+// IsLastReceived checks whether the last IDataChunk of the set has been received,
+// meaning the sender has explicitly finalized transmission.
+func (f *DataChunkFile) IsLastReceived() bool {
+	if f == nil {
+		return false
+	}
+	return f.receivedLast
+}
+
+// SetMaxWriteIDataChunkSize sets max size of a payload within one data IDataChunk to be sent.
+// Zero means no limit, whole buffer provided to Write() is sent as one IDataChunk
+func (f *DataChunkFile) SetMaxWriteIDataChunkSize(size int) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.maxWriteIDataChunkSize = size
+	return f
+}
+
+// SetAdaptiveWriteIDataChunkSize enables adaptive size of a payload within one data IDataChunk to be sent.
+// Size grows based on observed throughput up to max. Zero max means MaxAdaptiveIDataChunkSize
+func (f *DataChunkFile) SetAdaptiveWriteIDataChunkSize(max int) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.adaptiveWriteIDataChunkSize = newAdaptiveIDataChunkSize(max)
+	return f
+}
+
+// GetWriteIDataChunkSize gets max size of a payload within one data IDataChunk to be sent right now.
+// Zero means no limit
+func (f *DataChunkFile) GetWriteIDataChunkSize() int {
+	if f == nil {
+		return 0
+	}
+	if f.adaptiveWriteIDataChunkSize != nil {
+		return f.adaptiveWriteIDataChunkSize.size()
+	}
+	return f.maxWriteIDataChunkSize
+}
+
+// getRelayBufSize gets size of a buffer to relay data through this file with
+func (f *DataChunkFile) getRelayBufSize(defaultSize int) int {
+	if f.adaptiveWriteIDataChunkSize != nil {
+		return f.adaptiveWriteIDataChunkSize.limit()
+	}
+	return util.IfPositiveValue(f.maxWriteIDataChunkSize, defaultSize)
+}
+
+// SetThrottle sets throttle to limit bandwidth of data IDataChunk(s) to be sent. DefaultThrottle is used by default
+func (f *DataChunkFile) SetThrottle(throttle *Throttle) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.limiter = throttle.NewStreamLimiter()
+	return f
+}
+
+// getTransportContext gets context of the transport, in case the transport provides one
+func (f *DataChunkFile) getTransportContext() context.Context {
+	if contexter, ok := f.transport.(IDataChunkContexter); ok {
+		return contexter.Context()
+	}
+	return context.Background()
+}
+
+// SetChunkDigestType sets type of the digest to be attached to each data IDataChunk to be sent.
+// DigestType_DIGEST_RESERVED means no digest.
+func (f *DataChunkFile) SetChunkDigestType(_type DigestType) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.chunkDigestType = _type
+	return f
+}
+
+// SetStreamDigest sets digest of the whole set to be sent with the "last" IDataChunk upon Close()
+func (f *DataChunkFile) SetStreamDigest(digest *Digest) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.streamDigest = digest
+	return f
+}
+
+// SetVerifyDigest sets whether digests of incoming data IDataChunk(s) should be verified
+func (f *DataChunkFile) SetVerifyDigest(verify bool) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.verifyDigest = verify
+	return f
+}
+
+// GetReceivedStreamDigest gets digest of the whole set, as received with the "last" IDataChunk. Optional.
+func (f *DataChunkFile) GetReceivedStreamDigest() *Digest {
+	if f == nil {
+		return nil
+	}
+	return f.receivedStreamDigest
+}
+
+// SetProgressListener sets listener to be notified about progress of outgoing and incoming data
+// not more often than once per interval. Zero interval means DefaultProgressInterval
+func (f *DataChunkFile) SetProgressListener(listener ProgressListener, interval time.Duration) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	if listener == nil {
+		f.sendProgress = nil
+		f.receiveProgress = nil
+		return f
+	}
+	f.sendProgress = NewProgressTracker(listener, interval, false)
+	f.receiveProgress = NewProgressTracker(listener, interval, true)
+	return f
+}
+
+// SetProgressTotal sets total number of outgoing bytes, including bytes sent previously
+// in case transmission continues previous one. Zero means total is unknown
+func (f *DataChunkFile) SetProgressTotal(total int64) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.sendProgress.SetTotal(total)
+	return f
+}
+
+// SetReceiveProgressTotal sets total number of incoming bytes, including bytes received previously
+// in case transmission continues previous one. Zero means total is unknown
+func (f *DataChunkFile) SetReceiveProgressTotal(total int64) *DataChunkFile {
+	if f == nil {
+		return nil
+	}
+	f.receiveProgress.SetTotal(total)
+	return f
+}
+
 // close does internal job to close the communication
 func (f *DataChunkFile) close() {
 	if f == nil {
@@ -102,6 +319,12 @@ func (f *DataChunkFile) close() {
 
 	f.receivedDataBuf = nil
 	f.receivedDataErr = nil
+	f.receivedAny = false
+	f.receivedInitialOffset = 0
+	f.receivedLast = false
+	f.receivedLen = 0
+	f.receivedStreamDigest = nil
+	f.streamDigest = nil
 
 	// TODO flush outgoing transport
 	// TODO should incoming transport be drained?
@@ -146,7 +369,7 @@ func (f *DataChunkFile) receiveIDataChunk() (IDataChunk, error) {
 		// All went well, ready to receive more data
 	case io.EOF:
 		// Correct EOF arrived
-		if iDataChunk.GetDataLen() == 0 {
+		if (iDataChunk == nil) || (iDataChunk.GetDataLen() == 0) {
 			log.Infof("DataChunkFile.receiveIDataChunk() get EOF with no data")
 		} else {
 			log.Infof("DataChunkFile.receiveIDataChunk() get EOF with %d bytes", iDataChunk.GetDataLen())
@@ -168,12 +391,29 @@ func (f *DataChunkFile) receiveIDataChunkIntoBuf() {
 	iDataChunk, err := f.receiveIDataChunk()
 	if iDataChunk != nil {
 		log.Tracef("Got data len: %d", iDataChunk.GetDataLen())
+		if !f.receivedAny {
+			// The first IDataChunk specifies where the whole set starts within something bigger
+			f.receivedAny = true
+			f.receivedInitialOffset = iDataChunk.GetOffset()
+		}
 		if iDataChunk.GetDataLen() > 0 {
+			if e := f.verifyIDataChunkDigest(iDataChunk); e != nil {
+				log.Warnf("%v", e)
+				f.receivedDataErr = e
+				return
+			}
 			f.receivedDataBuf = append(f.receivedDataBuf, iDataChunk.GetData()...)
+			f.receivedLen += int64(iDataChunk.GetDataLen())
 		}
+		f.receiveProgress.Observe(f.receivedInitialOffset, f.receivedLen, iDataChunk.GetLast())
 
 		if iDataChunk.GetLast() {
 			log.Tracef("Got last IDataChunk, reporting EOF ")
+			f.receivedLast = true
+			if iDataChunk.GetDataLen() == 0 {
+				// Data-less finalizer carries digest of the whole set
+				f.receivedStreamDigest = iDataChunk.GetDigest()
+			}
 			f.receivedDataErr = io.EOF
 		}
 	}
@@ -184,6 +424,31 @@ func (f *DataChunkFile) receiveIDataChunkIntoBuf() {
 	}
 }
 
+// verifyIDataChunkDigest verifies digest of the data IDataChunk, in case verification is requested and
+// the IDataChunk has digest attached. Returns *DigestMismatchError in case of mismatch.
+func (f *DataChunkFile) verifyIDataChunkDigest(iDataChunk IDataChunk) error {
+	if !f.verifyDigest {
+		return nil
+	}
+	expected := iDataChunk.GetDigest()
+	if expected == nil {
+		return nil
+	}
+	if !IsDigestTypeSupported(expected.GetType()) {
+		log.Warnf("unable to verify digest of unknown type %v", expected.GetType())
+		return nil
+	}
+	actual := NewDigest().SetType(expected.GetType()).Calculate(iDataChunk.GetData())
+	if !actual.Equals(expected) {
+		return &DigestMismatchError{
+			Expected: expected,
+			Actual:   actual,
+			Offset:   iDataChunk.GetOffset(),
+		}
+	}
+	return nil
+}
+
 // sendIDataChunk is used to send data buf as one IDataChunk.
 // Returns an error, in case provided data buf to send is bigger than one IDataChunk accepts.
 func (f *DataChunkFile) sendIDataChunk(p []byte) (n int, err error) {
@@ -196,8 +461,8 @@ func (f *DataChunkFile) sendIDataChunk(p []byte) (n int, err error) {
 	if n == 0 {
 		return 0, nil
 	}
-	if (n > f.maxWriteIDataChunkSize) && (f.maxWriteIDataChunkSize > 0) {
-		return 0, fmt.Errorf("attempt to sendIDataChunk() with oversized chunk: %d > %d", n, f.maxWriteIDataChunkSize)
+	if max := f.GetWriteIDataChunkSize(); (n > max) && (max > 0) {
+		return 0, fmt.Errorf("attempt to sendIDataChunk() with oversized chunk: %d > %d", n, max)
 	}
 
 	// Offset of this data chunk
@@ -205,9 +470,20 @@ func (f *DataChunkFile) sendIDataChunk(p []byte) (n int, err error) {
 
 	// Create abstracted IDataChunk
 	iDataChunk := f.transport.NewIDataChunk(f)
-	iDataChunk.SetOffset(f.offset)
+	iDataChunk.SetOffset(f.globalOffset)
 	iDataChunk.SetData(p)
+	if f.chunkDigestType != DigestType_DIGEST_RESERVED {
+		iDataChunk.SetDigest(NewDigest().SetType(f.chunkDigestType).Calculate(p))
+	}
+	if err := f.limiter.Wait(f.getTransportContext(), len(p)); err != nil {
+		log.Warnf("sendIDataChunk() abandoned while throttled. err: %v", err)
+		return 0, err
+	}
+	start := time.Now()
 	err = f.transport.Send(iDataChunk)
+	if (err == nil) && (f.adaptiveWriteIDataChunkSize != nil) {
+		f.adaptiveWriteIDataChunkSize.observe(n, time.Since(start))
+	}
 	if err != nil {
 		// We have some kind of error and were not able to send the data
 		n = 0
@@ -221,18 +497,22 @@ func (f *DataChunkFile) sendIDataChunk(p []byte) (n int, err error) {
 
 	// Adjust offset of next chunk to be sent after this one
 	f.offset += int64(n)
+	if n > 0 {
+		f.sendProgress.Observe(f.globalInitialOffset, f.offset, false)
+	}
 	return
 }
 
 // iDataChunkSize calculates size for data buf of length bufLen
 func (f *DataChunkFile) iDataChunkSize(bufLen int) int {
-	if f.maxWriteIDataChunkSize <= 0 {
+	max := f.GetWriteIDataChunkSize()
+	if max <= 0 {
 		// MaxWriteIDataChunkSize is not specified, return the whole buf length
 		return bufLen
 	}
 
 	// Max chunk size is specified, result size must not be greater than MaxWriteIDataChunkSize
-	return util.Min(f.maxWriteIDataChunkSize, bufLen)
+	return util.Min(max, bufLen)
 }
 
 // Write implements io.Writer
@@ -276,7 +556,7 @@ func (f *DataChunkFile) WriteTo(dst io.Writer) (n int64, err error) {
 	log.Tracef("DataChunkFile.WriteTo() - start")
 	defer log.Tracef("DataChunkFile.WriteTo() - end")
 
-	return cp(dst, f, util.IfPositiveValue(f.maxWriteIDataChunkSize, defaultMaxWriteIDataChunkSize))
+	return cp(dst, f, f.getRelayBufSize(defaultMaxWriteIDataChunkSize))
 }
 
 // Read implements io.Reader
@@ -311,8 +591,9 @@ func (f *DataChunkFile) Read(p []byte) (n int, err error) {
 	log.Tracef("DataChunkFile.Read() - start")
 	defer log.Tracef("DataChunkFile.Read() - end")
 
-	if len(f.receivedDataBuf) == 0 {
-		// No buffered data available, need to get some
+	if (len(f.receivedDataBuf) == 0) && (f.receivedDataErr == nil) {
+		// No buffered data available, need to get some.
+		// Nothing is received after the last IDataChunk, because transport may carry more sets after this one
 		f.receiveIDataChunkIntoBuf()
 	}
 
@@ -346,7 +627,7 @@ func (f *DataChunkFile) ReadFrom(src io.Reader) (n int64, err error) {
 	log.Tracef("DataChunkFile.ReadFrom() - start")
 	defer log.Tracef("DataChunkFile.ReadFrom() - end")
 
-	return cp(f, src, util.IfPositiveValue(f.maxWriteIDataChunkSize, defaultMaxWriteIDataChunkSize))
+	return cp(f, src, f.getRelayBufSize(defaultMaxWriteIDataChunkSize))
 }
 
 // Close implements io.Closer
@@ -364,17 +645,23 @@ func (f *DataChunkFile) Close() error {
 
 	defer f.close()
 
-	if f.offset == 0 {
-		// No data were sent via this stream, no need to send finalizer
+	if (f.offset == 0) && (f.globalInitialOffset == 0) && (f.streamDigest == nil) && !f.needsFinalizer() {
+		// No data were sent via this stream and there is nothing to send with finalizer, no need to send finalizer
+		f.sendProgress.Observe(0, 0, true)
 		return nil
 	}
 
-	// Some data were sent via this stream, need to finalize transmission with finalizer
+	// Some data were sent via this stream (or this stream continues previous transmission),
+	// need to finalize transmission with finalizer
 
 	// Send "last" data chunk
 	// Create abstracted IDataChunk
 	iDataChunk := f.transport.NewIDataChunk(f)
+	iDataChunk.SetOffset(f.GetGlobalOffset())
 	iDataChunk.SetLast(true)
+	if f.streamDigest != nil {
+		iDataChunk.SetDigest(f.streamDigest)
+	}
 	err := f.transport.Send(iDataChunk)
 	if err != nil {
 		if err == io.EOF {
@@ -382,10 +669,18 @@ func (f *DataChunkFile) Close() error {
 		} else {
 			log.Errorf("failed to Send() %v", err)
 		}
+	} else {
+		f.sendProgress.Observe(f.globalInitialOffset, f.offset, true)
 	}
 
 	return err
 }
 
+// needsFinalizer checks whether transport requires empty set to be finalized
+func (f *DataChunkFile) needsFinalizer() bool {
+	finalizer, ok := f.transport.(IDataChunkFinalizer)
+	return ok && finalizer.NeedsFinalizer()
+}
+
 // TODO implement Reset function for DataChunkFile,
 //  so the same descriptor can be used for multiple transmissions.
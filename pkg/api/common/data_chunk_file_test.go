@@ -0,0 +1,97 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// marshalDataPacketWriter emulates gRPC stream by marshalling each DataPacket to be sent
+type marshalDataPacketWriter struct {
+	packets int
+}
+
+// Send is a DataPacketWriter interface function
+func (w *marshalDataPacketWriter) Send(packet *DataPacket) error {
+	_, err := proto.Marshal(packet)
+	w.packets++
+	return err
+}
+
+func benchmarkDataPacketFileUpload(b *testing.B, options *DataPacketFileOptions) {
+	level := log.GetLevel()
+	log.SetLevel(log.WarnLevel)
+	defer log.SetLevel(level)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4*1024*1024/16)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	packets := 0
+	for i := 0; i < b.N; i++ {
+		writer := &marshalDataPacketWriter{}
+		f, err := OpenDataPacketFileWOptions(writer, nil, options)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			b.Fatal(err)
+		}
+		packets += writer.packets
+	}
+	b.ReportMetric(float64(packets)/float64(b.N), "packets/op")
+}
+
+func BenchmarkDataPacketFileUploadChunk1K(b *testing.B) {
+	benchmarkDataPacketFileUpload(b, NewDataPacketFileOptions().SetChunkSize(1024))
+}
+
+func BenchmarkDataPacketFileUploadChunk32K(b *testing.B) {
+	benchmarkDataPacketFileUpload(b, NewDataPacketFileOptions().SetChunkSize(32*1024))
+}
+
+func BenchmarkDataPacketFileUploadChunk1M(b *testing.B) {
+	benchmarkDataPacketFileUpload(b, NewDataPacketFileOptions().SetChunkSize(1024*1024))
+}
+
+func BenchmarkDataPacketFileUploadAdaptive(b *testing.B) {
+	benchmarkDataPacketFileUpload(b, NewDataPacketFileOptions().SetAdaptiveChunkSize(true))
+}
+
+func TestAdaptiveIDataChunkSize(t *testing.T) {
+	a := newAdaptiveIDataChunkSize(0)
+	if a.size() != MinAdaptiveIDataChunkSize {
+		t.Fatalf("unexpected initial size %d", a.size())
+	}
+	// Constant time per chunk means throughput grows with chunk size
+	for i := 0; i < 32; i++ {
+		a.observe(a.size(), 1000)
+	}
+	if a.size() != MaxAdaptiveIDataChunkSize {
+		t.Fatalf("size %d has not grown to the max %d", a.size(), MaxAdaptiveIDataChunkSize)
+	}
+	// Significant throughput degradation shrinks chunk size
+	a.observe(a.size(), 1000*1000)
+	if a.size() >= MaxAdaptiveIDataChunkSize {
+		t.Fatalf("size %d has not shrunk", a.size())
+	}
+}
@@ -15,6 +15,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 	"io"
 )
@@ -35,6 +36,10 @@ type IDataChunk interface {
 	GetOffset() int64
 	// SetOffset sets offset of the DataChunk within the file (set)
 	SetOffset(int64)
+	// GetDigest gets digest of the DataChunk. For the data-less last DataChunk it is the digest of the whole file (set)
+	GetDigest() *Digest
+	// SetDigest sets digest of the DataChunk
+	SetDigest(*Digest)
 	// Stringer is a nice touch to log DataChunk(s)
 	fmt.Stringer
 }
@@ -67,3 +72,19 @@ type IDataChunkTransport interface {
 	// Send sends IDataChunk at the transport layer.
 	Send(IDataChunk) error
 }
+
+// IDataChunkFinalizer is an optional interface of IDataChunkTransport.
+// Specifies whether set of IDataChunk(s) has to be finalized with the "last" IDataChunk even in case
+// no data were sent, say, because the "last" IDataChunk carries metadata of the set.
+type IDataChunkFinalizer interface {
+	// NeedsFinalizer checks whether empty set has to be finalized
+	NeedsFinalizer() bool
+}
+
+// IDataChunkContexter is an optional interface of IDataChunkTransport.
+// Provides context of the transport, say, of the gRPC stream, thus waits upon sending are abandoned as soon as
+// the transport is done.
+type IDataChunkContexter interface {
+	// Context gets context of the transport
+	Context() context.Context
+}
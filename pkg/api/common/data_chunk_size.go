@@ -0,0 +1,85 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"time"
+
+	"github.com/sunsingerus/tbox/pkg/util"
+)
+
+const (
+	// MinAdaptiveIDataChunkSize specifies size adaptive IDataChunk size starts with and never goes below
+	MinAdaptiveIDataChunkSize = 16 * 1024
+	// MaxAdaptiveIDataChunkSize specifies default upper limit of adaptive IDataChunk size.
+	// gRPC limits incoming message size with 4MB by default, some room is left for envelope and metadata.
+	MaxAdaptiveIDataChunkSize = 4*1024*1024 - 64*1024
+)
+
+// adaptiveIDataChunkSize adapts size of IDataChunk(s) to be sent based on observed throughput.
+// Size is doubled while throughput improves and is halved when throughput degrades significantly.
+type adaptiveIDataChunkSize struct {
+	// min and max limit size
+	min, max int
+	// current size of IDataChunk to be sent
+	current int
+	// throughput observed for the current size, bytes per second
+	throughput float64
+}
+
+// newAdaptiveIDataChunkSize creates new adaptive IDataChunk size limited by max
+func newAdaptiveIDataChunkSize(max int) *adaptiveIDataChunkSize {
+	if max <= 0 {
+		max = MaxAdaptiveIDataChunkSize
+	}
+	min := util.Min(MinAdaptiveIDataChunkSize, max)
+	return &adaptiveIDataChunkSize{
+		min:     min,
+		max:     max,
+		current: min,
+	}
+}
+
+// size gets current size of IDataChunk to be sent
+func (a *adaptiveIDataChunkSize) size() int {
+	return a.current
+}
+
+// limit gets max size of IDataChunk to be sent
+func (a *adaptiveIDataChunkSize) limit() int {
+	return a.max
+}
+
+// observe accounts n bytes sent within elapsed duration and adapts size
+func (a *adaptiveIDataChunkSize) observe(n int, elapsed time.Duration) {
+	if n < a.current {
+		// Partial chunk says nothing about the current size
+		return
+	}
+	if elapsed <= 0 {
+		elapsed = time.Nanosecond
+	}
+
+	throughput := float64(n) / elapsed.Seconds()
+	switch {
+	case throughput >= a.throughput:
+		// Bigger chunks are still beneficial
+		a.current = util.Min(a.current*2, a.max)
+	case throughput < a.throughput/2:
+		// Bigger chunks do not pay off anymore
+		a.current = util.Max(a.current/2, a.min)
+	}
+	a.throughput = throughput
+}
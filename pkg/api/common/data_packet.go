@@ -76,6 +76,16 @@ func (x *DataPacket) SetOffset(offset int64) {
 	x.EnsureDataChunk().SetOffset(offset)
 }
 
+// GetDigest is an IDataChunk interface function
+func (x *DataPacket) GetDigest() *Digest {
+	return x.GetDataChunk().GetDigest()
+}
+
+// SetDigest is an IDataChunk interface function
+func (x *DataPacket) SetDigest(digest *Digest) {
+	x.EnsureDataChunk().SetDigest(digest)
+}
+
 // GetIDataChunk is an IDataChunkEnvelope interface function
 func (x *DataPacket) GetIDataChunk() IDataChunk {
 	return x.GetDataChunk()
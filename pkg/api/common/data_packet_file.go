@@ -15,6 +15,8 @@
 package common
 
 import (
+	"context"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -37,6 +39,8 @@ type DataPacketFile struct {
 var (
 	_ IDataChunkFile      = &DataPacketFile{}
 	_ IDataChunkTransport = &DataPacketFile{}
+	_ IDataChunkFinalizer = &DataPacketFile{}
+	_ IDataChunkContexter = &DataPacketFile{}
 )
 
 // OpenDataPacketFile opens set of DataChunk(s)
@@ -56,6 +60,10 @@ func OpenDataPacketFile(writer DataPacketWriter, reader DataPacketReader) (f *Da
 // Recv is an IDataChunkTransport interface function
 func (f *DataPacketFile) Recv() (IDataChunk, error) {
 	packet, err := f.reader.Recv()
+	if packet == nil {
+		// Avoid non-nil IDataChunk interface with nil *DataPacket inside
+		return nil, err
+	}
 	f.acceptPayloadMetadata(packet)
 	f.acceptStreamOptions(packet)
 	return packet, err
@@ -68,6 +76,21 @@ func (f *DataPacketFile) Send(iDataChunk IDataChunk) error {
 	return f.writer.Send(packet)
 }
 
+// NeedsFinalizer is an IDataChunkFinalizer interface function.
+// Empty set has to be finalized in case payload metadata is specified, so receiver gets the metadata.
+func (f *DataPacketFile) NeedsFinalizer() bool {
+	return f.GetPayloadMetadata() != nil
+}
+
+// Context is an IDataChunkContexter interface function.
+// Gets context of the writer, in case the writer has one, say, it is a gRPC stream
+func (f *DataPacketFile) Context() context.Context {
+	if writer, ok := f.writer.(IDataChunkContexter); ok {
+		return writer.Context()
+	}
+	return context.Background()
+}
+
 /*
 func S(){
 	// Fetch filename from the chunks stream - it may be in any chunk, actually
@@ -98,6 +121,10 @@ func (f *DataPacketFile) NewIDataChunk(offsetter GetOffsetter) IDataChunk {
 func (f *DataPacketFile) acceptPayloadMetadata(packet *DataPacket) {
 	if packet.GetPayloadMetadata() != nil {
 		f.SetPayloadMetadata(packet.GetPayloadMetadata())
+		if packet.GetStreamOptions().GetCompression() == nil {
+			// Total is specified for the payload, thus it corresponds to the transferred data of uncompressed stream only
+			f.SetReceiveProgressTotal(packet.GetPayloadMetadata().GetProperties().GetTotal())
+		}
 	}
 }
 
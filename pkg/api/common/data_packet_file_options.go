@@ -14,6 +14,8 @@
 
 package common
 
+import "time"
+
 // DataPacketFileOptions describes metadata a.k.a options for data file
 type DataPacketFileOptions struct {
 	Header   *Metadata
@@ -22,8 +24,51 @@ type DataPacketFileOptions struct {
 	// Compress outgoing data
 	Compress bool
 
+	// Compression specifies compression of outgoing data. LZMA is used in case Compress is requested without Compression
+	Compression *Compression
+
 	// Decompress incoming data
 	Decompress bool
+
+	// Offset of the outgoing data within something bigger. Used to continue previous transmission.
+	Offset int64
+
+	// EncryptionKeyID specifies key to encrypt outgoing data with. Empty means no encryption
+	EncryptionKeyID string
+
+	// KeyProvider provides keys to encrypt outgoing and to decrypt incoming data
+	KeyProvider KeyProvider
+
+	// ChunkSize specifies max size of the payload of one outgoing data chunk. Zero means default
+	ChunkSize int
+
+	// AdaptiveChunkSize specifies whether size of the outgoing data chunks should adapt to observed throughput.
+	// ChunkSize, if specified, limits adaptive size
+	AdaptiveChunkSize bool
+
+	// ChunkDigest specifies type of the digest to be attached to each outgoing data chunk
+	ChunkDigest DigestType
+
+	// StreamDigest specifies type of the digest of the whole outgoing stream.
+	// Digest is calculated over the data written, before compression and encryption
+	StreamDigest DigestType
+
+	// VerifyDigest specifies whether digests of incoming data chunks and stream should be verified.
+	// Stream digest is verified only in case incoming data are decompressed and decrypted.
+	// Incoming stream, which ends before it is finalized by the sender, fails to be read
+	VerifyDigest bool
+
+	// ProgressListener is notified about progress of outgoing and incoming data. Optional.
+	ProgressListener ProgressListener
+
+	// ProgressInterval specifies how often progress is reported. Zero means DefaultProgressInterval
+	ProgressInterval time.Duration
+
+	// ProgressTotal specifies total size of outgoing data. Zero means total from metadata properties, if any
+	ProgressTotal int64
+
+	// Throttle limits bandwidth of outgoing data. Nil means DefaultThrottle
+	Throttle *Throttle
 }
 
 // NewDataPacketFileOptions creates new DataChunkFileOptions
@@ -82,6 +127,35 @@ func (opts *DataPacketFileOptions) GetCompress() bool {
 	return opts.Compress
 }
 
+// SetCompression is a setter
+func (opts *DataPacketFileOptions) SetCompression(compression *Compression) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.Compression = compression
+	return opts
+}
+
+// GetCompression is a getter
+func (opts *DataPacketFileOptions) GetCompression() *Compression {
+	if opts == nil {
+		return nil
+	}
+	return opts.Compression
+}
+
+// GetWriteCompression gets compression to be applied to outgoing data
+func (opts *DataPacketFileOptions) GetWriteCompression() *Compression {
+	switch {
+	case opts.GetCompression() != nil:
+		return opts.GetCompression()
+	case opts.GetCompress():
+		return CompressionLZMA
+	default:
+		return CompressionNone
+	}
+}
+
 // SetDecompress is a setter
 func (opts *DataPacketFileOptions) SetDecompress(decompress bool) *DataPacketFileOptions {
 	if opts == nil {
@@ -98,3 +172,210 @@ func (opts *DataPacketFileOptions) GetDecompress() bool {
 	}
 	return opts.Decompress
 }
+
+// SetOffset is a setter
+func (opts *DataPacketFileOptions) SetOffset(offset int64) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.Offset = offset
+	return opts
+}
+
+// GetOffset is a getter
+func (opts *DataPacketFileOptions) GetOffset() int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.Offset
+}
+
+// SetEncryptionKeyID is a setter
+func (opts *DataPacketFileOptions) SetEncryptionKeyID(keyID string) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.EncryptionKeyID = keyID
+	return opts
+}
+
+// GetEncryptionKeyID is a getter
+func (opts *DataPacketFileOptions) GetEncryptionKeyID() string {
+	if opts == nil {
+		return ""
+	}
+	return opts.EncryptionKeyID
+}
+
+// SetKeyProvider is a setter
+func (opts *DataPacketFileOptions) SetKeyProvider(keyProvider KeyProvider) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.KeyProvider = keyProvider
+	return opts
+}
+
+// GetKeyProvider is a getter
+func (opts *DataPacketFileOptions) GetKeyProvider() KeyProvider {
+	if opts == nil {
+		return nil
+	}
+	return opts.KeyProvider
+}
+
+// SetChunkSize is a setter
+func (opts *DataPacketFileOptions) SetChunkSize(size int) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.ChunkSize = size
+	return opts
+}
+
+// GetChunkSize is a getter
+func (opts *DataPacketFileOptions) GetChunkSize() int {
+	if opts == nil {
+		return 0
+	}
+	return opts.ChunkSize
+}
+
+// SetAdaptiveChunkSize is a setter
+func (opts *DataPacketFileOptions) SetAdaptiveChunkSize(adaptive bool) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.AdaptiveChunkSize = adaptive
+	return opts
+}
+
+// GetAdaptiveChunkSize is a getter
+func (opts *DataPacketFileOptions) GetAdaptiveChunkSize() bool {
+	if opts == nil {
+		return false
+	}
+	return opts.AdaptiveChunkSize
+}
+
+// SetChunkDigest is a setter
+func (opts *DataPacketFileOptions) SetChunkDigest(_type DigestType) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.ChunkDigest = _type
+	return opts
+}
+
+// GetChunkDigest is a getter
+func (opts *DataPacketFileOptions) GetChunkDigest() DigestType {
+	if opts == nil {
+		return DigestType_DIGEST_RESERVED
+	}
+	return opts.ChunkDigest
+}
+
+// SetStreamDigest is a setter
+func (opts *DataPacketFileOptions) SetStreamDigest(_type DigestType) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.StreamDigest = _type
+	return opts
+}
+
+// GetStreamDigest is a getter
+func (opts *DataPacketFileOptions) GetStreamDigest() DigestType {
+	if opts == nil {
+		return DigestType_DIGEST_RESERVED
+	}
+	return opts.StreamDigest
+}
+
+// SetVerifyDigest is a setter
+func (opts *DataPacketFileOptions) SetVerifyDigest(verify bool) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.VerifyDigest = verify
+	return opts
+}
+
+// GetVerifyDigest is a getter
+func (opts *DataPacketFileOptions) GetVerifyDigest() bool {
+	if opts == nil {
+		return false
+	}
+	return opts.VerifyDigest
+}
+
+// SetProgressListener is a setter
+func (opts *DataPacketFileOptions) SetProgressListener(listener ProgressListener) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.ProgressListener = listener
+	return opts
+}
+
+// GetProgressListener is a getter
+func (opts *DataPacketFileOptions) GetProgressListener() ProgressListener {
+	if opts == nil {
+		return nil
+	}
+	return opts.ProgressListener
+}
+
+// SetProgressInterval is a setter
+func (opts *DataPacketFileOptions) SetProgressInterval(interval time.Duration) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.ProgressInterval = interval
+	return opts
+}
+
+// GetProgressInterval is a getter
+func (opts *DataPacketFileOptions) GetProgressInterval() time.Duration {
+	if opts == nil {
+		return 0
+	}
+	return opts.ProgressInterval
+}
+
+// SetProgressTotal is a setter
+func (opts *DataPacketFileOptions) SetProgressTotal(total int64) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.ProgressTotal = total
+	return opts
+}
+
+// GetProgressTotal is a getter. Falls back to total from metadata properties
+func (opts *DataPacketFileOptions) GetProgressTotal() int64 {
+	if opts == nil {
+		return 0
+	}
+	if opts.ProgressTotal > 0 {
+		return opts.ProgressTotal
+	}
+	return opts.GetMetadata().GetProperties().GetTotal()
+}
+
+// SetThrottle is a setter
+func (opts *DataPacketFileOptions) SetThrottle(throttle *Throttle) *DataPacketFileOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.Throttle = throttle
+	return opts
+}
+
+// GetThrottle is a getter
+func (opts *DataPacketFileOptions) GetThrottle() *Throttle {
+	if opts == nil {
+		return nil
+	}
+	return opts.Throttle
+}
@@ -17,16 +17,39 @@ package common
 import (
 	"bytes"
 	"fmt"
+	"hash"
 	"io"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// ErrStreamTruncated specifies the situation when incoming stream ends before the sender has finalized it
+var ErrStreamTruncated = fmt.Errorf("stream is truncated")
+
 // DataPacketFileWithOptions
 // Inspired by os.File handler and is expected to be used in the same context.
 type DataPacketFileWithOptions struct {
 	*DataPacketFile
 	Compressor *Compressor
+	Encryptor  *Encryptor
+
+	// decompress specifies whether incoming data should be decompressed
+	decompress bool
+	// keyProvider provides keys to decrypt incoming data. Incoming data are not decrypted without key provider
+	keyProvider KeyProvider
+	// readerOpened specifies whether decryptor and decompressor are already set up according to incoming stream options
+	readerOpened bool
+
+	// streamDigestType specifies type of the digest of the whole outgoing stream. Optional.
+	streamDigestType DigestType
+	// writeHash calculates digest of the whole outgoing stream
+	writeHash hash.Hash
+	// verifyDigest specifies whether digest of the whole incoming stream should be verified
+	verifyDigest bool
+	// readHash calculates digest of the whole incoming stream
+	readHash hash.Hash
+	// readHashType specifies type of the digest calculated by readHash
+	readHashType DigestType
 }
 
 // Ensure interface compatibility
@@ -60,27 +83,75 @@ func OpenDataPacketFileWOptions(
 		return nil, err
 	}
 	f.SetPayloadMetadata(options.GetMetadata())
+	f.SetInitialOffset(options.GetOffset())
 
 	this := newDataPacketFileWithOptions(f)
 
-	readCompression := CompressionNone
-	if options.GetDecompress() {
-		log.Infof("requesting LZMA decompression")
-		readCompression = CompressionLZMA
+	// Setup data chunk size
+	if options.GetAdaptiveChunkSize() {
+		f.SetAdaptiveWriteIDataChunkSize(options.GetChunkSize())
+	} else {
+		f.SetMaxWriteIDataChunkSize(options.GetChunkSize())
+	}
+
+	// Setup bandwidth limits
+	if throttle := options.GetThrottle(); throttle != nil {
+		f.SetThrottle(throttle)
+	}
+
+	// Setup digests
+	f.SetChunkDigestType(options.GetChunkDigest())
+	f.SetVerifyDigest(options.GetVerifyDigest())
+	this.verifyDigest = options.GetVerifyDigest()
+	if options.GetStreamDigest() != DigestType_DIGEST_RESERVED {
+		if this.writeHash, err = NewDigestHash(options.GetStreamDigest()); err != nil {
+			log.Errorf("UNABLE to setup stream digest. Err: %v", err)
+			return nil, err
+		}
+		this.streamDigestType = options.GetStreamDigest()
+		// Announce digest type in payload metadata, so receiver is able to calculate the same digest
+		this.EnsurePayloadMetadata().EnsureProperties().SetDigest(NewDigest().SetType(this.streamDigestType))
+	}
+
+	// Decryptor and decompressor are set up later, as soon as incoming stream options become known
+	this.decompress = options.GetDecompress()
+	this.keyProvider = options.GetKeyProvider()
+
+	// Outgoing data are compressed first and encrypted afterwards
+	var compressed io.Writer = this.GetDataPacketFile()
+	this.Encryptor = NewEncryptor()
+	if keyID := options.GetEncryptionKeyID(); keyID != "" {
+		log.Infof("requesting encryption with key %s", keyID)
+		encoding := NewEncryptionEncoding(keyID)
+		if err = this.Encryptor.OpenWriter(encoding, this.GetDataPacketFile(), options.GetKeyProvider()); err != nil {
+			log.Errorf("UNABLE to setup encryption options. Err: %v", err)
+			return nil, err
+		}
+		// Set encoding in transport metadata and key ID in payload metadata
+		this.EnsureStreamOptions().SetEncoding(encoding)
+		this.EnsurePayloadMetadata().SetEncryptionKeyID(keyID)
+		compressed = this.Encryptor
 	}
 
-	writeCompression := CompressionNone
-	if options.GetCompress() {
-		log.Infof("requesting LZMA compression")
-		writeCompression = CompressionLZMA
+	writeCompression := options.GetWriteCompression()
+	if writeCompression != CompressionNone {
+		log.Infof("requesting %s compression", writeCompression.GetName())
 		// Set compression in transport metadata
-		this.EnsureStreamOptions().SetCompression(CompressionLZMA)
+		this.EnsureStreamOptions().SetCompression(writeCompression)
+	}
+
+	// Setup progress. Progress is tracked on the transferred data, thus total of compressed stream is unknown
+	if listener := options.GetProgressListener(); listener != nil {
+		f.SetProgressListener(listener, options.GetProgressInterval())
+		if writeCompression == CompressionNone {
+			f.SetProgressTotal(options.GetProgressTotal())
+		}
 	}
 	this.Compressor, err = NewCompressor(
-		readCompression,
-		this.GetDataPacketFile(),
+		CompressionNone,
+		nil,
 		writeCompression,
-		this.GetDataPacketFile(),
+		compressed,
 	)
 	if err != nil {
 		log.Errorf("UNABLE to setup compression options. Err: %v", err)
@@ -100,7 +171,17 @@ func (f *DataPacketFileWithOptions) Close() error {
 	}
 
 	err1 := f.Compressor.Close()
-	// Need to explicitly call Close(), because Compressor.Close() does not call Close() on underlying io.Writer()
+	if err := f.Encryptor.Close(); (err != nil) && (err1 == nil) {
+		err1 = err
+	}
+	if f.writeHash != nil {
+		// Digest of the whole stream is sent with the finalizer and is available in payload metadata afterwards
+		digest := NewDigest().SetType(f.streamDigestType).SetData(f.writeHash.Sum(nil))
+		f.SetStreamDigest(digest)
+		f.EnsurePayloadMetadata().EnsureProperties().SetDigest(digest)
+	}
+	// Need to explicitly call Close(), because neither Compressor.Close() nor Encryptor.Close() call Close()
+	// on underlying io.Writer()
 	err2 := f.GetDataPacketFile().Close()
 
 	switch {
@@ -126,14 +207,22 @@ func (f *DataPacketFileWithOptions) Write(p []byte) (n int, err error) {
 	log.Tracef("DataPacketFileWithOptions.Write() - start: %d", len(p))
 	defer log.Tracef("DataPacketFileWithOptions.Write() - end  : %d", len(p))
 
-	if f.Compressor.WriteEnabled() {
-		return f.Compressor.Write(p)
+	switch {
+	case f.Compressor.WriteEnabled():
+		n, err = f.Compressor.Write(p)
+	case f.Encryptor.WriteEnabled():
+		n, err = f.Encryptor.Write(p)
+	case f.GetDataPacketFile() != nil:
+		n, err = f.GetDataPacketFile().Write(p)
+	default:
+		return 0, fmt.Errorf("unknown write() entity")
 	}
-	if f.GetDataPacketFile() != nil {
-		return f.GetDataPacketFile().Write(p)
+
+	if f.writeHash != nil {
+		f.writeHash.Write(p[:n])
 	}
 
-	return 0, fmt.Errorf("unknown write() entity")
+	return n, err
 }
 
 // WriteTo is an io.WriterTo interface function
@@ -141,7 +230,7 @@ func (f *DataPacketFileWithOptions) WriteTo(dst io.Writer) (int64, error) {
 	log.Tracef("DataPacketFileWithOptions.WriteTo() - start")
 	defer log.Tracef("DataPacketFileWithOptions.WriteTo() - end")
 
-	return cp(dst, f)
+	return cp(dst, f, f.getRelayBufSize(defaultRelayBufSize))
 }
 
 // Read is an io.Reader interface function
@@ -149,28 +238,145 @@ func (f *DataPacketFileWithOptions) Read(p []byte) (n int, err error) {
 	log.Tracef("DataPacketFileWithOptions.Read() - start")
 	defer log.Tracef("DataPacketFileWithOptions.Read() - end")
 
-	if f.Compressor.ReadEnabled() {
-		// TODO need to read uncompressed data even if compression requested
-		log.Debugf("decompression requested")
+	n, err = f.read(p)
+	if f.verifyDigest {
+		err = f.verifyStreamDigest(p[:n], err)
+	}
+	return n, err
+}
+
+// verifyStreamDigest accumulates digest of the incoming stream and verifies it as soon as the whole stream is read.
+// Verified digest of the whole stream is put into payload metadata.
+// Stream, which ends before the sender has finalized it, is reported as ErrStreamTruncated.
+// Digest of the whole stream is calculated by the sender over the data written, before compression and encryption,
+// thus it is verified only in case incoming data are read decompressed and decrypted. Otherwise, it is left
+// to whoever decompresses and decrypts the data later, and is available via GetReceivedStreamDigest().
+func (f *DataPacketFileWithOptions) verifyStreamDigest(p []byte, err error) error {
+	if (err == io.EOF) && f.IsAnyReceived() && !f.IsLastReceived() {
+		// Sender always finalizes the stream it has started, so the stream is cut off
+		log.Warnf("%v", ErrStreamTruncated)
+		return ErrStreamTruncated
+	}
 
-		if f.GetStreamOptions() == nil {
-			log.Debugf("no stream options yet, wait for it")
-			f.receiveIDataChunkIntoBuf()
+	if !f.readsPlaintext() {
+		if err == io.EOF {
+			log.Infof("stream digest is not verified, since incoming data are not read decompressed and decrypted")
 		}
+		return err
+	}
 
-		if f.GetStreamOptions() == nil {
-			log.Warnf("got no stream options, abort")
-			return 0, fmt.Errorf("decompression requested, but no metadata available")
+	if f.readHash == nil {
+		// Digest type is announced by the sender in payload metadata, which arrives with the first chunk
+		_type := f.GetPayloadMetadata().GetProperties().GetDigest().GetType()
+		if !IsDigestTypeSupported(_type) {
+			return err
 		}
+		f.readHash, _ = NewDigestHash(_type)
+		f.readHashType = _type
+	}
+
+	f.readHash.Write(p)
 
-		if f.GetStreamOptions().HasCompression() {
-			log.Tracef("reading compressed data")
-			return f.Compressor.Read(p)
+	if (err != io.EOF) || !f.IsLastReceived() {
+		return err
+	}
+
+	expected := f.GetReceivedStreamDigest()
+	if expected == nil {
+		log.Warnf("stream digest requested, but not provided by the sender")
+		return err
+	}
+	actual := NewDigest().SetType(f.readHashType).SetData(f.readHash.Sum(nil))
+	if !actual.Equals(expected) {
+		mismatch := &DigestMismatchError{
+			Expected: expected,
+			Actual:   actual,
+			Offset:   -1,
 		}
+		log.Warnf("%v", mismatch)
+		return mismatch
+	}
 
-		log.Warnf("unknown compression method %v", f.GetStreamOptions().GetCompression().GetType())
+	f.EnsurePayloadMetadata().EnsureProperties().SetDigest(actual)
+	return err
+}
+
+// readsPlaintext checks whether incoming data are read the same as the sender has written them,
+// that is decrypted and decompressed
+func (f *DataPacketFileWithOptions) readsPlaintext() bool {
+	options := f.GetStreamOptions()
+	if options.GetEncoding().IsEncryption() && !f.Encryptor.ReadEnabled() {
+		return false
+	}
+	if (options.GetCompression().GetType() != CompressionTypeNone) && !f.Compressor.ReadEnabled() {
+		return false
+	}
+	return true
+}
+
+// openReader sets up decryptor and decompressor according to incoming stream options
+func (f *DataPacketFileWithOptions) openReader() error {
+	f.readerOpened = true
+	if !f.decompress && (f.keyProvider == nil) {
+		// Incoming data are read as-is
+		return nil
+	}
+
+	if !f.receivedAny {
+		log.Debugf("no stream options yet, wait for it")
+		f.receiveIDataChunkIntoBuf()
+	}
+
+	// Stream options arrive with the first chunk.
+	// Incoming data are decrypted first and decompressed afterwards
+	var encrypted io.Reader = f.GetDataPacketFile()
+	encoding := f.GetStreamOptions().GetEncoding()
+	if encoding.IsEncryption() && (f.keyProvider != nil) {
+		log.Debugf("decryption requested")
+		if err := f.Encryptor.OpenReader(encoding, f.GetDataPacketFile(), f.keyProvider); err != nil {
+			return err
+		}
+		encrypted = f.Encryptor
+	}
+
+	if f.decompress {
+		log.Debugf("decompression requested")
+		if encoding.IsEncryption() && !f.Encryptor.ReadEnabled() && f.GetStreamOptions().HasCompression() {
+			return fmt.Errorf("unable to decompress encrypted stream without key provider")
+		}
+		// Stream without compression specified is read as-is
+		if err := f.Compressor.OpenReader(f.GetStreamOptions().GetCompression(), encrypted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// read reads data from the underlying entity, decrypting and decompressing it if requested
+func (f *DataPacketFileWithOptions) read(p []byte) (n int, err error) {
+	if !f.readerOpened {
+		if err := f.openReader(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.Compressor.ReadEnabled() {
+		log.Tracef("reading %s compressed data", f.Compressor.ReadCompression.GetName())
+		n, err = f.Compressor.Read(p)
+		if err == io.EOF {
+			err = f.drain()
+		}
+		return n, err
+	}
 
-		return 0, fmt.Errorf("unknown compression method")
+	if f.Encryptor.ReadEnabled() {
+		log.Tracef("reading encrypted data")
+		n, err = f.Encryptor.Read(p)
+		if err == io.EOF {
+			err = f.drain()
+		}
+		return n, err
 	}
 
 	if f.GetDataPacketFile() != nil {
@@ -180,12 +386,26 @@ func (f *DataPacketFileWithOptions) Read(p []byte) (n int, err error) {
 	return 0, fmt.Errorf("unknown read() entity")
 }
 
+// drain reads the rest of the incoming stream after decompressed or decrypted data are read completely.
+// Compressed data may end, as well as decryptor stops at the last encrypted segment, before the last packet
+// is received, thus the last packet, which finalizes the stream and carries digest of the whole stream,
+// has to be read explicitly.
+func (f *DataPacketFileWithOptions) drain() error {
+	if (f.GetDataPacketFile() == nil) || f.IsLastReceived() {
+		return io.EOF
+	}
+	if _, err := io.Copy(io.Discard, f.GetDataPacketFile()); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
 // ReadFrom is an io.ReaderFrom interface function
 func (f *DataPacketFileWithOptions) ReadFrom(src io.Reader) (int64, error) {
 	log.Tracef("DataPacketFileWithOptions.ReadFrom() - start")
 	defer log.Tracef("DataPacketFileWithOptions.ReadFrom() - end")
 
-	n, err := cp(f, src)
+	n, err := cp(f, src, f.getRelayBufSize(defaultRelayBufSize))
 
 	log.Debugf("Accepted data meta:")
 	f.GetPayloadMetadata().Log()
@@ -0,0 +1,205 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// packetQueue emulates gRPC stream by keeping DataPacket(s) sent to be received afterwards
+type packetQueue struct {
+	packets []*DataPacket
+}
+
+// Send is a DataPacketWriter interface function
+func (q *packetQueue) Send(packet *DataPacket) error {
+	q.packets = append(q.packets, proto.Clone(packet).(*DataPacket))
+	return nil
+}
+
+// Recv is a DataPacketReader interface function
+func (q *packetQueue) Recv() (*DataPacket, error) {
+	if len(q.packets) == 0 {
+		return nil, io.EOF
+	}
+	packet := q.packets[0]
+	q.packets = q.packets[1:]
+	return packet, nil
+}
+
+// send sends data as one stream with options
+func send(t *testing.T, data []byte, options *DataPacketFileOptions) *packetQueue {
+	q := &packetQueue{}
+	f, err := OpenDataPacketFileWOptions(q, nil, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// receive receives the whole stream with options
+func receive(t *testing.T, q *packetQueue, options *DataPacketFileOptions) ([]byte, *DataPacketFileWithOptions, error) {
+	f, err := OpenDataPacketFileWOptions(nil, q, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	return data, f, err
+}
+
+func TestDataPacketFile(t *testing.T) {
+	keys := NewStaticKeyProvider().Add("key", bytes.Repeat([]byte{7}, 32))
+	data := bytes.Repeat([]byte("0123456789abcdef"), 10*1024)
+	uploadID := NewUuidRandom()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		options *DataPacketFileOptions
+		// tamper modifies packets of the stream before they are received
+		tamper  func(packets []*DataPacket) []*DataPacket
+		receive *DataPacketFileOptions
+		// packets specifies number of packets the stream is sent as. Zero means any
+		packets int
+		// err checks error of the stream received. Nil means no error is expected
+		err func(error) bool
+		// compressed specifies whether data are received compressed
+		compressed bool
+		// streamDigest specifies whether digest of the data sent is received
+		streamDigest bool
+		filename     string
+	}{
+		{
+			name: "encrypted",
+			data: data,
+			options: NewDataPacketFileOptions().SetChunkSize(4096).SetEncryptionKeyID("key").SetKeyProvider(keys).
+				SetStreamDigest(DigestType_DIGEST_SHA256),
+			receive:      NewDataPacketFileOptions().SetKeyProvider(keys).SetVerifyDigest(true),
+			streamDigest: true,
+		},
+		{
+			name: "encrypted data tampered",
+			data: data,
+			options: NewDataPacketFileOptions().SetChunkSize(4096).SetEncryptionKeyID("key").SetKeyProvider(keys).
+				SetStreamDigest(DigestType_DIGEST_SHA256),
+			tamper: func(packets []*DataPacket) []*DataPacket {
+				packets[1].GetData()[0] ^= 0xff
+				return packets
+			},
+			receive: NewDataPacketFileOptions().SetKeyProvider(keys).SetVerifyDigest(true),
+			err: func(err error) bool {
+				return err != nil
+			},
+		},
+		{
+			name: "encrypted digest tampered",
+			data: data,
+			options: NewDataPacketFileOptions().SetChunkSize(4096).SetEncryptionKeyID("key").SetKeyProvider(keys).
+				SetStreamDigest(DigestType_DIGEST_SHA256),
+			tamper: func(packets []*DataPacket) []*DataPacket {
+				packets[len(packets)-1].SetDigest(NewDigest().SetType(DigestType_DIGEST_SHA256).Calculate([]byte("tampered")))
+				return packets
+			},
+			receive: NewDataPacketFileOptions().SetKeyProvider(keys).SetVerifyDigest(true),
+			err: func(err error) bool {
+				var mismatch *DigestMismatchError
+				return errors.As(err, &mismatch)
+			},
+		},
+		{
+			name:    "truncated",
+			data:    data,
+			options: NewDataPacketFileOptions().SetChunkSize(4096).SetStreamDigest(DigestType_DIGEST_SHA256),
+			tamper: func(packets []*DataPacket) []*DataPacket {
+				return packets[:len(packets)-1]
+			},
+			receive: NewDataPacketFileOptions().SetVerifyDigest(true),
+			err: func(err error) bool {
+				return errors.Is(err, ErrStreamTruncated)
+			},
+		},
+		{
+			name:         "empty with digest",
+			options:      NewDataPacketFileOptions().SetChunkSize(4096).SetStreamDigest(DigestType_DIGEST_SHA256),
+			receive:      NewDataPacketFileOptions().SetVerifyDigest(true),
+			streamDigest: true,
+		},
+		{
+			name:     "empty with metadata",
+			options:  NewDataPacketFileOptions().SetMetadata(NewMetadata().SetFilename("empty").SetUploadUUID(uploadID)),
+			receive:  NewDataPacketFileOptions().SetVerifyDigest(true),
+			packets:  1,
+			filename: "empty",
+		},
+		{
+			name:         "compressed",
+			data:         data,
+			options:      NewDataPacketFileOptions().SetChunkSize(4096).SetStreamDigest(DigestType_DIGEST_SHA256).SetCompress(true),
+			receive:      NewDataPacketFileOptions().SetDecompress(true).SetVerifyDigest(true),
+			streamDigest: true,
+		},
+		{
+			// Digest of the plaintext is available for later verification in case data are not decompressed
+			name:         "compressed not decompressed",
+			data:         data,
+			options:      NewDataPacketFileOptions().SetChunkSize(4096).SetStreamDigest(DigestType_DIGEST_SHA256).SetCompress(true),
+			receive:      NewDataPacketFileOptions().SetDecompress(false).SetVerifyDigest(true),
+			compressed:   true,
+			streamDigest: true,
+		},
+	}
+	for _, test := range tests {
+		q := send(t, test.data, test.options)
+		if (test.packets > 0) && (len(q.packets) != test.packets) {
+			t.Fatalf("%s: stream is sent as %d packets instead of %d", test.name, len(q.packets), test.packets)
+		}
+		if test.tamper != nil {
+			q.packets = test.tamper(q.packets)
+		}
+		received, f, err := receive(t, q, test.receive)
+		if test.err != nil {
+			if !test.err(err) {
+				t.Fatalf("%s: stream is not rejected as expected. err: %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if bytes.Equal(received, test.data) == test.compressed {
+			t.Fatalf("%s: unexpected %d bytes of data received", test.name, len(received))
+		}
+		if !f.IsLastReceived() {
+			t.Fatalf("%s: finalizer is not received", test.name)
+		}
+		if test.streamDigest && !f.GetReceivedStreamDigest().Equals(NewDigest().SetType(DigestType_DIGEST_SHA256).Calculate(test.data)) {
+			t.Fatalf("%s: unexpected stream digest received", test.name)
+		}
+		if (test.filename != "") && ((f.GetFilename() != test.filename) || (f.GetPayloadMetadata().GetUploadUuid().String() != uploadID.String())) {
+			t.Fatalf("%s: payload metadata is not received", test.name)
+		}
+	}
+}
@@ -14,7 +14,52 @@
 
 package common
 
-import "encoding/hex"
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// DigestMismatchError is returned when digest of the received data does not match digest provided by the sender
+type DigestMismatchError struct {
+	// Expected digest, as provided by the sender
+	Expected *Digest
+	// Actual digest, as calculated by the receiver
+	Actual *Digest
+	// Offset of the data chunk with mismatched digest. Is negative for whole-stream digest
+	Offset int64
+}
+
+// Error is an error interface function
+func (e *DigestMismatchError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("stream digest mismatch. expected: %s actual: %s", e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("data chunk digest mismatch at offset %d. expected: %s actual: %s", e.Offset, e.Expected, e.Actual)
+}
+
+// NewDigestHash creates new hash.Hash to calculate digest of the specified type
+func NewDigestHash(_type DigestType) (hash.Hash, error) {
+	switch _type {
+	case DigestType_DIGEST_MD5:
+		return md5.New(), nil
+	case DigestType_DIGEST_SHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown digest type %v", _type)
+	}
+}
+
+// IsDigestTypeSupported checks whether digest of the specified type can be calculated
+func IsDigestTypeSupported(_type DigestType) bool {
+	_, err := NewDigestHash(_type)
+	return err == nil
+}
 
 // NewDigest creates bew digest
 func NewDigest() *Digest {
@@ -52,6 +97,59 @@ func (x *Digest) SetDataFromString(data string) *Digest {
 	return x
 }
 
+// Calculate calculates digest of the data. Digest type has to be set beforehand.
+func (x *Digest) Calculate(data []byte) *Digest {
+	if x == nil {
+		return nil
+	}
+	h, err := NewDigestHash(x.GetType())
+	if err != nil {
+		x.Data = nil
+		return x
+	}
+	h.Write(data)
+	x.Data = h.Sum(nil)
+	return x
+}
+
+// CalculateReader calculates digest of the data read from the reader till EOF. Digest type has to be set beforehand.
+// Returns number of bytes read.
+func (x *Digest) CalculateReader(reader io.Reader) (int64, error) {
+	if x == nil {
+		return 0, nil
+	}
+	h, err := NewDigestHash(x.GetType())
+	if err != nil {
+		x.Data = nil
+		return 0, err
+	}
+	n, err := io.Copy(h, reader)
+	if err != nil {
+		x.Data = nil
+		return n, err
+	}
+	x.Data = h.Sum(nil)
+	return n, nil
+}
+
+// Equals checks whether two digests are equal internally
+func (x *Digest) Equals(digest *Digest) bool {
+	if (x == nil) || (digest == nil) {
+		return false
+	}
+	return (x.GetType() == digest.GetType()) && bytes.Equal(x.GetData(), digest.GetData())
+}
+
+// GetKey gets key of the digest, which is unique across digests of all types, such as sha256/<hex data>.
+// Key is suitable to be used as a path or as a database key.
+func (x *Digest) GetKey() string {
+	if x == nil {
+		return ""
+	}
+	_type := strings.ToLower(strings.TrimPrefix(DigestType_name[int32(x.GetType())], "DIGEST_"))
+	return _type + "/" + x.String()
+}
+
 // String
 func (x *Digest) String() string {
 	if x == nil {
@@ -49,6 +49,14 @@ var (
 	DomainProject = NewDomain("project")
 	// DomainAsset specifies abstract asset address [general purpose domain]
 	DomainAsset = NewDomain("asset")
+	// DomainUpload specifies abstract upload (session) [general purpose domain]
+	DomainUpload = NewDomain("upload")
+	// DomainEncryption specifies abstract encryption (key) [general purpose domain]
+	DomainEncryption = NewDomain("encryption")
+	// DomainPresignedGet specifies presigned URL to get an object [general purpose domain]
+	DomainPresignedGet = NewDomain("presigned_get")
+	// DomainPresignedPut specifies presigned URL to put an object [general purpose domain]
+	DomainPresignedPut = NewDomain("presigned_put")
 
 	// DomainS3 specifies S3 domain [predefined address domain]
 	DomainS3 = NewDomain("s3")
@@ -101,6 +109,10 @@ var (
 		DomainUser,
 		DomainProject,
 		DomainAsset,
+		DomainUpload,
+		DomainEncryption,
+		DomainPresignedGet,
+		DomainPresignedPut,
 		// Predefined address domains
 		DomainS3,
 		DomainKafka,
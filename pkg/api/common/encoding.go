@@ -14,6 +14,15 @@
 
 package common
 
+import "strings"
+
+const (
+	// EncodingMethodAESGCM specifies chunked AES-GCM encryption. Encoding method carries key ID as "aes-gcm:<key ID>"
+	EncodingMethodAESGCM = "aes-gcm"
+	// encodingMethodSeparator separates encoding method and its parameter
+	encodingMethodSeparator = ":"
+)
+
 // NewEncoding
 func NewEncoding(method ...string) *Encoding {
 	f := new(Encoding)
@@ -32,6 +41,29 @@ func (x *Encoding) Set(method string) *Encoding {
 	return x
 }
 
+// NewEncryptionEncoding creates encoding, which specifies encryption with the key specified by key ID
+func NewEncryptionEncoding(keyID string) *Encoding {
+	return NewEncoding(EncodingMethodAESGCM + encodingMethodSeparator + keyID)
+}
+
+// IsEncryption checks whether encoding specifies encryption
+func (x *Encoding) IsEncryption() bool {
+	method := strings.SplitN(x.GetMethod(), encodingMethodSeparator, 2)[0]
+	return method == EncodingMethodAESGCM
+}
+
+// GetEncryptionKeyID gets ID of the encryption key
+func (x *Encoding) GetEncryptionKeyID() string {
+	if !x.IsEncryption() {
+		return ""
+	}
+	parts := strings.SplitN(x.GetMethod(), encodingMethodSeparator, 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
 // Equals
 func (x *Encoding) Equals(encoding *Encoding) bool {
 	if x == nil {
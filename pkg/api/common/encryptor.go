@@ -0,0 +1,376 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Encrypted stream starts with salt and nonce prefix, both randomly generated per stream, followed by sequence
+// of segments. Each segment consists of 4 bytes big-endian length of the sealed segment, where the highest bit marks
+// the last segment, followed by sealed segment, which is AES-GCM sealed plaintext of up to encryptionSegmentSize bytes.
+// Each stream is sealed with its own key, derived by HKDF-SHA256 out of the provided key and the salt of the stream,
+// thus number of streams encrypted with the same provided key is bound by 2^128 birthday bound of the salt
+// rather than by nonce space of AES-GCM.
+// Nonce of each segment is built of nonce prefix, segment's sequence number and the last segment flag,
+// thus segments can not be reordered, dropped or truncated unnoticed. Sequence number limits one stream
+// to 2^32 segments, which is 256TiB of plaintext.
+const (
+	// encryptionSegmentSize specifies max size of plaintext within one segment
+	encryptionSegmentSize = 64 * 1024
+	// encryptionSaltSize specifies size of random salt of the stream, which the key of the stream is derived with
+	encryptionSaltSize = 32
+	// encryptionNoncePrefixSize specifies size of random nonce prefix of the stream
+	encryptionNoncePrefixSize = 7
+	// encryptionLastSegmentFlag marks the last segment in the segment length
+	encryptionLastSegmentFlag = uint32(1) << 31
+	// encryptionKeyInfo specifies context of the key derivation, which binds derived keys to stream encryption
+	encryptionKeyInfo = "tbox stream encryption"
+)
+
+var (
+	// ErrEncryptionTruncated specifies the situation when encrypted stream ends without the last segment
+	ErrEncryptionTruncated = fmt.Errorf("encrypted stream is truncated")
+	// ErrEncryptionTooLong specifies the situation when stream is too long to be encrypted
+	ErrEncryptionTooLong = fmt.Errorf("stream is too long to be encrypted")
+)
+
+// newAEAD creates AES-GCM AEAD for the key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newStreamAEAD creates AES-GCM AEAD for the key of the stream, derived out of the key and the salt of the stream
+func newStreamAEAD(key, salt []byte) (cipher.AEAD, error) {
+	// Key of the stream has the same size as the key, thus the same AES variant is used
+	streamKey := make([]byte, len(key))
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, []byte(encryptionKeyInfo)), streamKey); err != nil {
+		return nil, err
+	}
+	return newAEAD(streamKey)
+}
+
+// encryptionNonce builds nonce of the segment
+func encryptionNonce(prefix []byte, seq uint32, last bool) []byte {
+	nonce := make([]byte, encryptionNoncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encryptionNoncePrefixSize:], seq)
+	if last {
+		nonce[encryptionNoncePrefixSize+4] = 1
+	}
+	return nonce
+}
+
+// encryptingWriter encrypts data written into it and writes encrypted stream into underlying io.Writer
+type encryptingWriter struct {
+	writer io.Writer
+	aead   cipher.AEAD
+	header []byte
+	prefix []byte
+	seq    uint32
+	buf    []byte
+	closed bool
+}
+
+// NewEncryptingWriter creates io.WriteCloser, which encrypts data with the key and writes it into `writer`.
+// Close() has to be called in order to write the last segment. Close() does not close underlying io.Writer.
+func NewEncryptingWriter(writer io.Writer, key []byte) (io.WriteCloser, error) {
+	// Header of the stream consists of salt followed by nonce prefix
+	header := make([]byte, encryptionSaltSize+encryptionNoncePrefixSize)
+	if _, err := rand.Read(header); err != nil {
+		return nil, err
+	}
+	aead, err := newStreamAEAD(key, header[:encryptionSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{
+		writer: writer,
+		aead:   aead,
+		header: header,
+		prefix: header[encryptionSaltSize:],
+		buf:    make([]byte, 0, encryptionSegmentSize),
+	}, nil
+}
+
+// writeSegment seals and writes one segment
+func (w *encryptingWriter) writeSegment(plaintext []byte, last bool) error {
+	if w.seq == 0 {
+		// Stream starts with the header
+		if _, err := w.writer.Write(w.header); err != nil {
+			return err
+		}
+	}
+	if (w.seq == math.MaxUint32) && !last {
+		// The last segment takes the last sequence number
+		return ErrEncryptionTooLong
+	}
+
+	sealed := w.aead.Seal(nil, encryptionNonce(w.prefix, w.seq, last), plaintext, nil)
+	header := uint32(len(sealed))
+	if last {
+		header |= encryptionLastSegmentFlag
+	}
+	segment := make([]byte, 4, 4+len(sealed))
+	binary.BigEndian.PutUint32(segment, header)
+	segment = append(segment, sealed...)
+	if _, err := w.writer.Write(segment); err != nil {
+		return err
+	}
+
+	w.seq++
+	return nil
+}
+
+// Write is an io.Writer interface function
+func (w *encryptingWriter) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed encrypting writer")
+	}
+	for len(p) > 0 {
+		if len(w.buf) == encryptionSegmentSize {
+			// Segment is full and is not the last one, since more data are coming
+			if err := w.writeSegment(w.buf, false); err != nil {
+				return n, err
+			}
+			w.buf = w.buf[:0]
+		}
+		c := copy(w.buf[len(w.buf):encryptionSegmentSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		n += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// Close is an io.Closer interface function
+func (w *encryptingWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.writeSegment(w.buf, true)
+}
+
+// decryptingReader reads encrypted stream from underlying io.Reader and provides decrypted data
+type decryptingReader struct {
+	reader io.Reader
+	key    []byte
+	aead   cipher.AEAD
+	prefix []byte
+	seq    uint32
+	buf    []byte
+	last   bool
+}
+
+// NewDecryptingReader creates io.Reader, which reads encrypted stream from `reader` and decrypts it with the key.
+func NewDecryptingReader(reader io.Reader, key []byte) (io.Reader, error) {
+	// Key of the stream is derived as soon as salt is read, but the key is verified right away
+	if _, err := newAEAD(key); err != nil {
+		return nil, err
+	}
+	return &decryptingReader{
+		reader: reader,
+		key:    key,
+	}, nil
+}
+
+// readSegment reads and opens one segment
+func (r *decryptingReader) readSegment() error {
+	if r.aead == nil {
+		header := make([]byte, encryptionSaltSize+encryptionNoncePrefixSize)
+		if _, err := io.ReadFull(r.reader, header); err != nil {
+			if err == io.EOF {
+				return ErrEncryptionTruncated
+			}
+			return err
+		}
+		aead, err := newStreamAEAD(r.key, header[:encryptionSaltSize])
+		if err != nil {
+			return err
+		}
+		r.aead = aead
+		r.prefix = header[encryptionSaltSize:]
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r.reader, header); err != nil {
+		if err == io.EOF {
+			return ErrEncryptionTruncated
+		}
+		return err
+	}
+	size := binary.BigEndian.Uint32(header)
+	last := size&encryptionLastSegmentFlag != 0
+	size &^= encryptionLastSegmentFlag
+	if size > encryptionSegmentSize+uint32(r.aead.Overhead()) {
+		return fmt.Errorf("encrypted segment of size %d is too big", size)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, sealed); err != nil {
+		if err == io.EOF {
+			return ErrEncryptionTruncated
+		}
+		return err
+	}
+	plaintext, err := r.aead.Open(sealed[:0], encryptionNonce(r.prefix, r.seq, last), sealed, nil)
+	if err != nil {
+		return err
+	}
+
+	r.buf = plaintext
+	r.last = last
+	r.seq++
+	return nil
+}
+
+// Read is an io.Reader interface function
+func (r *decryptingReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.last {
+			return 0, io.EOF
+		}
+		if err := r.readSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Encryptor is an encryption descriptor
+type Encryptor struct {
+	ReadEncoding  *Encoding
+	Reader        io.Reader
+	WriteEncoding *Encoding
+	Writer        io.WriteCloser
+}
+
+// NewEncryptor creates new empty encryptor
+func NewEncryptor() *Encryptor {
+	return &Encryptor{}
+}
+
+// getKey gets key specified by encoding from key provider
+func getKey(encoding *Encoding, keyProvider KeyProvider) ([]byte, error) {
+	if !encoding.IsEncryption() {
+		return nil, fmt.Errorf("unknown encoding method %s", encoding.GetMethod())
+	}
+	if keyProvider == nil {
+		return nil, fmt.Errorf("no key provider for encoding %s", encoding.GetMethod())
+	}
+	return keyProvider.GetKey(encoding.GetEncryptionKeyID())
+}
+
+// OpenReader sets up encryptor to decrypt data read from `reader` according to encoding
+func (e *Encryptor) OpenReader(encoding *Encoding, reader io.Reader, keyProvider KeyProvider) error {
+	if e == nil {
+		return fmt.Errorf("can't open reader for empty")
+	}
+
+	key, err := getKey(encoding, keyProvider)
+	if err != nil {
+		log.Warnf("FAILED to get key. err: %v", err)
+		return err
+	}
+	r, err := NewDecryptingReader(reader, key)
+	if err != nil {
+		log.Warnf("FAILED to create decrypting reader. err: %v", err)
+		return err
+	}
+
+	e.ReadEncoding = encoding
+	e.Reader = r
+	return nil
+}
+
+// OpenWriter sets up encryptor to encrypt data written into `writer` according to encoding
+func (e *Encryptor) OpenWriter(encoding *Encoding, writer io.Writer, keyProvider KeyProvider) error {
+	if e == nil {
+		return fmt.Errorf("can't open writer for empty")
+	}
+
+	key, err := getKey(encoding, keyProvider)
+	if err != nil {
+		log.Warnf("FAILED to get key. err: %v", err)
+		return err
+	}
+	w, err := NewEncryptingWriter(writer, key)
+	if err != nil {
+		log.Warnf("FAILED to create encrypting writer. err: %v", err)
+		return err
+	}
+
+	e.WriteEncoding = encoding
+	e.Writer = w
+	return nil
+}
+
+// Close is an io.Closer interface function
+// Writes the last segment into underlying io.Writer, but does not call Close() on underlying io.Writer()
+func (e *Encryptor) Close() error {
+	if (e == nil) || (e.Writer == nil) {
+		return nil
+	}
+	return e.Writer.Close()
+}
+
+// WriteEnabled checks whether write is enabled
+func (e *Encryptor) WriteEnabled() bool {
+	if e == nil {
+		return false
+	}
+	return e.WriteEncoding != nil
+}
+
+// ReadEnabled checks whether read is enabled
+func (e *Encryptor) ReadEnabled() bool {
+	if e == nil {
+		return false
+	}
+	return e.ReadEncoding != nil
+}
+
+// Write is an io.Writer function
+func (e *Encryptor) Write(p []byte) (n int, err error) {
+	if (e == nil) || (e.Writer == nil) {
+		return 0, fmt.Errorf("can't write to empty")
+	}
+	return e.Writer.Write(p)
+}
+
+// Read is an io.Reader function
+func (e *Encryptor) Read(p []byte) (n int, err error) {
+	if (e == nil) || (e.Reader == nil) {
+		return 0, fmt.Errorf("can't read from empty")
+	}
+	return e.Reader.Read(p)
+}
@@ -0,0 +1,100 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// encrypt encrypts data with the key
+func encrypt(t *testing.T, data, key []byte) []byte {
+	buf := &bytes.Buffer{}
+	w, err := NewEncryptingWriter(buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// decrypt decrypts data with the key
+func decrypt(data, key []byte) ([]byte, error) {
+	r, err := NewDecryptingReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	data := bytes.Repeat([]byte("0123456789abcdef"), encryptionSegmentSize/16+1)
+	header := encryptionSaltSize + encryptionNoncePrefixSize
+
+	tests := []struct {
+		name string
+		data []byte
+		// corrupt modifies encrypted stream
+		corrupt func(encrypted []byte) []byte
+		err     bool
+	}{
+		{name: "empty", data: nil},
+		{name: "one segment", data: data[:encryptionSegmentSize]},
+		{name: "two segments", data: data},
+		{name: "salt corrupted", data: data, err: true, corrupt: func(encrypted []byte) []byte {
+			encrypted[0] ^= 1
+			return encrypted
+		}},
+		{name: "nonce prefix corrupted", data: data, err: true, corrupt: func(encrypted []byte) []byte {
+			encrypted[encryptionSaltSize] ^= 1
+			return encrypted
+		}},
+		{name: "header truncated", data: data, err: true, corrupt: func(encrypted []byte) []byte {
+			return encrypted[:header]
+		}},
+		{name: "last segment dropped", data: data, err: true, corrupt: func(encrypted []byte) []byte {
+			return encrypted[:header+4+encryptionSegmentSize+16]
+		}},
+	}
+	for _, test := range tests {
+		encrypted := encrypt(t, test.data, key)
+		if test.corrupt != nil {
+			encrypted = test.corrupt(encrypted)
+		}
+		decrypted, err := decrypt(encrypted, key)
+		if (err != nil) != test.err {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if !test.err && !bytes.Equal(decrypted, test.data) {
+			t.Fatalf("%s: decrypted data differ", test.name)
+		}
+	}
+
+	// Each stream has its own salt, thus its own key
+	first, second := encrypt(t, data, key), encrypt(t, data, key)
+	if bytes.Equal(first[:encryptionSaltSize], second[:encryptionSaltSize]) {
+		t.Fatalf("streams share the salt")
+	}
+	if bytes.Equal(first[header:], second[header:]) {
+		t.Fatalf("streams share the ciphertext")
+	}
+}
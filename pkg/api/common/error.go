@@ -0,0 +1,40 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+// NewError creates new Error with the message
+func NewError(msg string) *Error {
+	return &Error{
+		Msg: msg,
+	}
+}
+
+// SetCode sets code
+func (x *Error) SetCode(code int64) *Error {
+	if x == nil {
+		return nil
+	}
+	x.Code = code
+	return x
+}
+
+// SetMsg sets message
+func (x *Error) SetMsg(msg string) *Error {
+	if x == nil {
+		return nil
+	}
+	x.Msg = msg
+	return x
+}
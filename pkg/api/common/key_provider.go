@@ -0,0 +1,80 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sync"
+)
+
+// KeyProvider provides encryption keys by key ID.
+// Key length selects AES variant: 16, 24 or 32 bytes for AES-128, AES-192 or AES-256 respectively.
+type KeyProvider interface {
+	// GetKey gets key by its ID
+	GetKey(keyID string) ([]byte, error)
+}
+
+// KeyProviderFunc is an adapter to use ordinary function as a KeyProvider
+type KeyProviderFunc func(keyID string) ([]byte, error)
+
+// GetKey is a KeyProvider interface function
+func (f KeyProviderFunc) GetKey(keyID string) ([]byte, error) {
+	return f(keyID)
+}
+
+// StaticKeyProvider is a KeyProvider which keeps keys in memory
+type StaticKeyProvider struct {
+	mutex sync.RWMutex
+	keys  map[string][]byte
+}
+
+// Ensure interface compatibility
+var (
+	_ KeyProvider = &StaticKeyProvider{}
+	_ KeyProvider = KeyProviderFunc(nil)
+)
+
+// NewStaticKeyProvider creates new StaticKeyProvider
+func NewStaticKeyProvider() *StaticKeyProvider {
+	return &StaticKeyProvider{
+		keys: make(map[string][]byte),
+	}
+}
+
+// Add adds key with specified ID
+func (p *StaticKeyProvider) Add(keyID string, key []byte) *StaticKeyProvider {
+	if p == nil {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.keys[keyID] = key
+	return p
+}
+
+// GetKey is a KeyProvider interface function
+func (p *StaticKeyProvider) GetKey(keyID string) ([]byte, error) {
+	if p == nil {
+		return nil, fmt.Errorf("no key provider")
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if key, ok := p.keys[keyID]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %s", keyID)
+}
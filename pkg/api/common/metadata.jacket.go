@@ -192,6 +192,26 @@ func (x *Metadata) SetTaskUUID(uuid *UUID) *Metadata {
 	return x.Set(DomainTask, DomainUUID, NewAddress().Set(uuid))
 }
 
+// GetUploadUUID
+func (x *Metadata) GetUploadUuid() *UUID {
+	return x.GetAddresses().First(DomainUpload, DomainUUID).GetUuid()
+}
+
+// SetUploadUUID
+func (x *Metadata) SetUploadUUID(uuid *UUID) *Metadata {
+	return x.Set(DomainUpload, DomainUUID, NewAddress().Set(uuid))
+}
+
+// GetEncryptionKeyID
+func (x *Metadata) GetEncryptionKeyID() string {
+	return x.GetAddresses().First(DomainEncryption, DomainCustom).GetCustom()
+}
+
+// SetEncryptionKeyID
+func (x *Metadata) SetEncryptionKeyID(keyID string) *Metadata {
+	return x.Set(DomainEncryption, DomainCustom, NewAddress().Set(keyID))
+}
+
 // GetResultDomain
 func (x *Metadata) GetResultDomain() *Domain {
 	return x.GetAddresses().First(DomainResult, DomainDomain).GetDomain()
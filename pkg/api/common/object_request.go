@@ -14,6 +14,8 @@
 
 package common
 
+import "fmt"
+
 // NewObjectRequest is a constructor
 func NewObjectRequest() *ObjectRequest {
 	return new(ObjectRequest)
@@ -67,6 +69,59 @@ func (x *ObjectRequest) AppendAddress(entities ...interface{}) *ObjectRequest {
 	return x
 }
 
+// AddJsonPath adds JSONPath filter(s) of the entity, such as $.store.book[*].author
+func (x *ObjectRequest) AddJsonPath(paths ...string) *ObjectRequest {
+	if x == nil {
+		return nil
+	}
+	x.JsonPaths = append(x.JsonPaths, paths...)
+	return x
+}
+
+// HasRange checks whether range of the entity's data is requested
+func (x *ObjectRequest) HasRange() bool {
+	if x == nil {
+		return false
+	}
+	return x.Range != nil
+}
+
+// SetRange is a setter of the range of the entity's data requested.
+// Range starts at offset and is length bytes long. Data till the end are requested in case length is not specified
+func (x *ObjectRequest) SetRange(offset int64, length ...int64) *ObjectRequest {
+	if x == nil {
+		return nil
+	}
+	x.Range = NewDataChunkProperties().SetOffset(offset)
+	if len(length) > 0 {
+		x.Range.SetLen(length[0])
+	}
+	return x
+}
+
+// ResolveRange resolves range requested within the entity of the specified size.
+// Returns offset and length of the data to be provided. The whole entity is provided in case no range requested.
+func (x *ObjectRequest) ResolveRange(size int64) (offset, length int64, err error) {
+	if !x.HasRange() {
+		return 0, size, nil
+	}
+
+	offset = x.GetRange().GetOffset()
+	if (offset < 0) || (offset > size) {
+		return 0, 0, fmt.Errorf("range offset %d is out of size %d", offset, size)
+	}
+
+	length = size - offset
+	if x.GetRange().HasLen() && (x.GetRange().GetLen() < length) {
+		length = x.GetRange().GetLen()
+	}
+	if length < 0 {
+		return 0, 0, fmt.Errorf("range len %d is negative", length)
+	}
+
+	return offset, length, nil
+}
+
 // String is a stringifier
 func (x *ObjectRequest) String() string {
 	return "to be implemented"
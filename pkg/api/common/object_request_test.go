@@ -0,0 +1,49 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+)
+
+func TestObjectRequestResolveRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *ObjectRequest
+		offset  int64
+		length  int64
+		err     bool
+	}{
+		{name: "no request", request: nil, offset: 0, length: 100},
+		{name: "no range", request: NewObjectRequest(), offset: 0, length: 100},
+		{name: "offset only", request: NewObjectRequest().SetRange(30), offset: 30, length: 70},
+		{name: "offset and len", request: NewObjectRequest().SetRange(30, 20), offset: 30, length: 20},
+		{name: "zero len", request: NewObjectRequest().SetRange(30, 0), offset: 30, length: 0},
+		{name: "len past EOF", request: NewObjectRequest().SetRange(90, 20), offset: 90, length: 10},
+		{name: "offset at EOF", request: NewObjectRequest().SetRange(100), offset: 100, length: 0},
+		{name: "offset past EOF", request: NewObjectRequest().SetRange(101), err: true},
+		{name: "negative offset", request: NewObjectRequest().SetRange(-1), err: true},
+		{name: "negative len", request: NewObjectRequest().SetRange(10, -1), err: true},
+	}
+	for _, test := range tests {
+		offset, length, err := test.request.ResolveRange(100)
+		if (err != nil) != test.err {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if (offset != test.offset) || (length != test.length) {
+			t.Fatalf("%s: range [%d, +%d) resolved", test.name, offset, length)
+		}
+	}
+}
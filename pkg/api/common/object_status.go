@@ -58,6 +58,52 @@ func (x *ObjectStatus) SetAddress(address *Address) *ObjectStatus {
 	return x
 }
 
+// HasProperties checks whether properties are specified
+func (x *ObjectStatus) HasProperties() bool {
+	if x == nil {
+		return false
+	}
+	return x.Properties != nil
+}
+
+// SetProperties sets properties
+func (x *ObjectStatus) SetProperties(properties *DataChunkProperties) *ObjectStatus {
+	if x == nil {
+		return nil
+	}
+	x.Properties = properties
+	return x
+}
+
+// EnsureProperties returns new or existing properties
+func (x *ObjectStatus) EnsureProperties() *DataChunkProperties {
+	if x == nil {
+		return nil
+	}
+	if x.HasProperties() {
+		return x.GetProperties()
+	}
+	x.SetProperties(NewDataChunkProperties())
+	return x.GetProperties()
+}
+
+// HasError checks whether error is specified
+func (x *ObjectStatus) HasError() bool {
+	if x == nil {
+		return false
+	}
+	return x.Error != nil
+}
+
+// SetError sets error
+func (x *ObjectStatus) SetError(err *Error) *ObjectStatus {
+	if x == nil {
+		return nil
+	}
+	x.Error = err
+	return x
+}
+
 // String
 func (x *ObjectStatus) String() string {
 	return "to be implemented"
@@ -46,6 +46,11 @@ type ObjectStatus struct {
 	Domain *Domain `protobuf:"bytes,200,opt,name=domain,proto3,oneof" json:"domain,omitempty"`
 	// Address represents address of an object
 	Address *Address `protobuf:"bytes,300,opt,name=address,proto3,oneof" json:"address,omitempty"`
+	// Properties represents properties of an object, such as len, offset, etc... . [Optional]
+	// Ex.: offset of the data committed by the server within the resumable upload.
+	Properties *DataChunkProperties `protobuf:"bytes,400,opt,name=properties,proto3,oneof" json:"properties,omitempty"`
+	// Error describes why the object has failed, such as invalid filter of the object request. [Optional]
+	Error *Error `protobuf:"bytes,500,opt,name=error,proto3,oneof" json:"error,omitempty"`
 }
 
 func (x *ObjectStatus) Reset() {
@@ -97,6 +102,20 @@ func (x *ObjectStatus) GetAddress() *Address {
 	return nil
 }
 
+func (x *ObjectStatus) GetProperties() *DataChunkProperties {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *ObjectStatus) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
 var File_api_common_object_status_proto protoreflect.FileDescriptor
 
 var file_api_common_object_status_proto_rawDesc = []byte{
@@ -107,7 +126,11 @@ var file_api_common_object_status_proto_rawDesc = []byte{
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x18, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
 	0x6e, 0x2f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
 	0x17, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x64, 0x6f, 0x6d, 0x61,
-	0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb8, 0x01, 0x0a, 0x0c, 0x4f, 0x62, 0x6a,
+	0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x26, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f,
+	0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x16, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc7, 0x02, 0x0a, 0x0c, 0x4f, 0x62, 0x6a,
 	0x65, 0x63, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2a, 0x0a, 0x06, 0x73, 0x74, 0x61,
 	0x74, 0x75, 0x73, 0x18, 0x64, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e,
 	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73,
@@ -117,12 +140,21 @@ var file_api_common_object_status_proto_rawDesc = []byte{
 	0x6d, 0x61, 0x69, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
 	0x73, 0x73, 0x18, 0xac, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x2e,
 	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x48, 0x01,
-	0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07,
-	0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x61, 0x64, 0x64, 0x72,
-	0x65, 0x73, 0x73, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x6c, 0x79, 0x2d, 0x69, 0x6f, 0x2f, 0x61, 0x74, 0x6c,
-	0x61, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
-	0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x88, 0x01, 0x01, 0x12, 0x45, 0x0a, 0x0a,
+	0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x90, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x44,
+	0x61, 0x74, 0x61, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69,
+	0x65, 0x73, 0x48, 0x02, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0xf4, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x03, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x88,
+	0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x42, 0x0a, 0x0a,
+	0x08, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x70, 0x72,
+	0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x73, 0x75, 0x6e, 0x73, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x75, 0x73, 0x2f, 0x74, 0x62, 0x6f,
+	0x78, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -139,20 +171,24 @@ func file_api_common_object_status_proto_rawDescGZIP() []byte {
 
 var file_api_common_object_status_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_api_common_object_status_proto_goTypes = []interface{}{
-	(*ObjectStatus)(nil), // 0: api.common.ObjectStatus
-	(*Status)(nil),       // 1: api.common.Status
-	(*Domain)(nil),       // 2: api.common.Domain
-	(*Address)(nil),      // 3: api.common.Address
+	(*ObjectStatus)(nil),        // 0: api.common.ObjectStatus
+	(*Status)(nil),              // 1: api.common.Status
+	(*Domain)(nil),              // 2: api.common.Domain
+	(*Address)(nil),             // 3: api.common.Address
+	(*DataChunkProperties)(nil), // 4: api.common.DataChunkProperties
+	(*Error)(nil),               // 5: api.common.Error
 }
 var file_api_common_object_status_proto_depIdxs = []int32{
 	1, // 0: api.common.ObjectStatus.status:type_name -> api.common.Status
 	2, // 1: api.common.ObjectStatus.domain:type_name -> api.common.Domain
 	3, // 2: api.common.ObjectStatus.address:type_name -> api.common.Address
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	4, // 3: api.common.ObjectStatus.properties:type_name -> api.common.DataChunkProperties
+	5, // 4: api.common.ObjectStatus.error:type_name -> api.common.Error
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_api_common_object_status_proto_init() }
@@ -163,6 +199,8 @@ func file_api_common_object_status_proto_init() {
 	file_api_common_status_proto_init()
 	file_api_common_address_proto_init()
 	file_api_common_domain_proto_init()
+	file_api_common_data_chunk_properties_proto_init()
+	file_api_common_error_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_api_common_object_status_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ObjectStatus); i {
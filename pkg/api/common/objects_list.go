@@ -73,6 +73,15 @@ func (x *ObjectsList) AddFile(files ...*File) *ObjectsList {
 	return x
 }
 
+// AddPresignedURL
+func (x *ObjectsList) AddPresignedURL(urls ...*PresignedURL) *ObjectsList {
+	if x == nil {
+		return nil
+	}
+	x.PresignedUrls = append(x.PresignedUrls, urls...)
+	return x
+}
+
 // LenReports
 func (x *ObjectsList) LenReports() int {
 	if x == nil {
@@ -113,6 +122,14 @@ func (x *ObjectsList) LenFiles() int {
 	return len(x.Files)
 }
 
+// LenPresignedURLs
+func (x *ObjectsList) LenPresignedURLs() int {
+	if x == nil {
+		return 0
+	}
+	return len(x.PresignedUrls)
+}
+
 /*
 // First
 func (x *ReportMulti) First() *Report {
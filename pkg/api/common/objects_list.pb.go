@@ -52,6 +52,8 @@ type ObjectsList struct {
 	ObjectStatuses []*ObjectStatus `protobuf:"bytes,800,rep,name=object_statuses,json=objectStatuses,proto3" json:"object_statuses,omitempty"`
 	// Files specifies files of the requested objects
 	Files []*File `protobuf:"bytes,900,rep,name=files,proto3" json:"files,omitempty"`
+	// PresignedURLs specifies presigned URLs of the requested objects
+	PresignedUrls []*PresignedURL `protobuf:"bytes,1000,rep,name=presigned_urls,json=presignedUrls,proto3" json:"presigned_urls,omitempty"`
 }
 
 func (x *ObjectsList) Reset() {
@@ -124,6 +126,13 @@ func (x *ObjectsList) GetFiles() []*File {
 	return nil
 }
 
+func (x *ObjectsList) GetPresignedUrls() []*PresignedURL {
+	if x != nil {
+		return x.PresignedUrls
+	}
+	return nil
+}
+
 var File_api_common_objects_list_proto protoreflect.FileDescriptor
 
 var file_api_common_objects_list_proto_rawDesc = []byte{
@@ -137,7 +146,9 @@ var file_api_common_objects_list_proto_rawDesc = []byte{
 	0x74, 0x6f, 0x1a, 0x17, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x73,
 	0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x61, 0x70, 0x69,
 	0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xaf, 0x02, 0x0a, 0x0b,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x61, 0x70, 0x69,
+	0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x65,
+	0x64, 0x5f, 0x75, 0x72, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf1, 0x02, 0x0a, 0x0b,
 	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x06, 0x73,
 	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x64, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70,
 	0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
@@ -156,11 +167,15 @@ var file_api_common_objects_list_proto_rawDesc = []byte{
 	0x61, 0x74, 0x75, 0x73, 0x52, 0x0e, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x74, 0x61, 0x74,
 	0x75, 0x73, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x84, 0x07,
 	0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
-	0x6e, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x42, 0x2c, 0x5a,
-	0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x69, 0x6e, 0x61,
-	0x72, 0x6c, 0x79, 0x2d, 0x69, 0x6f, 0x2f, 0x61, 0x74, 0x6c, 0x61, 0x73, 0x2f, 0x70, 0x6b, 0x67,
-	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x6e, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x40, 0x0a,
+	0x0e, 0x70, 0x72, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x73, 0x18,
+	0xe8, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x50, 0x72, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c,
+	0x52, 0x0d, 0x70, 0x72, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x73, 0x42,
+	0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x75,
+	0x6e, 0x73, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x75, 0x73, 0x2f, 0x74, 0x62, 0x6f, 0x78, 0x2f, 0x70,
+	0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -183,6 +198,7 @@ var file_api_common_objects_list_proto_goTypes = []interface{}{
 	(*Task)(nil),         // 3: api.common.Task
 	(*ObjectStatus)(nil), // 4: api.common.ObjectStatus
 	(*File)(nil),         // 5: api.common.File
+	(*PresignedURL)(nil), // 6: api.common.PresignedURL
 }
 var file_api_common_objects_list_proto_depIdxs = []int32{
 	1, // 0: api.common.ObjectsList.status:type_name -> api.common.Status
@@ -191,11 +207,12 @@ var file_api_common_objects_list_proto_depIdxs = []int32{
 	1, // 3: api.common.ObjectsList.statuses:type_name -> api.common.Status
 	4, // 4: api.common.ObjectsList.object_statuses:type_name -> api.common.ObjectStatus
 	5, // 5: api.common.ObjectsList.files:type_name -> api.common.File
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	6, // 6: api.common.ObjectsList.presigned_urls:type_name -> api.common.PresignedURL
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_api_common_objects_list_proto_init() }
@@ -208,6 +225,7 @@ func file_api_common_objects_list_proto_init() {
 	file_api_common_task_proto_init()
 	file_api_common_status_proto_init()
 	file_api_common_object_status_proto_init()
+	file_api_common_presigned_url_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_api_common_objects_list_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ObjectsList); i {
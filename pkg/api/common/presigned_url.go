@@ -0,0 +1,93 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewPresignedURL
+func NewPresignedURL() *PresignedURL {
+	return new(PresignedURL)
+}
+
+// SetStatus
+func (x *PresignedURL) SetStatus(status *Status) *PresignedURL {
+	if x == nil {
+		return nil
+	}
+	x.Status = status
+	return x
+}
+
+// SetFilename
+func (x *PresignedURL) SetFilename(filename string) *PresignedURL {
+	if x == nil {
+		return nil
+	}
+	x.Filename = NewFilename(filename)
+	return x
+}
+
+// SetMethod
+func (x *PresignedURL) SetMethod(method string) *PresignedURL {
+	if x == nil {
+		return nil
+	}
+	x.Method = method
+	return x
+}
+
+// SetURL
+func (x *PresignedURL) SetURL(url string) *PresignedURL {
+	if x == nil {
+		return nil
+	}
+	x.Url = NewURL(url)
+	return x
+}
+
+// SetExpires
+func (x *PresignedURL) SetExpires(expires time.Time) *PresignedURL {
+	if x == nil {
+		return nil
+	}
+	x.Expires = timestamppb.New(expires)
+	return x
+}
+
+// IsExpired checks whether URL is expired or is about to expire within the margin
+func (x *PresignedURL) IsExpired(margin time.Duration) bool {
+	if x.GetExpires() == nil {
+		return false
+	}
+	return time.Now().Add(margin).After(x.GetExpires().AsTime())
+}
+
+// IsIssued checks whether URL is issued
+func (x *PresignedURL) IsIssued() bool {
+	return x.GetStatus().Equals(StatusOK) && (x.GetUrl().GetUrl() != "")
+}
+
+// String
+func (x *PresignedURL) String() string {
+	if x == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s %s", x.GetMethod(), x.GetFilename(), x.GetUrl())
+}
@@ -0,0 +1,227 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//*
+// PresignedURL represents time-limited URL to access an object directly in object storage.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: api/common/presigned_url.proto
+
+package common
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PresignedURL represents time-limited URL to access an object directly in object storage.
+type PresignedURL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Status specifies whether URL is issued
+	Status *Status `protobuf:"bytes,100,opt,name=status,proto3" json:"status,omitempty"`
+	// Filename specifies name of the file the URL provides access to
+	Filename *Filename `protobuf:"bytes,200,opt,name=filename,proto3" json:"filename,omitempty"`
+	// Method specifies HTTP method the URL is signed for. Ex.: GET, PUT
+	Method string `protobuf:"bytes,300,opt,name=method,proto3" json:"method,omitempty"`
+	// URL specifies the presigned URL
+	Url *URL `protobuf:"bytes,400,opt,name=url,proto3" json:"url,omitempty"`
+	// Expires specifies time the URL expires at
+	Expires *timestamppb.Timestamp `protobuf:"bytes,500,opt,name=expires,proto3" json:"expires,omitempty"`
+}
+
+func (x *PresignedURL) Reset() {
+	*x = PresignedURL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_common_presigned_url_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (*PresignedURL) ProtoMessage() {}
+
+func (x *PresignedURL) ProtoReflect() protoreflect.Message {
+	mi := &file_api_common_presigned_url_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignedURL.ProtoReflect.Descriptor instead.
+func (*PresignedURL) Descriptor() ([]byte, []int) {
+	return file_api_common_presigned_url_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PresignedURL) GetStatus() *Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *PresignedURL) GetFilename() *Filename {
+	if x != nil {
+		return x.Filename
+	}
+	return nil
+}
+
+func (x *PresignedURL) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *PresignedURL) GetUrl() *URL {
+	if x != nil {
+		return x.Url
+	}
+	return nil
+}
+
+func (x *PresignedURL) GetExpires() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expires
+	}
+	return nil
+}
+
+var File_api_common_presigned_url_proto protoreflect.FileDescriptor
+
+var file_api_common_presigned_url_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x65,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0a, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x61,
+	0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61,
+	0x6d, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x14, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x75, 0x72,
+	0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe1, 0x01, 0x0a, 0x0c, 0x50, 0x72, 0x65, 0x73,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x12, 0x2a, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x64, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63,
+	0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x31, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0xc8, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x52, 0x08, 0x66,
+	0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x18, 0xac, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x12, 0x22, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x90, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x55, 0x52, 0x4c, 0x52,
+	0x03, 0x75, 0x72, 0x6c, 0x12, 0x35, 0x0a, 0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x18,
+	0xf4, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x07, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x42, 0x2c, 0x5a, 0x2a, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x75, 0x6e, 0x73, 0x69, 0x6e,
+	0x67, 0x65, 0x72, 0x75, 0x73, 0x2f, 0x74, 0x62, 0x6f, 0x78, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61,
+	0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_api_common_presigned_url_proto_rawDescOnce sync.Once
+	file_api_common_presigned_url_proto_rawDescData = file_api_common_presigned_url_proto_rawDesc
+)
+
+func file_api_common_presigned_url_proto_rawDescGZIP() []byte {
+	file_api_common_presigned_url_proto_rawDescOnce.Do(func() {
+		file_api_common_presigned_url_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_common_presigned_url_proto_rawDescData)
+	})
+	return file_api_common_presigned_url_proto_rawDescData
+}
+
+var file_api_common_presigned_url_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_common_presigned_url_proto_goTypes = []interface{}{
+	(*PresignedURL)(nil),          // 0: api.common.PresignedURL
+	(*Status)(nil),                // 1: api.common.Status
+	(*Filename)(nil),              // 2: api.common.Filename
+	(*URL)(nil),                   // 3: api.common.URL
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_api_common_presigned_url_proto_depIdxs = []int32{
+	1, // 0: api.common.PresignedURL.status:type_name -> api.common.Status
+	2, // 1: api.common.PresignedURL.filename:type_name -> api.common.Filename
+	3, // 2: api.common.PresignedURL.url:type_name -> api.common.URL
+	4, // 3: api.common.PresignedURL.expires:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_api_common_presigned_url_proto_init() }
+func file_api_common_presigned_url_proto_init() {
+	if File_api_common_presigned_url_proto != nil {
+		return
+	}
+	file_api_common_filename_proto_init()
+	file_api_common_status_proto_init()
+	file_api_common_url_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_api_common_presigned_url_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PresignedURL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_common_presigned_url_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_common_presigned_url_proto_goTypes,
+		DependencyIndexes: file_api_common_presigned_url_proto_depIdxs,
+		MessageInfos:      file_api_common_presigned_url_proto_msgTypes,
+	}.Build()
+	File_api_common_presigned_url_proto = out.File
+	file_api_common_presigned_url_proto_rawDesc = nil
+	file_api_common_presigned_url_proto_goTypes = nil
+	file_api_common_presigned_url_proto_depIdxs = nil
+}
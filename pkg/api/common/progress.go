@@ -0,0 +1,161 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval specifies how often progress is reported by default
+const DefaultProgressInterval = time.Second
+
+// Progress describes progress of the data transfer
+type Progress struct {
+	// Incoming specifies whether data are received, otherwise data are sent
+	Incoming bool
+	// Transferred specifies number of bytes transferred so far.
+	// Includes bytes transferred previously, in case transmission continues previous one
+	Transferred int64
+	// Total specifies total number of bytes to be transferred. Zero means total is unknown
+	Total int64
+	// Rate specifies average rate of the transfer in bytes per second
+	Rate float64
+	// ETA specifies estimated time left till the transfer completes. Zero in case total is unknown
+	ETA time.Duration
+	// Elapsed specifies time passed since the transfer started
+	Elapsed time.Duration
+	// Done specifies whether the transfer is completed
+	Done bool
+}
+
+// String is a stringifier
+func (p *Progress) String() string {
+	if p == nil {
+		return ""
+	}
+	if p.Total > 0 {
+		return fmt.Sprintf("%d/%d bytes, %.0f bytes/s, ETA %v", p.Transferred, p.Total, p.Rate, p.ETA)
+	}
+	return fmt.Sprintf("%d bytes, %.0f bytes/s", p.Transferred, p.Rate)
+}
+
+// ProgressListener is a user-provided function, which is notified about progress of the data transfer
+type ProgressListener func(*Progress)
+
+// ProgressTracker tracks progress of the data transfer and notifies listener not more often than once per interval.
+// The first and the final observations are always reported.
+type ProgressTracker struct {
+	mutex sync.Mutex
+
+	listener ProgressListener
+	interval time.Duration
+	incoming bool
+	total    int64
+
+	// start specifies when the first observation was made
+	start time.Time
+	// lastTimeReported specifies when last time progress was reported
+	lastTimeReported time.Time
+	// done specifies whether the final observation is reported already
+	done bool
+}
+
+// NewProgressTracker creates new ProgressTracker. Zero interval means DefaultProgressInterval
+func NewProgressTracker(listener ProgressListener, interval time.Duration, incoming bool) *ProgressTracker {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	return &ProgressTracker{
+		listener: listener,
+		interval: interval,
+		incoming: incoming,
+	}
+}
+
+// SetTotal sets total number of bytes to be transferred. Zero means total is unknown
+func (t *ProgressTracker) SetTotal(total int64) *ProgressTracker {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.total = total
+	return t
+}
+
+// GetTotal gets total number of bytes to be transferred. Zero means total is unknown
+func (t *ProgressTracker) GetTotal() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.total
+}
+
+// Observe observes progress of the transfer. Initial specifies number of bytes transferred before the transfer
+// started, such as offset of the continued transmission, transferred specifies number of bytes transferred by
+// the transfer so far. Done specifies whether the transfer is completed.
+func (t *ProgressTracker) Observe(initial, transferred int64, done bool) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+
+	now := time.Now()
+	if t.start.IsZero() {
+		t.start = now
+	}
+	switch {
+	case t.done:
+		// Final observation is reported already, nothing to report anymore
+		t.mutex.Unlock()
+		return
+	case
+		t.lastTimeReported.IsZero(),                   // Nothing reported before, report the first one
+		now.After(t.lastTimeReported.Add(t.interval)), // Report every interval
+		done: // Report the final one to complete transfer reporting
+		// Should report this observation
+	default:
+		// Should not report this observation
+		t.mutex.Unlock()
+		return
+	}
+	t.lastTimeReported = now
+	t.done = done
+
+	progress := &Progress{
+		Incoming:    t.incoming,
+		Transferred: initial + transferred,
+		Total:       t.total,
+		Elapsed:     now.Sub(t.start),
+		Done:        done,
+	}
+	if seconds := progress.Elapsed.Seconds(); seconds > 0 {
+		// Rate is based on the data transferred by this transfer only
+		progress.Rate = float64(transferred) / seconds
+	}
+	if (progress.Total > progress.Transferred) && (progress.Rate > 0) {
+		progress.ETA = time.Duration(float64(progress.Total-progress.Transferred) / progress.Rate * float64(time.Second))
+	}
+	t.mutex.Unlock()
+
+	// Listener is called without lock held, so it is free to spend time
+	t.listener(progress)
+}
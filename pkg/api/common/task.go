@@ -50,6 +50,8 @@ const (
 	TaskAddress            int32 = 1200
 	TaskExtract            int32 = 1300
 	TaskExtractExecutables int32 = 1400
+	// Bandwidth limit is coming
+	TaskThrottle int32 = 1500
 )
 
 var TaskTypeEnum = NewEnum()
@@ -70,6 +72,7 @@ func init() {
 	TaskTypeEnum.MustRegister("TaskAddress", TaskAddress)
 	TaskTypeEnum.MustRegister("TaskExtract", TaskExtract)
 	TaskTypeEnum.MustRegister("TaskExtractExecutables", TaskExtractExecutables)
+	TaskTypeEnum.MustRegister("TaskThrottle", TaskThrottle)
 }
 
 // NewTask creates new Command with pre-allocated header
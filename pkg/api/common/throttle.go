@@ -0,0 +1,286 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RateLimiter is a token bucket, which limits rate of the data in bytes per second.
+// Bucket is refilled at the rate and holds up to burst bytes. Data bigger than the bucket are let through as well,
+// but the following data wait till the debt is repaid, thus the average rate stays within the limit.
+type RateLimiter struct {
+	mutex sync.Mutex
+	// rate specifies max rate in bytes per second. Zero means no limit
+	rate int64
+	// burst specifies capacity of the bucket in bytes
+	burst int64
+	// tokens specifies number of bytes available right now. Negative means debt
+	tokens float64
+	// last specifies when tokens were refilled last time
+	last time.Time
+}
+
+// NewRateLimiter creates new RateLimiter. Zero rate means no limit, zero burst means one second worth of data
+func NewRateLimiter(rate, burst int64) *RateLimiter {
+	return new(RateLimiter).SetRate(rate, burst)
+}
+
+// SetRate sets max rate in bytes per second and capacity of the bucket. Can be called at any time.
+// Zero rate means no limit, zero burst means one second worth of data
+func (l *RateLimiter) SetRate(rate, burst int64) *RateLimiter {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if burst <= 0 {
+		burst = rate
+	}
+	if (l.rate <= 0) && (rate > 0) {
+		// Limit is introduced, start with the full bucket
+		l.tokens = float64(burst)
+		l.last = time.Now()
+	}
+	l.rate = rate
+	l.burst = burst
+	l.tokens = math.Min(l.tokens, float64(burst))
+	return l
+}
+
+// GetRate gets max rate in bytes per second. Zero means no limit
+func (l *RateLimiter) GetRate() int64 {
+	if l == nil {
+		return 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.rate
+}
+
+// reserve takes n bytes out of the bucket and returns how long to wait till they are allowed
+func (l *RateLimiter) reserve(n int) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	l.tokens = math.Min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*float64(l.rate))
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+}
+
+// cancel puts n bytes, which are not going to be sent, back into the bucket
+func (l *RateLimiter) cancel(n int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.rate > 0 {
+		l.tokens = math.Min(float64(l.burst), l.tokens+float64(n))
+	}
+}
+
+// Wait waits till n bytes are allowed by the limit or till the context is done.
+// Returns context's error in case the context is done before n bytes are allowed
+func (l *RateLimiter) Wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	delay := l.reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(n)
+		return ctx.Err()
+	}
+}
+
+// Throttle limits bandwidth of outgoing data of each stream and of all streams throttled by it altogether.
+// Limits can be adjusted at any time and apply to streams in progress as well.
+type Throttle struct {
+	mutex sync.Mutex
+	// stream specifies max rate of each stream in bytes per second. Zero means no limit
+	stream int64
+	// burst specifies how many bytes can be sent at once above the limit. Zero means one second worth of data
+	burst int64
+	// process limits all streams altogether
+	process *RateLimiter
+}
+
+// DefaultThrottle is the process-wide throttle, which applies to streams with no throttle specified explicitly.
+// It imposes no limits unless configured.
+var DefaultThrottle = NewThrottle()
+
+// NewThrottle creates new Throttle with no limits
+func NewThrottle() *Throttle {
+	return &Throttle{
+		process: NewRateLimiter(0, 0),
+	}
+}
+
+// SetStreamRate sets max rate of each stream in bytes per second. Zero means no limit
+func (t *Throttle) SetStreamRate(rate int64) *Throttle {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stream = rate
+	return t
+}
+
+// GetStreamRate gets max rate of each stream in bytes per second. Zero means no limit
+func (t *Throttle) GetStreamRate() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.stream
+}
+
+// SetProcessRate sets max rate of all streams altogether in bytes per second. Zero means no limit
+func (t *Throttle) SetProcessRate(rate int64) *Throttle {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.process.SetRate(rate, t.burst)
+	return t
+}
+
+// GetProcessRate gets max rate of all streams altogether in bytes per second. Zero means no limit
+func (t *Throttle) GetProcessRate() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.process.GetRate()
+}
+
+// SetBurst sets how many bytes can be sent at once above the limit. Zero means one second worth of data
+func (t *Throttle) SetBurst(burst int64) *Throttle {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.burst = burst
+	t.process.SetRate(t.process.GetRate(), burst)
+	return t
+}
+
+// GetBurst gets how many bytes can be sent at once above the limit. Zero means one second worth of data
+func (t *Throttle) GetBurst() int64 {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.burst
+}
+
+// SetBandwidthLimit applies limits specified in BandwidthLimit. Limits not specified are left intact
+func (t *Throttle) SetBandwidthLimit(limit *BandwidthLimit) *Throttle {
+	if t == nil {
+		return nil
+	}
+	if limit.HasBurst() {
+		t.SetBurst(limit.GetBurst())
+	}
+	if limit.HasStream() {
+		t.SetStreamRate(limit.GetStream())
+	}
+	if limit.HasProcess() {
+		t.SetProcessRate(limit.GetProcess())
+	}
+	log.Infof("bandwidth limit set. stream: %d process: %d burst: %d", t.GetStreamRate(), t.GetProcessRate(), t.GetBurst())
+	return t
+}
+
+// ApplyTask applies bandwidth limit carried by TaskThrottle task
+func (t *Throttle) ApplyTask(task *Task) error {
+	if task.GetType() != TaskThrottle {
+		return fmt.Errorf("unexpected task type %d, expecting %d", task.GetType(), TaskThrottle)
+	}
+	limit := NewBandwidthLimit()
+	if err := task.GetPayload(limit); err != nil {
+		return err
+	}
+	t.SetBandwidthLimit(limit)
+	return nil
+}
+
+// NewStreamLimiter creates limiter for one stream throttled by this Throttle
+func (t *Throttle) NewStreamLimiter() *StreamLimiter {
+	if t == nil {
+		return nil
+	}
+	return &StreamLimiter{
+		throttle: t,
+		stream:   NewRateLimiter(0, 0),
+	}
+}
+
+// StreamLimiter limits bandwidth of one stream according to limits of its Throttle
+type StreamLimiter struct {
+	throttle *Throttle
+	stream   *RateLimiter
+	// rate and burst specify limits of the Throttle applied to the stream limiter last time
+	rate  int64
+	burst int64
+}
+
+// Wait waits till n bytes are allowed to be sent by both stream and process limits or till the context is done.
+// Returns context's error in case the context is done before n bytes are allowed
+func (l *StreamLimiter) Wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	// Limits may be adjusted while the stream is in progress
+	if rate, burst := l.throttle.GetStreamRate(), l.throttle.GetBurst(); (rate != l.rate) || (burst != l.burst) {
+		l.stream.SetRate(rate, burst)
+		l.rate, l.burst = rate, burst
+	}
+	if err := l.stream.Wait(ctx, n); err != nil {
+		return err
+	}
+	return l.throttle.process.Wait(ctx, n)
+}
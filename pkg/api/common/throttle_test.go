@@ -0,0 +1,116 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  int64
+		burst int64
+		// sizes specifies sizes of data sent one after another
+		sizes []int
+		// min and max bound delay of the last data
+		min time.Duration
+		max time.Duration
+	}{
+		{name: "no limit", rate: 0, burst: 0, sizes: []int{1 << 30, 1 << 30}, min: 0, max: 0},
+		{name: "within burst", rate: 1000, burst: 100, sizes: []int{50, 50}, min: 0, max: 0},
+		{name: "above burst", rate: 1000, burst: 100, sizes: []int{100, 100}, min: 90 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "debt", rate: 1000, burst: 100, sizes: []int{600, 1}, min: 490 * time.Millisecond, max: 501 * time.Millisecond},
+		{name: "zero burst is one second", rate: 1000, burst: 0, sizes: []int{1000, 100}, min: 90 * time.Millisecond, max: 100 * time.Millisecond},
+	}
+	for _, test := range tests {
+		l := NewRateLimiter(test.rate, test.burst)
+		var delay time.Duration
+		for _, size := range test.sizes {
+			delay = l.reserve(size)
+		}
+		if (delay < test.min) || (delay > test.max) {
+			t.Fatalf("%s: delay %v is out of [%v, %v]", test.name, delay, test.min, test.max)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(10000, 100)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background(), 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first 100 bytes are within burst, the following 200 bytes take 20ms
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("300 bytes sent within %v", elapsed)
+	}
+
+	// Wait is abandoned as soon as the context is done and bytes not sent are put back into the bucket
+	l = NewRateLimiter(1, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := l.Wait(ctx, 100); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("wait is abandoned within %v", elapsed)
+	}
+	if l.tokens != 1 {
+		t.Fatalf("%f tokens left after abandoned wait", l.tokens)
+	}
+}
+
+func TestStreamLimiterAdjust(t *testing.T) {
+	throttle := NewThrottle()
+	l := throttle.NewStreamLimiter()
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		limit *BandwidthLimit
+		rate  int64
+		burst int64
+	}{
+		{name: "no limit", limit: NewBandwidthLimit(), rate: 0, burst: 0},
+		{name: "stream rate", limit: NewBandwidthLimit().SetStream(1 << 30), rate: 1 << 30, burst: 1 << 30},
+		{name: "burst only", limit: NewBandwidthLimit().SetBurst(1 << 20), rate: 1 << 30, burst: 1 << 20},
+		{name: "rate only", limit: NewBandwidthLimit().SetStream(1 << 29), rate: 1 << 29, burst: 1 << 20},
+		{name: "limit lifted", limit: NewBandwidthLimit().SetStream(0), rate: 0, burst: 1 << 20},
+	}
+	for _, test := range tests {
+		throttle.SetBandwidthLimit(test.limit)
+		// Limits are applied to the stream in progress on the next data sent
+		if err := l.Wait(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if (l.stream.rate != test.rate) || (l.stream.burst != test.burst) {
+			t.Fatalf("%s: stream limiter has rate %d burst %d", test.name, l.stream.rate, l.stream.burst)
+		}
+	}
+
+	// Process limit applies to all streams altogether
+	throttle.SetBandwidthLimit(NewBandwidthLimit().SetProcess(1).SetBurst(1))
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(cancelled, 100); err != context.Canceled {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -20,13 +20,16 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// defaultRelayBufSize specifies default size of relay buffer used by cp
+const defaultRelayBufSize = 32 * 1024
+
 // cp copies from src into dst. Have to use `cp` because `copy` is a non-exported built-in function
 func cp(dst io.Writer, src io.Reader, sizes ...int) (int64, error) {
 	log.Tracef("cp() - start")
 	defer log.Tracef("cp() - end")
 
 	// Allocate relay buffer
-	size := 32 * 1024
+	size := defaultRelayBufSize
 	if len(sizes) > 0 {
 		size = sizes[0]
 	}
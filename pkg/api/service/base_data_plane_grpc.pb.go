@@ -31,6 +31,14 @@ type DataPlaneClient interface {
 	// Uni-directional Data stream. One object is uploaded from the client to the server.
 	// Returns status of the uploaded object.
 	DownloadObject(ctx context.Context, in *common.ObjectRequest, opts ...grpc.CallOption) (DataPlane_DownloadObjectClient, error)
+	// Status of the object being uploaded by UploadObject call.
+	// Used by resumable uploads in order to find out how many bytes are already committed by the server.
+	UploadObjectStatus(ctx context.Context, in *common.ObjectRequest, opts ...grpc.CallOption) (*common.ObjectStatus, error)
+	// Deduplicated upload. Client specifies payload metadata of the object to be uploaded, with digest and len
+	// of the object's data in properties. In case server already has the data with the same digest, it links
+	// existing data to the object and reports it as created, thus client skips data transfer.
+	// Reports not found otherwise, thus client has to upload the object with UploadObject call.
+	LinkObject(ctx context.Context, in *common.Metadata, opts ...grpc.CallOption) (*common.ObjectStatus, error)
 }
 
 type dataPlaneClient struct {
@@ -172,6 +180,24 @@ func (x *dataPlaneDownloadObjectClient) Recv() (*common.DataPacket, error) {
 	return m, nil
 }
 
+func (c *dataPlaneClient) UploadObjectStatus(ctx context.Context, in *common.ObjectRequest, opts ...grpc.CallOption) (*common.ObjectStatus, error) {
+	out := new(common.ObjectStatus)
+	err := c.cc.Invoke(ctx, "/api.service.DataPlane/UploadObjectStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataPlaneClient) LinkObject(ctx context.Context, in *common.Metadata, opts ...grpc.CallOption) (*common.ObjectStatus, error) {
+	out := new(common.ObjectStatus)
+	err := c.cc.Invoke(ctx, "/api.service.DataPlane/LinkObject", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DataPlaneServer is the server API for DataPlane service.
 // All implementations must embed UnimplementedDataPlaneServer
 // for forward compatibility
@@ -188,6 +214,14 @@ type DataPlaneServer interface {
 	// Uni-directional Data stream. One object is uploaded from the client to the server.
 	// Returns status of the uploaded object.
 	DownloadObject(*common.ObjectRequest, DataPlane_DownloadObjectServer) error
+	// Status of the object being uploaded by UploadObject call.
+	// Used by resumable uploads in order to find out how many bytes are already committed by the server.
+	UploadObjectStatus(context.Context, *common.ObjectRequest) (*common.ObjectStatus, error)
+	// Deduplicated upload. Client specifies payload metadata of the object to be uploaded, with digest and len
+	// of the object's data in properties. In case server already has the data with the same digest, it links
+	// existing data to the object and reports it as created, thus client skips data transfer.
+	// Reports not found otherwise, thus client has to upload the object with UploadObject call.
+	LinkObject(context.Context, *common.Metadata) (*common.ObjectStatus, error)
 	mustEmbedUnimplementedDataPlaneServer()
 }
 
@@ -207,6 +241,12 @@ func (UnimplementedDataPlaneServer) UploadObjects(DataPlane_UploadObjectsServer)
 func (UnimplementedDataPlaneServer) DownloadObject(*common.ObjectRequest, DataPlane_DownloadObjectServer) error {
 	return status.Errorf(codes.Unimplemented, "method DownloadObject not implemented")
 }
+func (UnimplementedDataPlaneServer) UploadObjectStatus(context.Context, *common.ObjectRequest) (*common.ObjectStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadObjectStatus not implemented")
+}
+func (UnimplementedDataPlaneServer) LinkObject(context.Context, *common.Metadata) (*common.ObjectStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LinkObject not implemented")
+}
 func (UnimplementedDataPlaneServer) mustEmbedUnimplementedDataPlaneServer() {}
 
 // UnsafeDataPlaneServer may be embedded to opt out of forward compatibility for this service.
@@ -319,13 +359,58 @@ func (x *dataPlaneDownloadObjectServer) Send(m *common.DataPacket) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _DataPlane_UploadObjectStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.ObjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataPlaneServer).UploadObjectStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.service.DataPlane/UploadObjectStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataPlaneServer).UploadObjectStatus(ctx, req.(*common.ObjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataPlane_LinkObject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Metadata)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataPlaneServer).LinkObject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.service.DataPlane/LinkObject",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataPlaneServer).LinkObject(ctx, req.(*common.Metadata))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DataPlane_ServiceDesc is the grpc.ServiceDesc for DataPlane service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var DataPlane_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "api.service.DataPlane",
 	HandlerType: (*DataPlaneServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UploadObjectStatus",
+			Handler:    _DataPlane_UploadObjectStatus_Handler,
+		},
+		{
+			MethodName: "LinkObject",
+			Handler:    _DataPlane_LinkObject_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "DataChunks",
@@ -17,8 +17,12 @@ package items
 import (
 	"bytes"
 	"fmt"
+	"time"
 )
 
+// DefaultMinIOPresignExpiry specifies default expiry of the presigned URLs
+const DefaultMinIOPresignExpiry = 15 * time.Minute
+
 // IMPORTANT
 // IMPORTANT Do not forget to update String() function
 // IMPORTANT
@@ -34,6 +38,8 @@ type MinIO struct {
 	Bucket           string `mapstructure:"bucket"`
 	BucketAutoCreate bool   `mapstructure:"bucketAutoCreate"`
 	Region           string `mapstructure:"region"`
+	// PresignExpiry specifies expiry of the presigned URLs. Ex.: 15m
+	PresignExpiry time.Duration `mapstructure:"presignExpiry"`
 	// IMPORTANT
 	// IMPORTANT Do not forget to update String() function
 	// IMPORTANT
@@ -116,6 +122,14 @@ func (m *MinIO) GetRegion() string {
 	return m.Region
 }
 
+// GetPresignExpiry is a getter. Returns DefaultMinIOPresignExpiry in case expiry is not specified
+func (m *MinIO) GetPresignExpiry() time.Duration {
+	if (m == nil) || (m.PresignExpiry <= 0) {
+		return DefaultMinIOPresignExpiry
+	}
+	return m.PresignExpiry
+}
+
 // String is a stringifier
 func (m *MinIO) String() string {
 	if m == nil {
@@ -133,6 +147,7 @@ func (m *MinIO) String() string {
 	_, _ = fmt.Fprintf(b, "Bucket: %v\n", m.Bucket)
 	_, _ = fmt.Fprintf(b, "BucketAutoCreate: %v\n", m.BucketAutoCreate)
 	_, _ = fmt.Fprintf(b, "Region: %v\n", m.Region)
+	_, _ = fmt.Fprintf(b, "PresignExpiry: %v\n", m.PresignExpiry)
 
 	return b.String()
 }
@@ -0,0 +1,78 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package items
+
+import (
+	"encoding/json"
+)
+
+// ThrottleConfigurator - bandwidth limits of outgoing data related section of config
+type ThrottleConfigurator interface {
+	GetStream() int64
+	GetProcess() int64
+	GetBurst() int64
+}
+
+// IMPORTANT
+// IMPORTANT Do not forget to update String() function
+// IMPORTANT
+type Throttle struct {
+	// Stream limits each stream individually, in bytes per second. Zero means no limit
+	Stream int64 `mapstructure:"stream"`
+	// Process limits all streams of the process altogether, in bytes per second. Zero means no limit
+	Process int64 `mapstructure:"process"`
+	// Burst specifies how many bytes can be sent at once above the limit. Zero means one second worth of data
+	Burst int64 `mapstructure:"burst"`
+	// IMPORTANT
+	// IMPORTANT Do not forget to update String() function
+	// IMPORTANT
+}
+
+var _ ThrottleConfigurator = &Throttle{}
+
+// NewThrottle is a constructor
+func NewThrottle() *Throttle {
+	return &Throttle{}
+}
+
+// GetStream is a getter
+func (t *Throttle) GetStream() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Stream
+}
+
+// GetProcess is a getter
+func (t *Throttle) GetProcess() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Process
+}
+
+// GetBurst is a getter
+func (t *Throttle) GetBurst() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Burst
+}
+
+// String is a stringifier
+func (t *Throttle) String() string {
+	self, _ := json.Marshal(t)
+	return string(self)
+}
@@ -16,6 +16,7 @@ package sections
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sunsingerus/tbox/pkg/config/items"
 )
@@ -30,6 +31,7 @@ type MinIOConfigurator interface {
 	GetMinIOBucket() string
 	GetMinIOBucketAutoCreate() bool
 	GetMinIORegion() string
+	GetMinIOPresignExpiry() time.Duration
 }
 
 // Interface compatibility
@@ -88,6 +90,11 @@ func (c MinIO) GetMinIORegion() string {
 	return c.MinIO.GetRegion()
 }
 
+// GetMinIOPresignExpiry
+func (c MinIO) GetMinIOPresignExpiry() time.Duration {
+	return c.MinIO.GetPresignExpiry()
+}
+
 // String
 func (c MinIO) String() string {
 	return fmt.Sprintf("MinIO=%s", c.MinIO)
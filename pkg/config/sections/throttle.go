@@ -0,0 +1,52 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sections
+
+import (
+	"fmt"
+
+	"github.com/sunsingerus/tbox/pkg/config/items"
+)
+
+// ThrottleConfigurator - bandwidth limits of outgoing data related section of config
+type ThrottleConfigurator interface {
+	GetThrottle() items.ThrottleConfigurator
+}
+
+// Interface compatibility
+var _ ThrottleConfigurator = Throttle{}
+
+// Throttle
+type Throttle struct {
+	Throttle *items.Throttle `mapstructure:"throttle"`
+}
+
+// ThrottleNormalize is a normalizer
+func (t Throttle) ThrottleNormalize() Throttle {
+	if t.Throttle == nil {
+		t.Throttle = items.NewThrottle()
+	}
+	return t
+}
+
+// GetThrottle is a getter
+func (t Throttle) GetThrottle() items.ThrottleConfigurator {
+	return t.Throttle
+}
+
+// String is a stringifier
+func (t Throttle) String() string {
+	return fmt.Sprintf("Throttle=%s", t.Throttle)
+}
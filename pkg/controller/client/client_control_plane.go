@@ -20,14 +20,23 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/sunsingerus/tbox/pkg/api/service"
 	"github.com/sunsingerus/tbox/pkg/controller"
 )
 
 // TasksExchange exchanges tasks
 func TasksExchange(ControlPlaneClient service.ControlPlaneClient) {
-	// ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	if err := TasksExchangeContext(context.Background(), ControlPlaneClient); err != nil {
+		log.Fatalf("ControlPlaneClient.Tasks() failed %v", err)
+		os.Exit(1)
+	}
+}
+
+// TasksExchangeContext exchanges tasks till the stream is broken or the context is done.
+// TaskThrottle tasks received are applied by HandleThrottleTask, all other tasks are queued into incoming tasks.
+func TasksExchangeContext(ctx context.Context, ControlPlaneClient service.ControlPlaneClient) error {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	//this code sends token per each RPC call:
@@ -36,11 +45,32 @@ func TasksExchange(ControlPlaneClient service.ControlPlaneClient) {
 
 	rpcTasks, err := ControlPlaneClient.Tasks(ctx)
 	if err != nil {
-		log.Fatalf("ControlPlaneClient.Tasks() failed %v", err)
-		os.Exit(1)
+		return err
 	}
 	defer rpcTasks.CloseSend()
 
 	log.Infof("Tasks() called")
-	controller.TasksExchangeEndlessLoop(rpcTasks)
+	controller.TasksExchangeEndlessLoop(throttleTasksReceiver{rpcTasks})
+	// Loop ends either when the stream is completed by the server or when the context is done
+	return ctx.Err()
+}
+
+// throttleTasksReceiver applies TaskThrottle tasks as soon as they are received and passes all other tasks through
+type throttleTasksReceiver struct {
+	controller.TaskSenderReceiver
+}
+
+// Recv receives the next task, which is not TaskThrottle task
+func (r throttleTasksReceiver) Recv() (*common.Task, error) {
+	for {
+		task, err := r.TaskSenderReceiver.Recv()
+		if (task == nil) || (task.GetType() != common.TaskThrottle) {
+			return task, err
+		}
+		// Throttle task, which can not be applied, is reported by the handler and is not passed through either
+		_ = HandleThrottleTask(task)
+		if err != nil {
+			return nil, err
+		}
+	}
 }
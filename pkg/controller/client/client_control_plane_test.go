@@ -0,0 +1,70 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// tasksStream emulates control plane stream, which has tasks to be received
+type tasksStream struct {
+	tasks []*common.Task
+}
+
+// Send is a TaskSenderReceiver interface function
+func (s *tasksStream) Send(*common.Task) error {
+	return nil
+}
+
+// Recv is a TaskSenderReceiver interface function
+func (s *tasksStream) Recv() (*common.Task, error) {
+	if len(s.tasks) == 0 {
+		return nil, io.EOF
+	}
+	task := s.tasks[0]
+	s.tasks = s.tasks[1:]
+	return task, nil
+}
+
+func TestThrottleTasksReceiver(t *testing.T) {
+	rate := common.DefaultThrottle.GetStreamRate()
+	defer common.DefaultThrottle.SetStreamRate(rate)
+
+	throttle, err := common.NewBandwidthLimit().SetStream(rate + 1000).NewTask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := common.NewTask()
+	other.EnsureHeader().SetType(common.TaskThrottle + 1)
+	r := throttleTasksReceiver{&tasksStream{tasks: []*common.Task{throttle, other}}}
+
+	// Throttle task is applied, but is not passed through
+	task, err := r.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task != other {
+		t.Fatalf("unexpected task %v", task)
+	}
+	if common.DefaultThrottle.GetStreamRate() != rate+1000 {
+		t.Fatalf("throttle task is not applied")
+	}
+	if _, err := r.Recv(); err != io.EOF {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
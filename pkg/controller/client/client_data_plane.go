@@ -22,6 +22,7 @@ import (
 
 	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/controller"
 )
 
 // DataExchange send data to server and receives back reply (if needed)
@@ -29,14 +30,36 @@ func DataExchange(
 	DataPlaneClient service.DataPlaneClient,
 	src io.Reader,
 	options *DataExchangeOptions,
+) *DataExchangeResult {
+	return DataExchangeContext(context.Background(), DataPlaneClient, src, options)
+}
+
+// DataExchangeContext send data to server and receives back reply (if needed)
+func DataExchangeContext(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	src io.Reader,
+	options *DataExchangeOptions,
 ) *DataExchangeResult {
 	log.Infof("DataExchange() - start")
 	defer log.Infof("DataExchange() - end")
 
+	source := newRetrySource(src, options.GetRetryPolicy())
+	return options.GetRetryPolicy().Do(ctx, "DataExchange", source.Rewind, func() *DataExchangeResult {
+		return dataExchange(ctx, DataPlaneClient, source.Reader(), options)
+	})
+}
+
+// dataExchange makes one attempt to send data to server and to receive back reply (if needed)
+func dataExchange(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	src io.Reader,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
 	result := NewDataExchangeResult()
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	var DataChunksBiMultiClient service.DataPlane_DataChunksClient
@@ -47,24 +70,10 @@ func DataExchange(
 		return result
 	}
 
-	defer func() {
-		// This is hand-made flush() replacement for gRPC
-		// It is required in order to flush all outstanding data before
-		// context's cancel() is called, which simply discards all outstanding data.
-		// On receiving end, when cancel() is the first in the race, f receives 'cancel' and (sometimes) no data
-		// instead of complete set of data and EOF
-		// See https://github.com/grpc/grpc-go/issues/1714 for more details
-		DataChunksBiMultiClient.CloseSend()
-		DataChunksBiMultiClient.Recv()
-	}()
-
 	f, err := common.OpenDataPacketFileWOptions(
 		DataChunksBiMultiClient,
 		DataChunksBiMultiClient,
-		common.NewDataPacketFileOptions().
-			SetMetadata(options.GetMetadata()).
-			SetCompress(options.GetCompress()).
-			SetDecompress(options.GetDecompress()),
+		options.GetDataPacketFileOptions(),
 	)
 	if err != nil {
 		log.Errorf("DataPlaneClient.DataExchange() failed %v", result.Error)
@@ -76,11 +85,24 @@ func DataExchange(
 		// We have something to send
 		result.Send.Data.Len, result.Error = f.ReadFrom(src)
 		if result.Error != nil {
+			// Stream is cancelled on return, thus incomplete data are not finalized
+			result.Error = getStreamError(DataChunksBiMultiClient, new(common.DataPacket), result.Error)
 			log.Warnf("DataPlaneClient.DataExchange() failed with err %v", result.Error)
 			return result
 		}
 	}
 
+	// Finalize transmission and half-close the stream, so server knows all data are sent.
+	// Server is still able to reply.
+	if result.Error = f.Close(); result.Error == nil {
+		result.Error = DataChunksBiMultiClient.CloseSend()
+	}
+	if result.Error != nil {
+		result.Error = getStreamError(DataChunksBiMultiClient, new(common.DataPacket), result.Error)
+		log.Warnf("DataPlaneClient.DataExchange() failed with err %v", result.Error)
+		return result
+	}
+
 	if options.GetWaitReply() {
 		// We should wait for reply
 		result.Recv.Data.Len, result.Recv.Data.Data, result.Error = f.WriteToBuf()
@@ -91,22 +113,64 @@ func DataExchange(
 	}
 	result.Recv.Data.Metadata = f.GetPayloadMetadata()
 
+	// Wait for the server to complete the stream before the stream's context is cancelled,
+	// because cancellation discards all outstanding data.
+	// See https://github.com/grpc/grpc-go/issues/1714 for more details
+	if err := drainDataChunksClient(DataChunksBiMultiClient); err != nil {
+		log.Warnf("DataPlaneClient.DataExchange() failed with err %v", err)
+		result.Error = err
+	}
+
 	return result
 }
 
+// drainDataChunksClient receives and discards whatever is left in the stream till the server completes it
+func drainDataChunksClient(client service.DataPlane_DataChunksClient) error {
+	for {
+		if _, err := client.Recv(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // Upload send data to server and receives back status(es)
 func Upload(
 	DataPlaneClient service.DataPlaneClient,
 	src io.Reader,
 	options *DataExchangeOptions,
+) *DataExchangeResult {
+	return UploadContext(context.Background(), DataPlaneClient, src, options)
+}
+
+// UploadContext send data to server and receives back status(es)
+func UploadContext(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	src io.Reader,
+	options *DataExchangeOptions,
 ) *DataExchangeResult {
 	log.Infof("Upload() - start")
 	defer log.Infof("Upload() - end")
 
+	source := newRetrySource(src, options.GetRetryPolicy())
+	return options.GetRetryPolicy().Do(ctx, "Upload", source.Rewind, func() *DataExchangeResult {
+		return upload(ctx, DataPlaneClient, source.Reader(), options)
+	})
+}
+
+// upload makes one attempt to send data to server and to receive back status(es)
+func upload(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	src io.Reader,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
 	result := NewDataExchangeResult()
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	var DataChunksUpOneClient service.DataPlane_UploadObjectClient
@@ -119,10 +183,7 @@ func Upload(
 	f, err := common.OpenDataPacketFileWOptions(
 		DataChunksUpOneClient,
 		nil,
-		common.NewDataPacketFileOptions().
-			SetMetadata(options.GetMetadata()).
-			SetCompress(options.GetCompress()).
-			SetDecompress(options.GetDecompress()),
+		options.GetDataPacketFileOptions(),
 	)
 	if err != nil {
 		log.Errorf("DataPlaneClient.Upload() failed %v", result.Error)
@@ -134,30 +195,135 @@ func Upload(
 		// We have something to send
 		result.Send.Data.Len, result.Error = f.ReadFrom(src)
 		if result.Error != nil {
+			result.Error = getStreamError(DataChunksUpOneClient, new(common.ObjectStatus), result.Error)
 			log.Warnf("DataPlaneClient.Upload() failed with err %v", result.Error)
 			return result
 		}
 	}
 
+	// Finalize transmission, so server knows the object is complete
+	if result.Error = f.Close(); result.Error != nil {
+		result.Error = getStreamError(DataChunksUpOneClient, new(common.ObjectStatus), result.Error)
+		log.Warnf("DataPlaneClient.Upload() failed with err %v", result.Error)
+		return result
+	}
+	if options.GetDigest() != common.DigestType_DIGEST_RESERVED {
+		result.Send.Data.Digest = f.GetPayloadMetadata().GetProperties().GetDigest()
+	}
+
 	result.Recv.ObjectStatus, result.Error = DataChunksUpOneClient.CloseAndRecv()
 
 	return result
 }
 
+// UploadObjects sends multiple objects to server via one stream and receives back statuses of the objects.
+func UploadObjects(
+	DataPlaneClient service.DataPlaneClient,
+	srcs []io.Reader,
+	options []*DataExchangeOptions,
+) *DataExchangeResult {
+	return UploadObjectsContext(context.Background(), DataPlaneClient, srcs, options)
+}
+
+// UploadObjectsContext sends multiple objects to server via one stream and receives back statuses of the objects.
+// Each object is sent with its own options, payload metadata in particular, and is finalized with the last packet.
+// Statuses of the objects are provided in result.Recv.ObjectsList in the same order as objects are sent.
+func UploadObjectsContext(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	srcs []io.Reader,
+	options []*DataExchangeOptions,
+) *DataExchangeResult {
+	log.Infof("UploadObjects() - start")
+	defer log.Infof("UploadObjects() - end")
+
+	// Stream carries all objects, thus retry policy of the first object applies to the whole stream
+	var policy *RetryPolicy
+	if len(options) > 0 {
+		policy = options[0].GetRetryPolicy()
+	}
+	sources := make([]*retrySource, len(srcs))
+	for i := range srcs {
+		sources[i] = newRetrySource(srcs[i], policy)
+	}
+	rewind := func() error {
+		for _, source := range sources {
+			if err := source.Rewind(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return policy.Do(ctx, "UploadObjects", rewind, func() *DataExchangeResult {
+		readers := make([]io.Reader, len(sources))
+		for i := range sources {
+			readers[i] = sources[i].Reader()
+		}
+		return uploadObjects(ctx, DataPlaneClient, readers, options)
+	})
+}
+
+// uploadObjects makes one attempt to send multiple objects to server via one stream
+func uploadObjects(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	srcs []io.Reader,
+	options []*DataExchangeOptions,
+) *DataExchangeResult {
+	result := NewDataExchangeResult()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var UploadObjectsClient service.DataPlane_UploadObjectsClient
+	UploadObjectsClient, result.Error = DataPlaneClient.UploadObjects(ctx)
+	if result.Error != nil {
+		log.Errorf("DataPlaneClient.UploadObjects() failed %v", result.Error)
+		return result
+	}
+
+	objects := controller.NewObjectsWriter(UploadObjectsClient)
+	for i, src := range srcs {
+		var objectOptions *DataExchangeOptions
+		if i < len(options) {
+			objectOptions = options[i]
+		}
+
+		n, err := objects.WriteObject(src, objectOptions.GetDataPacketFileOptions())
+		result.Send.Data.Len += n
+		if err != nil {
+			result.Error = getStreamError(UploadObjectsClient, new(common.ObjectsList), err)
+			log.Warnf("DataPlaneClient.UploadObjects() failed to send object %d with err %v", i, result.Error)
+			return result
+		}
+	}
+
+	result.Recv.ObjectsList, result.Error = UploadObjectsClient.CloseAndRecv()
+
+	return result
+}
+
 // Download downloads data from server
 func Download(
 	DataPlaneClient service.DataPlaneClient,
 	dst io.Writer,
 	taskId, filename string,
 	options *DataExchangeOptions,
+) *DataExchangeResult {
+	return DownloadContext(context.Background(), DataPlaneClient, dst, taskId, filename, options)
+}
+
+// DownloadContext downloads data from server
+func DownloadContext(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	dst io.Writer,
+	taskId, filename string,
+	options *DataExchangeOptions,
 ) *DataExchangeResult {
 	log.Infof("Download() - start")
 	defer log.Infof("Download() - end")
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	request := common.NewObjectRequest().
 		AppendAddress(
 			common.DomainTaskID,
@@ -168,6 +334,48 @@ func Download(
 			common.NewAddress().Set(common.NewFilename(filename)),
 		)
 
+	return DownloadObjectContext(ctx, DataPlaneClient, dst, request, options)
+}
+
+// DownloadObject downloads data of the object specified by the request from server.
+func DownloadObject(
+	DataPlaneClient service.DataPlaneClient,
+	dst io.Writer,
+	request *common.ObjectRequest,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	return DownloadObjectContext(context.Background(), DataPlaneClient, dst, request, options)
+}
+
+// DownloadObjectContext downloads data of the object specified by the request from server.
+// Request may specify range of the object's data to be downloaded.
+func DownloadObjectContext(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	dst io.Writer,
+	request *common.ObjectRequest,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	log.Infof("DownloadObject() - start")
+	defer log.Infof("DownloadObject() - end")
+
+	destination := newRetryWriter(dst, options.GetRetryPolicy())
+	return options.GetRetryPolicy().Do(ctx, "DownloadObject", destination.Rewind, func() *DataExchangeResult {
+		return downloadObject(ctx, DataPlaneClient, destination.Writer(), request, options)
+	})
+}
+
+// downloadObject makes one attempt to download data of the object specified by the request from server
+func downloadObject(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	dst io.Writer,
+	request *common.ObjectRequest,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	var client service.DataPlane_DownloadObjectClient
 	result := NewDataExchangeResult()
 
@@ -180,10 +388,7 @@ func Download(
 	f, err := common.OpenDataPacketFileWOptions(
 		nil,
 		client,
-		common.NewDataPacketFileOptions().
-			SetMetadata(options.GetMetadata()).
-			SetCompress(options.GetCompress()).
-			SetDecompress(options.GetDecompress()),
+		options.GetDataPacketFileOptions(),
 	)
 	if err != nil {
 		log.Errorf("DataPlaneClient.Download() failed %v", result.Error)
@@ -203,3 +408,139 @@ func Download(
 
 	return result
 }
+
+// DownloadObjects downloads multiple objects specified by the request from server via one stream.
+func DownloadObjects(
+	DataPlaneClient service.DataPlaneClient,
+	request *common.ObjectRequest,
+	options *DataExchangeOptions,
+	handler func(*common.Metadata, io.Reader) (int64, error),
+) *DataExchangeResult {
+	return DownloadObjectsContext(context.Background(), DataPlaneClient, request, options, handler)
+}
+
+// DownloadObjectsContext downloads multiple objects specified by the request from server via one stream.
+// Handler is called for each object with payload metadata and data of the object.
+func DownloadObjectsContext(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	request *common.ObjectRequest,
+	options *DataExchangeOptions,
+	handler func(*common.Metadata, io.Reader) (int64, error),
+) *DataExchangeResult {
+	log.Infof("DownloadObjects() - start")
+	defer log.Infof("DownloadObjects() - end")
+
+	// Objects already provided to the handler can not be taken back, thus retry is possible before the first object only
+	handled := false
+	rewind := func() error {
+		if handled {
+			return ErrRetryNotRewindable
+		}
+		return nil
+	}
+	return options.GetRetryPolicy().Do(ctx, "DownloadObjects", rewind, func() *DataExchangeResult {
+		return downloadObjects(ctx, DataPlaneClient, request, options, func(metadata *common.Metadata, reader io.Reader) (int64, error) {
+			handled = true
+			return handler(metadata, reader)
+		})
+	})
+}
+
+// downloadObjects makes one attempt to download multiple objects specified by the request from server via one stream
+func downloadObjects(
+	ctx context.Context,
+	DataPlaneClient service.DataPlaneClient,
+	request *common.ObjectRequest,
+	options *DataExchangeOptions,
+	handler func(*common.Metadata, io.Reader) (int64, error),
+) *DataExchangeResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var client service.DataPlane_DownloadObjectClient
+	result := NewDataExchangeResult()
+
+	client, result.Error = DataPlaneClient.DownloadObject(ctx, request)
+	if result.Error != nil {
+		log.Errorf("DataPlaneClient.DownloadObjects() failed %v", result.Error)
+		return result
+	}
+
+	objects := controller.NewObjectsReader(client, options.GetDataPacketFileOptions())
+	for {
+		metadata, reader, err := objects.Next()
+		if err == io.EOF {
+			// No more objects in the stream
+			return result
+		}
+		if err != nil {
+			log.Warnf("DataPlaneClient.DownloadObjects() failed with err %v", err)
+			result.Error = err
+			return result
+		}
+
+		n, err := handler(metadata, reader)
+		result.Recv.Data.Len += n
+		if err != nil {
+			log.Warnf("DataPlaneClient.DownloadObjects() failed to handle object %d with err %v", objects.Index(), err)
+			result.Error = err
+			return result
+		}
+	}
+}
+
+// GetUploadStatus requests status of the resumable upload.
+func GetUploadStatus(DataPlaneClient service.DataPlaneClient, uploadID *common.UUID) *DataExchangeResult {
+	return GetUploadStatusContext(context.Background(), DataPlaneClient, uploadID)
+}
+
+// GetUploadStatusContext requests status of the resumable upload.
+// Offset of the data committed by the server is reported in properties of the object status.
+func GetUploadStatusContext(ctx context.Context, DataPlaneClient service.DataPlaneClient, uploadID *common.UUID) *DataExchangeResult {
+	log.Infof("GetUploadStatus() - start")
+	defer log.Infof("GetUploadStatus() - end")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	request := common.NewObjectRequest().
+		SetRequestDomain(common.DomainUpload).
+		SetResultDomain(common.DomainStatus).
+		AppendAddress(
+			common.DomainUpload,
+			common.NewAddress().Set(uploadID),
+		)
+
+	result := NewDataExchangeResult()
+	result.Recv.ObjectStatus, result.Error = DataPlaneClient.UploadObjectStatus(ctx, request)
+	if result.Error != nil {
+		log.Errorf("DataPlaneClient.GetUploadStatus() failed %v", result.Error)
+	}
+
+	return result
+}
+
+// LinkObject asks service to link data already known to the service to the object described by metadata.
+// Digest and len of the object's data are specified in metadata properties.
+func LinkObject(DataPlaneClient service.DataPlaneClient, metadata *common.Metadata) *DataExchangeResult {
+	return LinkObjectContext(context.Background(), DataPlaneClient, metadata)
+}
+
+// LinkObjectContext asks service to link data already known to the service to the object described by metadata.
+// Object status is StatusCreated in case data are linked and StatusNotFound in case data are unknown to the service.
+func LinkObjectContext(ctx context.Context, DataPlaneClient service.DataPlaneClient, metadata *common.Metadata) *DataExchangeResult {
+	log.Infof("LinkObject() - start")
+	defer log.Infof("LinkObject() - end")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := NewDataExchangeResult()
+	result.Recv.ObjectStatus, result.Error = DataPlaneClient.LinkObject(ctx, metadata)
+	if result.Error != nil {
+		log.Errorf("DataPlaneClient.LinkObject() failed %v", result.Error)
+	}
+
+	return result
+}
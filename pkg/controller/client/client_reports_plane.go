@@ -25,11 +25,15 @@ import (
 
 // GetTaskStatus requests status(es) of the task
 func GetTaskStatus(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID) *DataExchangeResult {
+	return GetTaskStatusContext(context.Background(), ReportsPlaneClient, taskUUID)
+}
+
+// GetTaskStatusContext requests status(es) of the task
+func GetTaskStatusContext(ctx context.Context, ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID) *DataExchangeResult {
 	log.Infof("Status() - start")
 	defer log.Infof("Status() - end")
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// One object request
@@ -54,11 +58,15 @@ func GetTaskStatus(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *comm
 
 // GetTask requests task
 func GetTask(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID) *DataExchangeResult {
+	return GetTaskContext(context.Background(), ReportsPlaneClient, taskUUID)
+}
+
+// GetTaskContext requests task
+func GetTaskContext(ctx context.Context, ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID) *DataExchangeResult {
 	log.Infof("Task() - start")
 	defer log.Infof("Task() - end")
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// One object request
@@ -78,11 +86,15 @@ func GetTask(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUI
 
 // GetTaskReport requests report(es) of the task
 func GetTaskReport(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID) *DataExchangeResult {
+	return GetTaskReportContext(context.Background(), ReportsPlaneClient, taskUUID)
+}
+
+// GetTaskReportContext requests report(es) of the task
+func GetTaskReportContext(ctx context.Context, ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID) *DataExchangeResult {
 	log.Infof("Report() - start")
 	defer log.Infof("Report() - end")
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// One object request
@@ -102,11 +114,15 @@ func GetTaskReport(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *comm
 
 // GetTaskFiles requests file(es) of the task
 func GetTaskFiles(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID, jps []string) *DataExchangeResult {
+	return GetTaskFilesContext(context.Background(), ReportsPlaneClient, taskUUID, jps)
+}
+
+// GetTaskFilesContext requests file(es) of the task
+func GetTaskFilesContext(ctx context.Context, ReportsPlaneClient service.ReportsPlaneClient, taskUUID *common.UUID, jps []string) *DataExchangeResult {
 	log.Infof("Files() - start")
 	defer log.Infof("Files() - end")
 
-	//ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// One object request
@@ -128,3 +144,45 @@ func GetTaskFiles(ReportsPlaneClient service.ReportsPlaneClient, taskUUID *commo
 
 	return result
 }
+
+// GetTaskFilePresignedURL requests presigned URL of the file of the task
+func GetTaskFilePresignedURL(ReportsPlaneClient service.ReportsPlaneClient, domain *common.Domain, taskId, filename string) *DataExchangeResult {
+	return GetTaskFilePresignedURLContext(context.Background(), ReportsPlaneClient, domain, taskId, filename)
+}
+
+// GetTaskFilePresignedURLContext requests presigned URL of the file of the task.
+// Domain specifies URL requested, either common.DomainPresignedGet or common.DomainPresignedPut.
+// Filename is relative to the task, such as out/result.json
+func GetTaskFilePresignedURLContext(
+	ctx context.Context,
+	ReportsPlaneClient service.ReportsPlaneClient,
+	domain *common.Domain,
+	taskId, filename string,
+) *DataExchangeResult {
+	log.Infof("PresignedURL() - start")
+	defer log.Infof("PresignedURL() - end")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// One object request
+	objectRequest := common.NewObjectRequest().
+		AppendAddress(
+			common.DomainTaskID,
+			common.NewAddress().Set(common.NewUuidFromString(taskId)),
+		).
+		AppendAddress(
+			common.DomainFilename,
+			common.NewAddress().Set(common.NewFilename(filename)),
+		)
+	// Multi-object request
+	request := common.NewObjectsRequest()
+	request.SetRequestDomain(common.DomainTask)
+	request.SetResultDomain(domain)
+	request.Append(objectRequest)
+	// Unify call result
+	result := NewDataExchangeResult()
+	result.Recv.ObjectsList, result.Error = ReportsPlaneClient.ObjectsReport(ctx, request)
+
+	return result
+}
@@ -16,7 +16,10 @@ package controller_client
 
 import (
 	"bytes"
+	"context"
+	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/controller"
 	log "github.com/sirupsen/logrus"
 	"io"
 	"os"
@@ -24,6 +27,11 @@ import (
 
 // DownloadIntoFile sends file from client to service and receives response back (if any)
 func DownloadIntoFile(client service.DataPlaneClient, taskId, filename string, options *DataExchangeOptions) *DataExchangeResult {
+	return DownloadIntoFileContext(context.Background(), client, taskId, filename, options)
+}
+
+// DownloadIntoFileContext sends file from client to service and receives response back (if any)
+func DownloadIntoFileContext(ctx context.Context, client service.DataPlaneClient, taskId, filename string, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("DownloadIntoFile() - start")
 	defer log.Info("DownloadIntoFile() - end")
 
@@ -34,25 +42,35 @@ func DownloadIntoFile(client service.DataPlaneClient, taskId, filename string, o
 	}
 	defer f.Close()
 
-	return DownloadIntoWriter(client, taskId, filename, f, options)
+	return DownloadIntoWriterContext(ctx, client, taskId, filename, f, options)
 }
 
 // DownloadIntoStdout sends STDIN from client to service and receives response back (if any)
 func DownloadIntoStdout(client service.DataPlaneClient, taskId, filename string, options *DataExchangeOptions) *DataExchangeResult {
+	return DownloadIntoStdoutContext(context.Background(), client, taskId, filename, options)
+}
+
+// DownloadIntoStdoutContext sends STDIN from client to service and receives response back (if any)
+func DownloadIntoStdoutContext(ctx context.Context, client service.DataPlaneClient, taskId, filename string, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("DownloadIntoStdout() - start")
 	defer log.Info("DownloadIntoStdout() - end")
 
-	return DownloadIntoWriter(client, taskId, filename, os.Stdout, options)
+	return DownloadIntoWriterContext(ctx, client, taskId, filename, os.Stdout, options)
 }
 
 // DownloadIntoBuffer downloads into bytes.Buffer of DataExchangeResult
 func DownloadIntoBuffer(client service.DataPlaneClient, taskId, filename string, options *DataExchangeOptions) *DataExchangeResult {
+	return DownloadIntoBufferContext(context.Background(), client, taskId, filename, options)
+}
+
+// DownloadIntoBufferContext downloads into bytes.Buffer of DataExchangeResult
+func DownloadIntoBufferContext(ctx context.Context, client service.DataPlaneClient, taskId, filename string, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("DownloadIntoBuffer() - start")
 	defer log.Info("DownloadIntoBuffer() - end")
 
 	buf := &bytes.Buffer{}
 
-	res := DownloadIntoWriter(client, taskId, filename, buf, options)
+	res := DownloadIntoWriterContext(ctx, client, taskId, filename, buf, options)
 	res.Recv.Data.Len = int64(buf.Len())
 	res.Recv.Data.Data = buf
 	return res
@@ -60,10 +78,15 @@ func DownloadIntoBuffer(client service.DataPlaneClient, taskId, filename string,
 
 // DownloadIntoWriter downloads into io.Writer
 func DownloadIntoWriter(client service.DataPlaneClient, taskId, filename string, w io.Writer, options *DataExchangeOptions) *DataExchangeResult {
+	return DownloadIntoWriterContext(context.Background(), client, taskId, filename, w, options)
+}
+
+// DownloadIntoWriterContext downloads into io.Writer
+func DownloadIntoWriterContext(ctx context.Context, client service.DataPlaneClient, taskId, filename string, w io.Writer, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("DownloadIntoWriter() - start")
 	defer log.Info("DownloadIntoWriter() - end")
 
-	result := Download(client, w, taskId, filename, options)
+	result := DownloadContext(ctx, client, w, taskId, filename, options)
 	if result.Error == nil {
 		log.Infof("DONE send %s size %d", "io.Reader", result.Send.Data.Len)
 	} else {
@@ -72,3 +95,28 @@ func DownloadIntoWriter(client service.DataPlaneClient, taskId, filename string,
 
 	return result
 }
+
+// DownloadDir downloads directory tree specified by the request into dir.
+func DownloadDir(client service.DataPlaneClient, dir string, request *common.ObjectRequest, options *DataExchangeOptions) *DataExchangeResult {
+	return DownloadDirContext(context.Background(), client, dir, request, options)
+}
+
+// DownloadDirContext downloads directory tree specified by the request into dir.
+// Relative paths, permissions and modification times of the entries are preserved.
+func DownloadDirContext(ctx context.Context, client service.DataPlaneClient, dir string, request *common.ObjectRequest, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("DownloadDir() - start")
+	defer log.Info("DownloadDir() - end")
+
+	tree := controller.NewDirTreeWriter(dir)
+	result := DownloadObjectsContext(ctx, client, request, options, tree.Write)
+	if err := tree.Close(); (err != nil) && (result.Error == nil) {
+		result.Error = err
+	}
+	if result.Error == nil {
+		log.Infof("DONE download dir %s size %d", dir, result.Recv.Data.Len)
+	} else {
+		log.Warnf("FAILED download dir %s size %d err %v", dir, result.Recv.Data.Len, result.Error)
+	}
+
+	return result
+}
@@ -15,6 +15,10 @@
 package controller_client
 
 import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
 	"github.com/sunsingerus/tbox/pkg/api/common"
 )
 
@@ -22,12 +26,40 @@ import (
 type DataExchangeOptions struct {
 	// compress specifies whether to compress data on send
 	compress bool
+	// compression specifies compression of data on send. LZMA is used in case compress is requested without compression
+	compression *common.Compression
 	// decompress specifies whether to decompress data on receive
 	decompress bool
 	// waitReply specifies whether to wait for answer/reply
 	waitReply bool
 	// metadata describes data stream
 	metadata *common.Metadata
+	// offset specifies offset of the data being sent within the whole object. Used to resume interrupted upload
+	offset int64
+	// encryptionKeyID specifies key to encrypt data on send with. Empty means no encryption
+	encryptionKeyID string
+	// keyProvider provides keys to encrypt data on send and to decrypt data on receive
+	keyProvider common.KeyProvider
+	// chunkSize specifies max size of the payload of one outgoing data chunk. Zero means default
+	chunkSize int
+	// adaptiveChunkSize specifies whether size of outgoing data chunks should adapt to observed throughput
+	adaptiveChunkSize bool
+	// digest specifies type of the digest of outgoing data chunks and the whole outgoing stream
+	digest common.DigestType
+	// verifyDigest specifies whether digests of incoming data should be verified
+	verifyDigest bool
+	// partRetries specifies how many times failed part of the parallel upload is retried. Zero means default
+	partRetries int
+	// retryPolicy specifies how failed calls are retried. Nil means no retries
+	retryPolicy *RetryPolicy
+	// progressListener is notified about progress of the data transfer. Optional
+	progressListener common.ProgressListener
+	// progressInterval specifies how often progress is reported. Zero means default
+	progressInterval time.Duration
+	// progressTotal specifies total size of data to be sent. Zero means unknown
+	progressTotal int64
+	// throttle limits bandwidth of data to be sent. Nil means common.DefaultThrottle
+	throttle *common.Throttle
 }
 
 // NewDataExchangeOptions
@@ -52,6 +84,23 @@ func (opts *DataExchangeOptions) GetCompress() bool {
 	return opts.compress
 }
 
+// SetCompression
+func (opts *DataExchangeOptions) SetCompression(compression *common.Compression) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.compression = compression
+	return opts
+}
+
+// GetCompression
+func (opts *DataExchangeOptions) GetCompression() *common.Compression {
+	if opts == nil {
+		return nil
+	}
+	return opts.compression
+}
+
 // SetDecompress
 func (opts *DataExchangeOptions) SetDecompress(decompress bool) *DataExchangeOptions {
 	if opts == nil {
@@ -103,6 +152,248 @@ func (opts *DataExchangeOptions) GetMetadata() *common.Metadata {
 	return opts.metadata
 }
 
+// SetOffset
+func (opts *DataExchangeOptions) SetOffset(offset int64) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.offset = offset
+	return opts
+}
+
+// GetOffset
+func (opts *DataExchangeOptions) GetOffset() int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.offset
+}
+
+// SetEncryptionKeyID
+func (opts *DataExchangeOptions) SetEncryptionKeyID(keyID string) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.encryptionKeyID = keyID
+	return opts
+}
+
+// GetEncryptionKeyID
+func (opts *DataExchangeOptions) GetEncryptionKeyID() string {
+	if opts == nil {
+		return ""
+	}
+	return opts.encryptionKeyID
+}
+
+// SetKeyProvider
+func (opts *DataExchangeOptions) SetKeyProvider(keyProvider common.KeyProvider) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.keyProvider = keyProvider
+	return opts
+}
+
+// GetKeyProvider
+func (opts *DataExchangeOptions) GetKeyProvider() common.KeyProvider {
+	if opts == nil {
+		return nil
+	}
+	return opts.keyProvider
+}
+
+// SetChunkSize
+func (opts *DataExchangeOptions) SetChunkSize(size int) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.chunkSize = size
+	return opts
+}
+
+// GetChunkSize
+func (opts *DataExchangeOptions) GetChunkSize() int {
+	if opts == nil {
+		return 0
+	}
+	return opts.chunkSize
+}
+
+// SetAdaptiveChunkSize
+func (opts *DataExchangeOptions) SetAdaptiveChunkSize(adaptive bool) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.adaptiveChunkSize = adaptive
+	return opts
+}
+
+// GetAdaptiveChunkSize
+func (opts *DataExchangeOptions) GetAdaptiveChunkSize() bool {
+	if opts == nil {
+		return false
+	}
+	return opts.adaptiveChunkSize
+}
+
+// SetDigest
+func (opts *DataExchangeOptions) SetDigest(digest common.DigestType) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.digest = digest
+	return opts
+}
+
+// GetDigest
+func (opts *DataExchangeOptions) GetDigest() common.DigestType {
+	if opts == nil {
+		return common.DigestType_DIGEST_RESERVED
+	}
+	return opts.digest
+}
+
+// SetVerifyDigest
+func (opts *DataExchangeOptions) SetVerifyDigest(verify bool) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.verifyDigest = verify
+	return opts
+}
+
+// GetVerifyDigest
+func (opts *DataExchangeOptions) GetVerifyDigest() bool {
+	if opts == nil {
+		return false
+	}
+	return opts.verifyDigest
+}
+
+// SetPartRetries
+func (opts *DataExchangeOptions) SetPartRetries(retries int) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.partRetries = retries
+	return opts
+}
+
+// GetPartRetries
+func (opts *DataExchangeOptions) GetPartRetries() int {
+	if opts == nil {
+		return 0
+	}
+	return opts.partRetries
+}
+
+// SetRetryPolicy
+func (opts *DataExchangeOptions) SetRetryPolicy(policy *RetryPolicy) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.retryPolicy = policy
+	return opts
+}
+
+// GetRetryPolicy
+func (opts *DataExchangeOptions) GetRetryPolicy() *RetryPolicy {
+	if opts == nil {
+		return nil
+	}
+	return opts.retryPolicy
+}
+
+// SetProgressListener
+func (opts *DataExchangeOptions) SetProgressListener(listener common.ProgressListener) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.progressListener = listener
+	return opts
+}
+
+// GetProgressListener
+func (opts *DataExchangeOptions) GetProgressListener() common.ProgressListener {
+	if opts == nil {
+		return nil
+	}
+	return opts.progressListener
+}
+
+// SetProgressInterval
+func (opts *DataExchangeOptions) SetProgressInterval(interval time.Duration) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.progressInterval = interval
+	return opts
+}
+
+// GetProgressInterval
+func (opts *DataExchangeOptions) GetProgressInterval() time.Duration {
+	if opts == nil {
+		return 0
+	}
+	return opts.progressInterval
+}
+
+// SetProgressTotal
+func (opts *DataExchangeOptions) SetProgressTotal(total int64) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.progressTotal = total
+	return opts
+}
+
+// GetProgressTotal
+func (opts *DataExchangeOptions) GetProgressTotal() int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.progressTotal
+}
+
+// SetThrottle
+func (opts *DataExchangeOptions) SetThrottle(throttle *common.Throttle) *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	opts.throttle = throttle
+	return opts
+}
+
+// GetThrottle
+func (opts *DataExchangeOptions) GetThrottle() *common.Throttle {
+	if opts == nil {
+		return nil
+	}
+	return opts.throttle
+}
+
+// GetDataPacketFileOptions builds options for DataPacketFile to exchange data with
+func (opts *DataExchangeOptions) GetDataPacketFileOptions() *common.DataPacketFileOptions {
+	return common.NewDataPacketFileOptions().
+		SetMetadata(opts.GetMetadata()).
+		SetCompress(opts.GetCompress()).
+		SetCompression(opts.GetCompression()).
+		SetDecompress(opts.GetDecompress()).
+		SetOffset(opts.GetOffset()).
+		SetEncryptionKeyID(opts.GetEncryptionKeyID()).
+		SetKeyProvider(opts.GetKeyProvider()).
+		SetChunkSize(opts.GetChunkSize()).
+		SetAdaptiveChunkSize(opts.GetAdaptiveChunkSize()).
+		SetChunkDigest(opts.GetDigest()).
+		SetStreamDigest(opts.GetDigest()).
+		SetVerifyDigest(opts.GetVerifyDigest()).
+		SetProgressListener(opts.GetProgressListener()).
+		SetProgressInterval(opts.GetProgressInterval()).
+		SetProgressTotal(opts.GetProgressTotal()).
+		SetThrottle(opts.GetThrottle())
+}
+
 // Ensure
 func (opts *DataExchangeOptions) Ensure() *DataExchangeOptions {
 	if opts == nil {
@@ -111,6 +402,18 @@ func (opts *DataExchangeOptions) Ensure() *DataExchangeOptions {
 	return opts
 }
 
+// Clone makes copy of the options, metadata is deep-copied
+func (opts *DataExchangeOptions) Clone() *DataExchangeOptions {
+	if opts == nil {
+		return nil
+	}
+	clone := *opts
+	if opts.metadata != nil {
+		clone.metadata = proto.Clone(opts.metadata).(*common.Metadata)
+	}
+	return &clone
+}
+
 // EnsureMetadata
 func (opts *DataExchangeOptions) EnsureMetadata() *common.Metadata {
 	if opts == nil {
@@ -0,0 +1,112 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+)
+
+// DownloadPresigned downloads file of the task directly from object storage via presigned URL
+func DownloadPresigned(
+	ReportsPlaneClient service.ReportsPlaneClient,
+	DataPlaneClient service.DataPlaneClient,
+	dst io.Writer,
+	taskId, filename string,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	return DownloadPresignedContext(context.Background(), ReportsPlaneClient, DataPlaneClient, dst, taskId, filename, options)
+}
+
+// DownloadPresignedContext downloads file of the task directly from object storage via presigned URL.
+// Falls back to streaming download via DataPlane in case presigned URL is not issued or can not be fetched.
+// Fallback is not possible in case direct download fails after some data are written into dst.
+// Options which require data to be processed as a stream, such as decompression, decryption or
+// digest verification, are not applicable to direct download, thus file is streamed right away.
+func DownloadPresignedContext(
+	ctx context.Context,
+	ReportsPlaneClient service.ReportsPlaneClient,
+	DataPlaneClient service.DataPlaneClient,
+	dst io.Writer,
+	taskId, filename string,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	log.Infof("DownloadPresigned() - start")
+	defer log.Infof("DownloadPresigned() - end")
+
+	if options.GetDecompress() || (options.GetKeyProvider() != nil) || options.GetVerifyDigest() {
+		return DownloadContext(ctx, DataPlaneClient, dst, taskId, filename, options)
+	}
+
+	destination := &retryWriter{dst: dst, retry: true}
+	result := downloadPresigned(ctx, ReportsPlaneClient, destination, taskId, filename)
+	if result.Error == nil {
+		return result
+	}
+	if destination.written > 0 {
+		log.Warnf("presigned download of %s/%s failed after %d bytes, unable to fall back. err: %v", taskId, filename, destination.written, result.Error)
+		return result
+	}
+	if ctx.Err() != nil {
+		return result
+	}
+
+	log.Infof("presigned download of %s/%s failed, fall back to streaming. err: %v", taskId, filename, result.Error)
+	return DownloadContext(ctx, DataPlaneClient, dst, taskId, filename, options)
+}
+
+// downloadPresigned requests presigned URL of the file and fetches it
+func downloadPresigned(
+	ctx context.Context,
+	ReportsPlaneClient service.ReportsPlaneClient,
+	dst io.Writer,
+	taskId, filename string,
+) *DataExchangeResult {
+	result := GetTaskFilePresignedURLContext(ctx, ReportsPlaneClient, common.DomainPresignedGet, taskId, filename)
+	if result.Error != nil {
+		return result
+	}
+	urls := result.Recv.ObjectsList.GetPresignedUrls()
+	if (len(urls) == 0) || !urls[0].IsIssued() {
+		result.Error = fmt.Errorf("presigned URL is not issued")
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urls[0].GetUrl().GetUrl(), nil)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		result.Error = fmt.Errorf("presigned URL fetch failed with status %s", resp.Status)
+		return result
+	}
+
+	result.Recv.Data.Len, result.Error = io.Copy(dst, resp.Body)
+	return result
+}
@@ -0,0 +1,191 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+)
+
+// RemoteFile provides random access to the object located at the server.
+// Each read issues ranged download of the object's data, thus only requested data are transferred.
+type RemoteFile struct {
+	// ctx bounds all downloads issued by the file, since io.ReaderAt and io.ReadSeeker do not accept context
+	ctx     context.Context
+	client  service.DataPlaneClient
+	request *common.ObjectRequest
+	options *DataExchangeOptions
+
+	// offset is the current offset for Read() and Seek()
+	offset int64
+	// size is the size of the object
+	size int64
+}
+
+// Ensure interface compatibility
+var (
+	_ io.ReaderAt   = &RemoteFile{}
+	_ io.ReadSeeker = &RemoteFile{}
+)
+
+// OpenRemoteFile opens object specified by the request as a RemoteFile.
+func OpenRemoteFile(client service.DataPlaneClient, request *common.ObjectRequest, options *DataExchangeOptions) (*RemoteFile, error) {
+	return OpenRemoteFileContext(context.Background(), client, request, options)
+}
+
+// OpenRemoteFileContext opens object specified by the request as a RemoteFile.
+// Size of the object is requested from the server. The context bounds all reads of the file.
+func OpenRemoteFileContext(ctx context.Context, client service.DataPlaneClient, request *common.ObjectRequest, options *DataExchangeOptions) (*RemoteFile, error) {
+	f := &RemoteFile{
+		ctx:     ctx,
+		client:  client,
+		request: request,
+		options: options,
+	}
+
+	// Empty range provides payload metadata only
+	result := options.GetRetryPolicy().Do(ctx, "OpenRemoteFile", nil, func() *DataExchangeResult {
+		result := NewDataExchangeResult()
+		result.Recv.Data.Metadata, result.Error = statObject(ctx, client, f.rangeRequest(0, 0))
+		return result
+	})
+	if result.Error != nil {
+		log.Warnf("unable to open remote file. err: %v", result.Error)
+		return nil, result.Error
+	}
+	if !result.Recv.Data.Metadata.GetProperties().HasTotal() {
+		return nil, fmt.Errorf("server has not provided size of the object")
+	}
+	f.size = result.Recv.Data.Metadata.GetProperties().GetTotal()
+
+	return f, nil
+}
+
+// statObject gets payload metadata of the object specified by the request, which arrives with the first packet.
+// Download is cancelled as soon as the first packet is received, thus data of the object are not transferred
+// even in case server ignores range of the request.
+func statObject(ctx context.Context, client service.DataPlaneClient, request *common.ObjectRequest) (*common.Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.DownloadObject(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	packet, err := stream.Recv()
+	if err == io.EOF {
+		return nil, fmt.Errorf("server has not provided metadata of the object")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return packet.GetPayloadMetadata(), nil
+}
+
+// rangeRequest builds request of the specified range of the object
+func (f *RemoteFile) rangeRequest(offset, length int64) *common.ObjectRequest {
+	request := common.NewObjectRequest()
+	if f.request != nil {
+		request = proto.Clone(f.request).(*common.ObjectRequest)
+	}
+	return request.SetRange(offset, length)
+}
+
+// Size gets size of the object
+func (f *RemoteFile) Size() int64 {
+	if f == nil {
+		return 0
+	}
+	return f.size
+}
+
+// ReadAt is an io.ReaderAt interface function
+func (f *RemoteFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	length := int64(len(p))
+	if off+length > f.size {
+		length = f.size - off
+	}
+
+	w := &sliceWriter{buf: p[:0:length]}
+	result := DownloadObjectContext(f.ctx, f.client, w, f.rangeRequest(off, length), f.options)
+	n = len(w.buf)
+	if result.Error != nil {
+		return n, result.Error
+	}
+	if n < len(p) {
+		// Less data than requested means the end of the object is reached
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read is an io.Reader interface function
+func (f *RemoteFile) Read(p []byte) (n int, err error) {
+	n, err = f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if (err == io.EOF) && (n > 0) {
+		// EOF will be reported by the next Read()
+		err = nil
+	}
+	return n, err
+}
+
+// Seek is an io.Seeker interface function
+func (f *RemoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position %d", offset)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// sliceWriter is an io.Writer which writes into provided slice and does not grow beyond its capacity
+type sliceWriter struct {
+	buf []byte
+}
+
+// Write is an io.Writer interface function
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	available := cap(w.buf) - len(w.buf)
+	if len(p) > available {
+		w.buf = append(w.buf, p[:available]...)
+		return available, io.ErrShortWrite
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
@@ -0,0 +1,192 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+	controller_service "github.com/sunsingerus/tbox/pkg/controller/service"
+)
+
+// objectDataPlaneClient emulates data plane, which serves one object
+type objectDataPlaneClient struct {
+	service.DataPlaneClient
+	data []byte
+	// ignoreRange specifies whether the whole object is served regardless of the range requested
+	ignoreRange bool
+	// received specifies number of packets received by the client
+	received int
+}
+
+// DownloadObject is a DataPlaneClient interface function
+func (c *objectDataPlaneClient) DownloadObject(ctx context.Context, request *common.ObjectRequest, _ ...grpc.CallOption) (service.DataPlane_DownloadObjectClient, error) {
+	if c.ignoreRange {
+		request = nil
+	}
+	server := &objectDownloadServer{}
+	if err := controller_service.ServeObject(server, request, bytes.NewReader(c.data), int64(len(c.data)), nil); err != nil {
+		return nil, err
+	}
+	return &objectDownloadClient{ctx: ctx, client: c, packets: server.packets}, nil
+}
+
+// objectDownloadServer emulates server side of DownloadObject stream
+type objectDownloadServer struct {
+	grpc.ServerStream
+	packetsWriter
+}
+
+// Context is a grpc.ServerStream interface function
+func (s *objectDownloadServer) Context() context.Context {
+	return context.Background()
+}
+
+// objectDownloadClient emulates client side of DownloadObject stream
+type objectDownloadClient struct {
+	grpc.ClientStream
+	ctx     context.Context
+	client  *objectDataPlaneClient
+	packets []*common.DataPacket
+}
+
+// Context is a grpc.ClientStream interface function
+func (s *objectDownloadClient) Context() context.Context {
+	return s.ctx
+}
+
+// Recv is a DataPlane_DownloadObjectClient interface function
+func (s *objectDownloadClient) Recv() (*common.DataPacket, error) {
+	if len(s.packets) == 0 {
+		return nil, io.EOF
+	}
+	packet := s.packets[0]
+	s.packets = s.packets[1:]
+	s.client.received++
+	return packet, nil
+}
+
+func TestOpenRemoteFile(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 10*1024)
+	for _, ignoreRange := range []bool{false, true} {
+		client := &objectDataPlaneClient{data: data, ignoreRange: ignoreRange}
+		f, err := OpenRemoteFile(client, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Size() != int64(len(data)) {
+			t.Fatalf("ignore range %v: size %d", ignoreRange, f.Size())
+		}
+		// Size is provided by the first packet, the rest of the object is not transferred
+		if client.received != 1 {
+			t.Fatalf("ignore range %v: %d packets received", ignoreRange, client.received)
+		}
+	}
+}
+
+func TestRemoteFileReadAt(t *testing.T) {
+	data := []byte("0123456789")
+	f, err := OpenRemoteFile(&objectDataPlaneClient{data: data}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		len    int
+		// data specifies data expected to be read
+		data string
+		eof  bool
+		fail bool
+	}{
+		{name: "head", offset: 0, len: 4, data: "0123"},
+		{name: "middle", offset: 3, len: 4, data: "3456"},
+		{name: "whole", offset: 0, len: 10, data: "0123456789"},
+		{name: "past EOF", offset: 8, len: 4, data: "89", eof: true},
+		{name: "at EOF", offset: 10, len: 4, eof: true},
+		{name: "beyond EOF", offset: 20, len: 4, eof: true},
+		{name: "zero len", offset: 5, len: 0},
+		{name: "negative offset", offset: -1, len: 4, fail: true},
+	}
+	for _, test := range tests {
+		p := make([]byte, test.len)
+		n, err := f.ReadAt(p, test.offset)
+		if test.fail {
+			if (err == nil) || (err == io.EOF) {
+				t.Fatalf("%s: unexpected err: %v", test.name, err)
+			}
+			continue
+		}
+		if (err == io.EOF) != test.eof || ((err != nil) && (err != io.EOF)) {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if string(p[:n]) != test.data {
+			t.Fatalf("%s: read %q", test.name, p[:n])
+		}
+	}
+}
+
+func TestRemoteFileSeek(t *testing.T) {
+	data := []byte("0123456789")
+	f, err := OpenRemoteFile(&objectDataPlaneClient{data: data}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each seek is followed by read of 2 bytes, thus position moves on
+	tests := []struct {
+		name     string
+		offset   int64
+		whence   int
+		position int64
+		data     string
+		fail     bool
+	}{
+		{name: "start", offset: 2, whence: io.SeekStart, position: 2, data: "23"},
+		{name: "current", offset: 1, whence: io.SeekCurrent, position: 5, data: "56"},
+		{name: "current back", offset: -7, whence: io.SeekCurrent, position: 0, data: "01"},
+		{name: "end", offset: -1, whence: io.SeekEnd, position: 9, data: "9"},
+		{name: "past end", offset: 5, whence: io.SeekEnd, position: 15},
+		{name: "negative", offset: -11, whence: io.SeekEnd, fail: true},
+		{name: "invalid whence", offset: 0, whence: 3, fail: true},
+	}
+	for _, test := range tests {
+		position, err := f.Seek(test.offset, test.whence)
+		if (err != nil) != test.fail {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if test.fail {
+			continue
+		}
+		if position != test.position {
+			t.Fatalf("%s: position %d", test.name, position)
+		}
+		p := make([]byte, 2)
+		n, err := f.Read(p)
+		if (err != nil) && (err != io.EOF) {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if string(p[:n]) != test.data {
+			t.Fatalf("%s: read %q", test.name, p[:n])
+		}
+	}
+}
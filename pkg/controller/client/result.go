@@ -30,6 +30,8 @@ type DataExchangeResult struct {
 	Send struct {
 		Data struct {
 			Len int64
+			// Digest of the whole stream sent. Optional
+			Digest *common.Digest
 		}
 	}
 
@@ -0,0 +1,388 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRetryMaxAttempts    = 5
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+	defaultRetryMultiplier     = 2.0
+	defaultRetryJitter         = 0.2
+)
+
+// ErrRetryNotRewindable specifies the situation when failed call can not be retried,
+// because data already transferred can not be transferred again
+var ErrRetryNotRewindable = fmt.Errorf("unable to retry, transferred data can not be rewound")
+
+// RetryPolicy specifies how failed data plane calls are retried.
+// Delay between attempts grows exponentially from initial backoff up to max backoff and is randomized by jitter.
+// gRPC connection reconnects on its own, thus the call is re-issued on the same connection after the delay.
+// Data of the source are re-sent from the beginning: seekable source is rewound, non-seekable source is replayed
+// from the buffer, in case it fits into the buffer. Otherwise the call fails with ErrRetryNotRewindable.
+type RetryPolicy struct {
+	// maxAttempts specifies max number of attempts, including the first one
+	maxAttempts int
+	// initialBackoff specifies delay before the first retry
+	initialBackoff time.Duration
+	// maxBackoff specifies max delay between attempts
+	maxBackoff time.Duration
+	// multiplier specifies how delay grows after each attempt
+	multiplier float64
+	// jitter specifies fraction of the delay to be randomized, in [0, 1]
+	jitter float64
+	// codes specifies gRPC status codes to be retried
+	codes []codes.Code
+	// bufferSize specifies max number of bytes of non-seekable source to be buffered for re-send
+	bufferSize int
+}
+
+// NewRetryPolicy creates new RetryPolicy with defaults: 5 attempts, backoff from 100ms up to 10s,
+// Unavailable status code is retried, non-seekable sources are not buffered
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		maxAttempts:    defaultRetryMaxAttempts,
+		initialBackoff: defaultRetryInitialBackoff,
+		maxBackoff:     defaultRetryMaxBackoff,
+		multiplier:     defaultRetryMultiplier,
+		jitter:         defaultRetryJitter,
+		codes:          []codes.Code{codes.Unavailable},
+	}
+}
+
+// SetMaxAttempts sets max number of attempts, including the first one
+func (p *RetryPolicy) SetMaxAttempts(attempts int) *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	p.maxAttempts = attempts
+	return p
+}
+
+// GetMaxAttempts gets max number of attempts, including the first one
+func (p *RetryPolicy) GetMaxAttempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.maxAttempts < 1 {
+		return 1
+	}
+	return p.maxAttempts
+}
+
+// SetBackoff sets initial and max delay between attempts
+func (p *RetryPolicy) SetBackoff(initial, max time.Duration) *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	p.initialBackoff = initial
+	p.maxBackoff = max
+	return p
+}
+
+// SetMultiplier sets how delay grows after each attempt
+func (p *RetryPolicy) SetMultiplier(multiplier float64) *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	p.multiplier = multiplier
+	return p
+}
+
+// SetJitter sets fraction of the delay to be randomized, in [0, 1]
+func (p *RetryPolicy) SetJitter(jitter float64) *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	p.jitter = math.Max(0, math.Min(1, jitter))
+	return p
+}
+
+// SetCodes sets gRPC status codes to be retried
+func (p *RetryPolicy) SetCodes(codes ...codes.Code) *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	p.codes = codes
+	return p
+}
+
+// GetCodes gets gRPC status codes to be retried
+func (p *RetryPolicy) GetCodes() []codes.Code {
+	if p == nil {
+		return nil
+	}
+	return p.codes
+}
+
+// SetBufferSize sets max number of bytes of non-seekable source to be buffered for re-send
+func (p *RetryPolicy) SetBufferSize(size int) *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	p.bufferSize = size
+	return p
+}
+
+// GetBufferSize gets max number of bytes of non-seekable source to be buffered for re-send
+func (p *RetryPolicy) GetBufferSize() int {
+	if p == nil {
+		return 0
+	}
+	return p.bufferSize
+}
+
+// GetBackoff gets delay after the specified failed attempt, attempts are counted from 1
+func (p *RetryPolicy) GetBackoff(attempt int) time.Duration {
+	if p == nil {
+		return 0
+	}
+	backoff := float64(p.initialBackoff) * math.Pow(math.Max(p.multiplier, 1), float64(attempt-1))
+	if p.maxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.maxBackoff))
+	}
+	// Randomize delay within [backoff*(1-jitter), backoff*(1+jitter)], so clients do not retry all at once
+	backoff *= 1 + p.jitter*(2*rand.Float64()-1)
+	return time.Duration(backoff)
+}
+
+// IsRetryable checks whether call failed with the error can be retried
+func (p *RetryPolicy) IsRetryable(err error) bool {
+	if p == nil || err == nil {
+		return false
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		// Not a gRPC error, such as failure to read source data
+		return false
+	}
+	for _, code := range p.codes {
+		if s.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Do calls the call till it succeeds, fails with non-retryable error, attempts are exhausted or the context is done.
+// Rewind is called before each retry to prepare data to be transferred again, nil means nothing to rewind.
+// Errors of all failed attempts are collected in result.Errors.
+func (p *RetryPolicy) Do(
+	ctx context.Context,
+	name string,
+	rewind func() error,
+	call func() *DataExchangeResult,
+) *DataExchangeResult {
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		result := call()
+		if result.Error == nil {
+			result.Errors = append(errs, result.Errors...)
+			return result
+		}
+		errs = append(errs, result.Error)
+		result.Errors = errs
+
+		if (attempt >= p.GetMaxAttempts()) || !p.IsRetryable(result.Error) {
+			return result
+		}
+		if rewind != nil {
+			if err := rewind(); err != nil {
+				log.Warnf("%s() failed on attempt %d and can not be retried. err: %v", name, attempt, err)
+				result.Error = err
+				return result
+			}
+		}
+
+		backoff := p.GetBackoff(attempt)
+		log.Warnf("%s() failed on attempt %d of %d, retry in %v. err: %v", name, attempt, p.GetMaxAttempts(), backoff, result.Error)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result
+		case <-timer.C:
+		}
+	}
+}
+
+// retrySource provides data of the source for each attempt of the call.
+// Seekable source is rewound to its initial position, non-seekable source is buffered up to the limit.
+type retrySource struct {
+	src io.Reader
+	// seeker is set in case the source is seekable, start is the initial position of the source
+	seeker io.Seeker
+	start  int64
+	// buf keeps data read from non-seekable source, pos is the position of the replay within buf
+	buf   []byte
+	pos   int
+	limit int
+	// overflow specifies whether data read from non-seekable source do not fit into the buffer
+	overflow bool
+	// read specifies whether any data were read from the source
+	read bool
+	// retry specifies whether the call is retried at all, otherwise the source is used as is
+	retry bool
+}
+
+// newRetrySource creates new retrySource. Returns nil in case of nil source
+func newRetrySource(src io.Reader, policy *RetryPolicy) *retrySource {
+	if src == nil {
+		return nil
+	}
+	s := &retrySource{
+		src:   src,
+		limit: policy.GetBufferSize(),
+		retry: policy.GetMaxAttempts() > 1,
+	}
+	if seeker, ok := src.(io.Seeker); ok {
+		// Seekable interface does not guarantee ability to seek, such as stdin is not seekable in case of a pipe
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			s.seeker = seeker
+			s.start = start
+		}
+	}
+	return s
+}
+
+// Reader gets reader of the source. Returns untyped nil in case of nil source
+func (s *retrySource) Reader() io.Reader {
+	if s == nil {
+		return nil
+	}
+	if !s.retry {
+		return s.src
+	}
+	return s
+}
+
+// Read is an io.Reader interface function
+func (s *retrySource) Read(p []byte) (int, error) {
+	if s.pos < len(s.buf) {
+		// Replay buffered data
+		n := copy(p, s.buf[s.pos:])
+		s.pos += n
+		return n, nil
+	}
+
+	n, err := s.src.Read(p)
+	if n > 0 {
+		s.read = true
+	}
+	if (s.seeker == nil) && !s.overflow {
+		if len(s.buf)+n > s.limit {
+			s.overflow = true
+			s.buf = nil
+		} else {
+			s.buf = append(s.buf, p[:n]...)
+		}
+		s.pos = len(s.buf)
+	}
+	return n, err
+}
+
+// Rewind prepares the source to be read from the beginning
+func (s *retrySource) Rewind() error {
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.seeker != nil:
+		_, err := s.seeker.Seek(s.start, io.SeekStart)
+		return err
+	case !s.read:
+		// Nothing is consumed, nothing to rewind
+		return nil
+	case s.overflow:
+		return ErrRetryNotRewindable
+	}
+	s.pos = 0
+	return nil
+}
+
+// retryWriter counts data written into the destination, so failed download can be retried
+// only in case nothing was written into the destination
+type retryWriter struct {
+	dst     io.Writer
+	written int64
+	// retry specifies whether the call is retried at all, otherwise the destination is used as is
+	retry bool
+}
+
+// newRetryWriter creates new retryWriter. Returns nil in case of nil destination
+func newRetryWriter(dst io.Writer, policy *RetryPolicy) *retryWriter {
+	if dst == nil {
+		return nil
+	}
+	return &retryWriter{
+		dst:   dst,
+		retry: policy.GetMaxAttempts() > 1,
+	}
+}
+
+// Writer gets writer of the destination. Returns untyped nil in case of nil destination
+func (w *retryWriter) Writer() io.Writer {
+	if w == nil {
+		return nil
+	}
+	if !w.retry {
+		return w.dst
+	}
+	return w
+}
+
+// Write is an io.Writer interface function
+func (w *retryWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Rewind checks whether download can be retried
+func (w *retryWriter) Rewind() error {
+	if w == nil {
+		return nil
+	}
+	if w.written > 0 {
+		return ErrRetryNotRewindable
+	}
+	return nil
+}
+
+// getStreamError gets the actual error of the client stream in case send failed.
+// gRPC reports failed stream as io.EOF on send, while the status of the stream is provided on receive only.
+func getStreamError(stream grpc.ClientStream, reply interface{}, err error) error {
+	if !errors.Is(err, io.EOF) {
+		return err
+	}
+	if e := stream.RecvMsg(reply); (e != nil) && (e != io.EOF) {
+		return e
+	}
+	return err
+}
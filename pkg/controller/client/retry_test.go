@@ -0,0 +1,310 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+)
+
+var errUnavailable = status.Error(codes.Unavailable, "unavailable")
+
+// retryDataPlaneClient emulates data plane, which breaks streams after the specified number of packets
+type retryDataPlaneClient struct {
+	service.DataPlaneClient
+	// breaks specifies after how many packets stream of each attempt breaks. Attempts beyond breaks succeed
+	breaks []int
+	// attempts specifies number of streams opened
+	attempts int
+	// uploaded keeps data received by the stream of the last upload attempt
+	uploaded []byte
+	// packets are sent by download streams
+	packets []*common.DataPacket
+}
+
+// getBreak gets after how many packets stream of the next attempt breaks. Negative means stream does not break
+func (c *retryDataPlaneClient) getBreak() int {
+	c.attempts++
+	if c.attempts <= len(c.breaks) {
+		return c.breaks[c.attempts-1]
+	}
+	return -1
+}
+
+// UploadObject is a DataPlaneClient interface function
+func (c *retryDataPlaneClient) UploadObject(ctx context.Context, _ ...grpc.CallOption) (service.DataPlane_UploadObjectClient, error) {
+	c.uploaded = nil
+	return &retryUploadObjectClient{ctx: ctx, client: c, breaks: c.getBreak()}, nil
+}
+
+// DownloadObject is a DataPlaneClient interface function
+func (c *retryDataPlaneClient) DownloadObject(ctx context.Context, _ *common.ObjectRequest, _ ...grpc.CallOption) (service.DataPlane_DownloadObjectClient, error) {
+	breaks := c.getBreak()
+	if breaks == 0 {
+		return nil, errUnavailable
+	}
+	return &retryDownloadObjectClient{ctx: ctx, packets: c.packets, breaks: breaks}, nil
+}
+
+// retryUploadObjectClient emulates client side of UploadObject stream
+type retryUploadObjectClient struct {
+	grpc.ClientStream
+	ctx    context.Context
+	client *retryDataPlaneClient
+	breaks int
+	sent   int
+}
+
+// Context is a grpc.ClientStream interface function
+func (s *retryUploadObjectClient) Context() context.Context {
+	return s.ctx
+}
+
+// Send is a DataPlane_UploadObjectClient interface function. Broken stream reports io.EOF, the same as gRPC does
+func (s *retryUploadObjectClient) Send(packet *common.DataPacket) error {
+	if s.sent == s.breaks {
+		return io.EOF
+	}
+	s.sent++
+	s.client.uploaded = append(s.client.uploaded, packet.GetData()...)
+	return nil
+}
+
+// RecvMsg is a grpc.ClientStream interface function, which provides status of the broken stream
+func (s *retryUploadObjectClient) RecvMsg(interface{}) error {
+	return errUnavailable
+}
+
+// CloseAndRecv is a DataPlane_UploadObjectClient interface function
+func (s *retryUploadObjectClient) CloseAndRecv() (*common.ObjectStatus, error) {
+	if s.sent == s.breaks {
+		return nil, errUnavailable
+	}
+	return common.NewObjectStatus(), nil
+}
+
+// retryDownloadObjectClient emulates client side of DownloadObject stream
+type retryDownloadObjectClient struct {
+	grpc.ClientStream
+	ctx     context.Context
+	packets []*common.DataPacket
+	breaks  int
+	sent    int
+}
+
+// Context is a grpc.ClientStream interface function
+func (s *retryDownloadObjectClient) Context() context.Context {
+	return s.ctx
+}
+
+// Recv is a DataPlane_DownloadObjectClient interface function
+func (s *retryDownloadObjectClient) Recv() (*common.DataPacket, error) {
+	if s.sent == s.breaks {
+		return nil, errUnavailable
+	}
+	if s.sent == len(s.packets) {
+		return nil, io.EOF
+	}
+	s.sent++
+	return proto.Clone(s.packets[s.sent-1]).(*common.DataPacket), nil
+}
+
+// packetsWriter keeps packets sent
+type packetsWriter struct {
+	packets []*common.DataPacket
+}
+
+// Send is a DataPacketWriter interface function
+func (w *packetsWriter) Send(packet *common.DataPacket) error {
+	w.packets = append(w.packets, proto.Clone(packet).(*common.DataPacket))
+	return nil
+}
+
+// nonSeekableReader hides io.Seeker of the reader
+type nonSeekableReader struct {
+	io.Reader
+}
+
+// newRetryPolicy creates retry policy, which retries with no delay
+func newRetryPolicy(attempts int) *RetryPolicy {
+	return NewRetryPolicy().SetMaxAttempts(attempts).SetBackoff(time.Millisecond, time.Millisecond)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := NewRetryPolicy().SetBackoff(100*time.Millisecond, time.Second).SetMultiplier(2).SetJitter(0.2)
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{attempt: 1, backoff: 100 * time.Millisecond},
+		{attempt: 2, backoff: 200 * time.Millisecond},
+		{attempt: 4, backoff: 800 * time.Millisecond},
+		{attempt: 5, backoff: time.Second},
+		{attempt: 50, backoff: time.Second},
+	}
+	for _, test := range tests {
+		min, max := test.backoff*8/10, test.backoff*12/10
+		for i := 0; i < 100; i++ {
+			if backoff := policy.GetBackoff(test.attempt); (backoff < min) || (backoff > max) {
+				t.Fatalf("attempt %d: backoff %v is out of [%v, %v]", test.attempt, backoff, min, max)
+			}
+		}
+	}
+
+	// No jitter means exact backoff
+	if backoff := policy.SetJitter(0).GetBackoff(3); backoff != 400*time.Millisecond {
+		t.Fatalf("unexpected backoff %v", backoff)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	errRead := fmt.Errorf("read failed")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		policy *RetryPolicy
+		// errs specifies errors of the attempts, attempts beyond errs succeed
+		errs   []error
+		rewind error
+		calls  int
+		err    error
+	}{
+		{name: "success", ctx: context.Background(), policy: newRetryPolicy(3), calls: 1},
+		{name: "retried", ctx: context.Background(), policy: newRetryPolicy(3), errs: []error{errUnavailable, errUnavailable}, calls: 3},
+		{name: "max attempts", ctx: context.Background(), policy: newRetryPolicy(3), errs: []error{errUnavailable, errUnavailable, errUnavailable, errUnavailable}, calls: 3, err: errUnavailable},
+		{name: "no policy", ctx: context.Background(), policy: nil, errs: []error{errUnavailable}, calls: 1, err: errUnavailable},
+		{name: "non-retryable code", ctx: context.Background(), policy: newRetryPolicy(3), errs: []error{status.Error(codes.InvalidArgument, "invalid")}, calls: 1, err: status.Error(codes.InvalidArgument, "invalid")},
+		{name: "non-gRPC error", ctx: context.Background(), policy: newRetryPolicy(3), errs: []error{errRead}, calls: 1, err: errRead},
+		{name: "retryable code", ctx: context.Background(), policy: newRetryPolicy(3).SetCodes(codes.Aborted), errs: []error{status.Error(codes.Aborted, "aborted")}, calls: 2},
+		{name: "not rewindable", ctx: context.Background(), policy: newRetryPolicy(3), errs: []error{errUnavailable}, rewind: ErrRetryNotRewindable, calls: 1, err: ErrRetryNotRewindable},
+		{name: "context done", ctx: cancelled, policy: NewRetryPolicy().SetBackoff(time.Hour, time.Hour), errs: []error{errUnavailable}, calls: 1, err: errUnavailable},
+	}
+	for _, test := range tests {
+		calls := 0
+		result := test.policy.Do(test.ctx, test.name, func() error { return test.rewind }, func() *DataExchangeResult {
+			calls++
+			if calls <= len(test.errs) {
+				return NewDataExchangeResultError(test.errs[calls-1])
+			}
+			return NewDataExchangeResult()
+		})
+		if calls != test.calls {
+			t.Fatalf("%s: %d calls made", test.name, calls)
+		}
+		if (result.Error == nil) != (test.err == nil) || ((test.err != nil) && (result.Error.Error() != test.err.Error())) {
+			t.Fatalf("%s: unexpected err: %v", test.name, result.Error)
+		}
+		// Errors of all failed attempts are collected
+		failed := len(test.errs)
+		if calls < failed {
+			failed = calls
+		}
+		if len(result.Errors) != failed {
+			t.Fatalf("%s: %d errors collected", test.name, len(result.Errors))
+		}
+	}
+}
+
+func TestRetryUpload(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1024)
+	tests := []struct {
+		name   string
+		src    func() io.Reader
+		policy *RetryPolicy
+		breaks []int
+		// attempts specifies number of streams opened
+		attempts int
+		err      error
+	}{
+		{name: "seekable", src: func() io.Reader { return bytes.NewReader(data) }, policy: newRetryPolicy(3), breaks: []int{5, 1}, attempts: 3},
+		{name: "buffered", src: func() io.Reader { return nonSeekableReader{bytes.NewReader(data)} }, policy: newRetryPolicy(3).SetBufferSize(len(data)), breaks: []int{5}, attempts: 2},
+		{name: "not buffered", src: func() io.Reader { return nonSeekableReader{bytes.NewReader(data)} }, policy: newRetryPolicy(3), breaks: []int{5}, attempts: 1, err: ErrRetryNotRewindable},
+		{name: "exhausted", src: func() io.Reader { return bytes.NewReader(data) }, policy: newRetryPolicy(2), breaks: []int{5, 5}, attempts: 2, err: errUnavailable},
+	}
+	for _, test := range tests {
+		client := &retryDataPlaneClient{breaks: test.breaks}
+		result := UploadContext(context.Background(), client, test.src(), NewDataExchangeOptions().SetChunkSize(1024).SetRetryPolicy(test.policy))
+		if !errors.Is(result.Error, test.err) && ((test.err == nil) || (status.Code(result.Error) != status.Code(test.err))) {
+			t.Fatalf("%s: unexpected err: %v", test.name, result.Error)
+		}
+		if client.attempts != test.attempts {
+			t.Fatalf("%s: %d attempts made", test.name, client.attempts)
+		}
+		// Successful attempt sends the whole data, no bytes are duplicated or lost
+		if (test.err == nil) && !bytes.Equal(client.uploaded, data) {
+			t.Fatalf("%s: %d bytes uploaded instead of %d", test.name, len(client.uploaded), len(data))
+		}
+	}
+}
+
+func TestRetryDownload(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1024)
+	writer := &packetsWriter{}
+	f, err := common.OpenDataPacketFileWOptions(writer, nil, common.NewDataPacketFileOptions().SetChunkSize(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		breaks []int
+		// attempts specifies number of streams opened
+		attempts int
+		// written specifies number of bytes written into destination
+		written int
+		err     error
+	}{
+		{name: "intact", attempts: 1, written: len(data)},
+		{name: "unavailable", breaks: []int{0, 0}, attempts: 3, written: len(data)},
+		// Data already written can not be taken back, thus download broken partway is not retried
+		{name: "broken partway", breaks: []int{3}, attempts: 1, written: 3 * 1024, err: ErrRetryNotRewindable},
+	}
+	for _, test := range tests {
+		client := &retryDataPlaneClient{breaks: test.breaks, packets: writer.packets}
+		dst := &bytes.Buffer{}
+		result := DownloadObjectContext(context.Background(), client, dst, common.NewObjectRequest(), NewDataExchangeOptions().SetRetryPolicy(newRetryPolicy(3)))
+		if !errors.Is(result.Error, test.err) {
+			t.Fatalf("%s: unexpected err: %v", test.name, result.Error)
+		}
+		if client.attempts != test.attempts {
+			t.Fatalf("%s: %d attempts made", test.name, client.attempts)
+		}
+		if !bytes.Equal(dst.Bytes(), data[:test.written]) {
+			t.Fatalf("%s: %d bytes written instead of %d", test.name, dst.Len(), test.written)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package controller_client
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -29,6 +30,11 @@ import (
 
 // SendFile sends file from client to service and receives response back (if any)
 func SendFile(client service.DataPlaneClient, filename string, options *DataExchangeOptions) (int64, error) {
+	return SendFileContext(context.Background(), client, filename, options)
+}
+
+// SendFileContext sends file from client to service and receives response back (if any)
+func SendFileContext(ctx context.Context, client service.DataPlaneClient, filename string, options *DataExchangeOptions) (int64, error) {
 	log.Info("SendFile() - start")
 	defer log.Info("SendFile() - end")
 
@@ -46,25 +52,35 @@ func SendFile(client service.DataPlaneClient, filename string, options *DataExch
 
 	options = options.Ensure()
 	options.EnsureMetadata().SetFilename(filepath.Base(filename))
-	return SendReader(client, f, options)
+	return SendReaderContext(ctx, client, f, options)
 }
 
 // SendStdin sends STDIN from client to service and receives response back (if any)
 func SendStdin(client service.DataPlaneClient, options *DataExchangeOptions) (int64, error) {
+	return SendStdinContext(context.Background(), client, options)
+}
+
+// SendStdinContext sends STDIN from client to service and receives response back (if any)
+func SendStdinContext(ctx context.Context, client service.DataPlaneClient, options *DataExchangeOptions) (int64, error) {
 	log.Info("SendStdin() - start")
 	defer log.Info("SendStdin() - end")
 
 	options = options.Ensure()
 	options.EnsureMetadata().SetFilename(os.Stdin.Name())
-	return SendReader(client, os.Stdin, options)
+	return SendReaderContext(ctx, client, os.Stdin, options)
 }
 
 // SendReader
 func SendReader(client service.DataPlaneClient, r io.Reader, options *DataExchangeOptions) (int64, error) {
+	return SendReaderContext(context.Background(), client, r, options)
+}
+
+// SendReaderContext
+func SendReaderContext(ctx context.Context, client service.DataPlaneClient, r io.Reader, options *DataExchangeOptions) (int64, error) {
 	log.Info("SendReader() - start")
 	defer log.Info("SendReader() - end")
 
-	result := DataExchange(client, r, options)
+	result := DataExchangeContext(ctx, client, r, options)
 	if result.Error == nil {
 		log.Infof("DONE send %s size %d", "io.Reader", result.Send.Data.Len)
 	} else {
@@ -76,10 +92,15 @@ func SendReader(client service.DataPlaneClient, r io.Reader, options *DataExchan
 
 // SendBytes
 func SendBytes(client service.DataPlaneClient, data []byte, options *DataExchangeOptions) (int64, error) {
+	return SendBytesContext(context.Background(), client, data, options)
+}
+
+// SendBytesContext
+func SendBytesContext(ctx context.Context, client service.DataPlaneClient, data []byte, options *DataExchangeOptions) (int64, error) {
 	log.Info("SendBytes() - start")
 	defer log.Info("SendBytes() - end")
 
-	return SendReader(client, bytes.NewReader(data), options)
+	return SendReaderContext(ctx, client, bytes.NewReader(data), options)
 }
 
 // SendEchoRequest
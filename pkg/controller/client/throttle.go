@@ -0,0 +1,48 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_client
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/config/sections"
+)
+
+// SetupThrottle applies bandwidth limits specified in config to the process-wide common.DefaultThrottle,
+// which limits all outgoing streams with no throttle specified explicitly in options.
+// Limits can be adjusted later by TaskThrottle task received via control plane, see HandleThrottleTask.
+// TasksExchange and TasksExchangeContext apply TaskThrottle tasks received on their own.
+func SetupThrottle(config sections.ThrottleConfigurator) *common.Throttle {
+	cfg := config.GetThrottle()
+	log.Infof("SetupThrottle() stream: %d process: %d burst: %d", cfg.GetStream(), cfg.GetProcess(), cfg.GetBurst())
+	return common.DefaultThrottle.SetBandwidthLimit(
+		common.NewBandwidthLimit().
+			SetStream(cfg.GetStream()).
+			SetProcess(cfg.GetProcess()).
+			SetBurst(cfg.GetBurst()),
+	)
+}
+
+// HandleThrottleTask applies bandwidth limits carried by TaskThrottle task to the process-wide common.DefaultThrottle.
+// Streams in progress are affected as well. Is called by TasksExchangeContext for each TaskThrottle task received,
+// thus needs to be called explicitly only in case tasks are received by other means.
+func HandleThrottleTask(task *common.Task) error {
+	if err := common.DefaultThrottle.ApplyTask(task); err != nil {
+		log.Warnf("unable to apply throttle task. err: %v", err)
+		return err
+	}
+	return nil
+}
@@ -17,22 +17,33 @@ package controller_client
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/controller"
 )
 
 // UploadFile sends file from client to service and receives response back (if any)
 func UploadFile(client service.DataPlaneClient, filename string, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadFileContext(context.Background(), client, filename, options)
+}
+
+// UploadFileContext sends file from client to service and receives response back (if any)
+func UploadFileContext(ctx context.Context, client service.DataPlaneClient, filename string, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("UploadFile() - start")
 	defer log.Info("UploadFile() - end")
 
-	if _, err := os.Stat(filename); err != nil {
+	stat, err := os.Stat(filename)
+	if err != nil {
 		log.Warnf("no file %s available err: %v", filename, err)
 		return NewDataExchangeResultError(err)
 	}
@@ -45,23 +56,33 @@ func UploadFile(client service.DataPlaneClient, filename string, options *DataEx
 	}
 	defer f.Close()
 
-	options = options.Ensure()
+	options = options.Ensure().Clone().SetProgressTotal(stat.Size())
 	options.EnsureMetadata().SetFilename(filepath.Base(filename))
-	return UploadReader(client, f, options)
+	return UploadReaderContext(ctx, client, f, options)
 }
 
 // UploadStdin sends STDIN from client to service and receives response back (if any)
 func UploadStdin(client service.DataPlaneClient, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadStdinContext(context.Background(), client, options)
+}
+
+// UploadStdinContext sends STDIN from client to service and receives response back (if any)
+func UploadStdinContext(ctx context.Context, client service.DataPlaneClient, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("UploadStdin() - start")
 	defer log.Info("UploadStdin() - end")
 
 	options = options.Ensure()
 	options.EnsureMetadata().SetFilename(os.Stdin.Name())
-	return UploadReader(client, os.Stdin, options)
+	return UploadReaderContext(ctx, client, os.Stdin, options)
 }
 
 // UploadStdinLog sends STDIN in time chunks from client to service and receives response back (if any)
 func UploadStdinLog(client service.DataPlaneClient, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadStdinLogContext(context.Background(), client, options)
+}
+
+// UploadStdinLogContext sends STDIN in time chunks from client to service and receives response back (if any)
+func UploadStdinLogContext(ctx context.Context, client service.DataPlaneClient, options *DataExchangeOptions) *DataExchangeResult {
 	type ev struct {
 		str string
 		eof bool
@@ -93,6 +114,10 @@ MAIN:
 	for {
 		select {
 
+		case <-ctx.Done():
+			// Data collected so far are not sent, since the context is done
+			return NewDataExchangeResultError(ctx.Err())
+
 		case s := <-stdin:
 			if s.eof {
 				break MAIN
@@ -104,18 +129,18 @@ MAIN:
 
 		case <-timer.C:
 			if toSend.Len() > 0 {
-				_ = UploadReader(client, toSend, options)
+				_ = UploadReaderContext(ctx, client, toSend, options)
 				toSend = bytes.NewBuffer(nil)
 			}
 
 		case <-ticker.C:
 			if toSend.Len() > 0 {
-				_ = UploadReader(client, toSend, options)
+				_ = UploadReaderContext(ctx, client, toSend, options)
 				toSend = bytes.NewBuffer(nil)
 			}
 
 		case <-sizeSignal:
-			_ = UploadReader(client, toSend, options)
+			_ = UploadReaderContext(ctx, client, toSend, options)
 			toSend = bytes.NewBuffer(nil)
 
 		}
@@ -127,23 +152,33 @@ MAIN:
 		return &DataExchangeResult{}
 	}
 
-	return UploadReader(client, toSend, options)
+	return UploadReaderContext(ctx, client, toSend, options)
 }
 
 // UploadBytes
 func UploadBytes(client service.DataPlaneClient, data []byte, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadBytesContext(context.Background(), client, data, options)
+}
+
+// UploadBytesContext
+func UploadBytesContext(ctx context.Context, client service.DataPlaneClient, data []byte, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("UploadBytes() - start")
 	defer log.Info("UploadBytes() - end")
 
-	return UploadReader(client, bytes.NewReader(data), options)
+	return UploadReaderContext(ctx, client, bytes.NewReader(data), options)
 }
 
 // UploadReader
 func UploadReader(client service.DataPlaneClient, r io.Reader, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadReaderContext(context.Background(), client, r, options)
+}
+
+// UploadReaderContext
+func UploadReaderContext(ctx context.Context, client service.DataPlaneClient, r io.Reader, options *DataExchangeOptions) *DataExchangeResult {
 	log.Info("UploadReader() - start")
 	defer log.Info("UploadReader() - end")
 
-	result := Upload(client, r, options)
+	result := UploadContext(ctx, client, r, options)
 	if result.Error == nil {
 		log.Infof("DONE send %s size %d", "io.Reader", result.Send.Data.Len)
 	} else {
@@ -152,3 +187,486 @@ func UploadReader(client service.DataPlaneClient, r io.Reader, options *DataExch
 
 	return result
 }
+
+// UploadFiles sends multiple files from client to service via one stream and receives back statuses of the files.
+func UploadFiles(client service.DataPlaneClient, options *DataExchangeOptions, filenames ...string) *DataExchangeResult {
+	return UploadFilesContext(context.Background(), client, options, filenames...)
+}
+
+// UploadFilesContext sends multiple files from client to service via one stream and receives back statuses of the files.
+// Options are applied to each file, filename is set in metadata of each file individually.
+func UploadFilesContext(ctx context.Context, client service.DataPlaneClient, options *DataExchangeOptions, filenames ...string) *DataExchangeResult {
+	log.Info("UploadFiles() - start")
+	defer log.Info("UploadFiles() - end")
+
+	var srcs []io.Reader
+	var srcsOptions []*DataExchangeOptions
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			log.Warnf("ERROR open file %s err: %v", filename, err)
+			return NewDataExchangeResultError(err)
+		}
+		defer f.Close()
+		stat, err := f.Stat()
+		if err != nil {
+			log.Warnf("ERROR stat file %s err: %v", filename, err)
+			return NewDataExchangeResultError(err)
+		}
+
+		fileOptions := options.Ensure().Clone().SetProgressTotal(stat.Size())
+		fileOptions.EnsureMetadata().SetFilename(filepath.Base(filename))
+		srcs = append(srcs, f)
+		srcsOptions = append(srcsOptions, fileOptions)
+	}
+
+	result := UploadObjectsContext(ctx, client, srcs, srcsOptions)
+	if result.Error == nil {
+		log.Infof("DONE send %d files size %d", len(filenames), result.Send.Data.Len)
+	} else {
+		log.Warnf("FAILED send %d files size %d err %v", len(filenames), result.Send.Data.Len, result.Error)
+	}
+
+	return result
+}
+
+// UploadDir sends directory tree from client to service via one stream and receives back statuses of the entries.
+func UploadDir(client service.DataPlaneClient, dir string, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadDirContext(context.Background(), client, dir, options)
+}
+
+// UploadDirContext sends directory tree from client to service via one stream and receives back statuses of the entries.
+// Each directory and regular file is sent as a separate object with its relative path, permissions and
+// modification time specified in metadata. Options are applied to each entry.
+func UploadDirContext(ctx context.Context, client service.DataPlaneClient, dir string, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("UploadDir() - start")
+	defer log.Info("UploadDir() - end")
+
+	var srcs []io.Reader
+	var srcsOptions []*DataExchangeOptions
+	err := controller.WalkDirTree(dir, func(entry *controller.DirTreeEntry) error {
+		entryOptions := options.Ensure().Clone()
+		if !entry.IsDir() {
+			entryOptions.SetProgressTotal(entry.Info.Size())
+		}
+		entry.SetMetadata(entryOptions.EnsureMetadata())
+		srcs = append(srcs, entry.Reader())
+		srcsOptions = append(srcsOptions, entryOptions)
+		return nil
+	})
+	if err != nil {
+		log.Warnf("ERROR walk dir %s err: %v", dir, err)
+		return NewDataExchangeResultError(err)
+	}
+
+	result := UploadObjectsContext(ctx, client, srcs, srcsOptions)
+	if result.Error == nil {
+		log.Infof("DONE send dir %s with %d entries size %d", dir, len(srcs), result.Send.Data.Len)
+	} else {
+		log.Warnf("FAILED send dir %s with %d entries size %d err %v", dir, len(srcs), result.Send.Data.Len, result.Error)
+	}
+
+	return result
+}
+
+// UploadFileResumable sends file from client to service as resumable upload identified by uploadID.
+func UploadFileResumable(client service.DataPlaneClient, filename string, uploadID *common.UUID, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadFileResumableContext(context.Background(), client, filename, uploadID, options)
+}
+
+// UploadFileResumableContext sends file from client to service as resumable upload identified by uploadID.
+// In case upload with the same uploadID was interrupted, it is continued from the offset committed by the service.
+func UploadFileResumableContext(ctx context.Context, client service.DataPlaneClient, filename string, uploadID *common.UUID, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("UploadFileResumable() - start")
+	defer log.Info("UploadFileResumable() - end")
+
+	f, err := os.Open(filename)
+	if err != nil {
+		log.Warnf("ERROR open file %s err: %v", filename, err)
+		return NewDataExchangeResultError(err)
+	}
+	defer f.Close()
+
+	options = options.Ensure()
+	options.EnsureMetadata().SetFilename(filepath.Base(filename))
+	return UploadReaderResumableContext(ctx, client, f, uploadID, options)
+}
+
+// UploadReaderResumable sends io.ReadSeeker from client to service as resumable upload identified by uploadID.
+func UploadReaderResumable(client service.DataPlaneClient, r io.ReadSeeker, uploadID *common.UUID, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadReaderResumableContext(context.Background(), client, r, uploadID, options)
+}
+
+// UploadReaderResumableContext sends io.ReadSeeker from client to service as resumable upload identified by uploadID.
+// Service is asked for the offset committed so far and data are sent starting from this offset.
+func UploadReaderResumableContext(ctx context.Context, client service.DataPlaneClient, r io.ReadSeeker, uploadID *common.UUID, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("UploadReaderResumable() - start")
+	defer log.Info("UploadReaderResumable() - end")
+
+	// Each attempt asks service for the committed offset and continues from it, thus there is nothing to rewind.
+	// Retries are made on the whole resumable upload only, not on the upload of the remaining data.
+	return options.GetRetryPolicy().Do(ctx, "UploadReaderResumable", nil, func() *DataExchangeResult {
+		return uploadReaderResumable(ctx, client, r, uploadID, options.Clone().SetRetryPolicy(nil))
+	})
+}
+
+// uploadReaderResumable makes one attempt to send io.ReadSeeker from client to service as resumable upload
+func uploadReaderResumable(ctx context.Context, client service.DataPlaneClient, r io.ReadSeeker, uploadID *common.UUID, options *DataExchangeOptions) *DataExchangeResult {
+	if uploadID == nil {
+		return NewDataExchangeResultError(fmt.Errorf("upload ID is not specified"))
+	}
+
+	options = options.Ensure()
+	if options.GetDataPacketFileOptions().GetWriteCompression() != common.CompressionNone {
+		// Offsets of compressed stream do not correspond to offsets of the source
+		return NewDataExchangeResultError(fmt.Errorf("resumable upload does not support compression"))
+	}
+	if options.GetEncryptionKeyID() != "" {
+		// Encrypted stream can not be continued by another stream
+		return NewDataExchangeResultError(fmt.Errorf("resumable upload does not support encryption"))
+	}
+
+	status := GetUploadStatusContext(ctx, client, uploadID)
+	if status.Error != nil {
+		return status
+	}
+	offset := status.Recv.ObjectStatus.GetProperties().GetOffset()
+	if status.Recv.ObjectStatus.GetStatus().Equals(common.StatusCreated) {
+		log.Infof("upload %s is already completed", uploadID)
+		return status
+	}
+
+	total, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return NewDataExchangeResultError(err)
+	}
+	if offset > total {
+		return NewDataExchangeResultError(fmt.Errorf("committed offset %d is beyond size %d", offset, total))
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return NewDataExchangeResultError(err)
+	}
+	log.Infof("upload %s continues from offset %d of %d", uploadID, offset, total)
+
+	options.EnsureMetadata().SetUploadUUID(uploadID).EnsureProperties().SetTotal(total)
+	options.SetOffset(offset)
+	return UploadReaderContext(ctx, client, r, options)
+}
+
+// UploadFileDedup sends file from client to service as deduplicated upload.
+func UploadFileDedup(client service.DataPlaneClient, filename string, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadFileDedupContext(context.Background(), client, filename, options)
+}
+
+// UploadFileDedupContext sends file from client to service as deduplicated upload.
+// Data of the file are not transferred in case service already has data with the same digest.
+func UploadFileDedupContext(ctx context.Context, client service.DataPlaneClient, filename string, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("UploadFileDedup() - start")
+	defer log.Info("UploadFileDedup() - end")
+
+	f, err := os.Open(filename)
+	if err != nil {
+		log.Warnf("ERROR open file %s err: %v", filename, err)
+		return NewDataExchangeResultError(err)
+	}
+	defer f.Close()
+
+	options = options.Ensure()
+	options.EnsureMetadata().SetFilename(filepath.Base(filename))
+	return UploadReaderDedupContext(ctx, client, f, options)
+}
+
+// UploadReaderDedup sends io.ReadSeeker from client to service as deduplicated upload.
+func UploadReaderDedup(client service.DataPlaneClient, r io.ReadSeeker, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadReaderDedupContext(context.Background(), client, r, options)
+}
+
+// UploadReaderDedupContext sends io.ReadSeeker from client to service as deduplicated upload.
+// SHA256 digest of the data is calculated and service is asked to link data with the same digest to the object.
+// Data are uploaded only in case service does not have them yet. Object status is provided in the result.
+func UploadReaderDedupContext(ctx context.Context, client service.DataPlaneClient, r io.ReadSeeker, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("UploadReaderDedup() - start")
+	defer log.Info("UploadReaderDedup() - end")
+
+	options = options.Clone().Ensure()
+	if options.GetEncryptionKeyID() != "" {
+		// Service is not able to calculate digest of the encrypted data
+		return NewDataExchangeResultError(fmt.Errorf("deduplicated upload does not support encryption"))
+	}
+
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return NewDataExchangeResultError(err)
+	}
+	digest := common.NewDigest().SetType(common.DigestType_DIGEST_SHA256)
+	size, err := digest.CalculateReader(r)
+	if err != nil {
+		return NewDataExchangeResultError(err)
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return NewDataExchangeResultError(err)
+	}
+	log.Infof("data of %d bytes has digest %s", size, digest)
+
+	options.EnsureMetadata().EnsureProperties().SetDigest(digest).SetLen(size).SetTotal(size)
+	link := LinkObjectContext(ctx, client, options.GetMetadata())
+	switch {
+	case link.Error != nil:
+		if ctx.Err() != nil {
+			return link
+		}
+		// Deduplication is an optimization only, data are uploaded in case service is not able to deduplicate them
+		log.Warnf("unable to link data with digest %s, upload them. err: %v", digest, link.Error)
+	case link.Recv.ObjectStatus.GetStatus().Equals(common.StatusCreated):
+		log.Infof("data with digest %s are linked by the service, upload skipped", digest)
+		link.Send.Data.Digest = digest
+		return link
+	}
+
+	// Service verifies data against the stream digest
+	options.SetDigest(common.DigestType_DIGEST_SHA256).SetProgressTotal(size)
+	return UploadReaderContext(ctx, client, r, options)
+}
+
+const (
+	// MinUploadPartSize specifies min size of the part of the parallel upload.
+	// Object storages, such as S3 and MinIO, are not able to compose objects out of smaller parts.
+	MinUploadPartSize = 5 * 1024 * 1024
+	// defaultPartRetries specifies how many times failed part of the parallel upload is retried by default
+	defaultPartRetries = 3
+)
+
+// UploadFileParallel sends file from client to service as parallel upload identified by uploadID.
+func UploadFileParallel(client service.DataPlaneClient, filename string, uploadID *common.UUID, parts int, options *DataExchangeOptions) *DataExchangeResult {
+	return UploadFileParallelContext(context.Background(), client, filename, uploadID, parts, options)
+}
+
+// UploadFileParallelContext sends file from client to service as parallel upload identified by uploadID.
+// File is split into specified number of parts, which are sent concurrently, each one on a separate stream.
+func UploadFileParallelContext(ctx context.Context, client service.DataPlaneClient, filename string, uploadID *common.UUID, parts int, options *DataExchangeOptions) *DataExchangeResult {
+	log.Info("UploadFileParallel() - start")
+	defer log.Info("UploadFileParallel() - end")
+
+	f, err := os.Open(filename)
+	if err != nil {
+		log.Warnf("ERROR open file %s err: %v", filename, err)
+		return NewDataExchangeResultError(err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		log.Warnf("ERROR stat file %s err: %v", filename, err)
+		return NewDataExchangeResultError(err)
+	}
+
+	options = options.Ensure()
+	options.EnsureMetadata().SetFilename(filepath.Base(filename))
+	return UploadReaderAtParallelContext(ctx, client, f, stat.Size(), uploadID, parts, options)
+}
+
+// UploadReaderAtParallel sends data of io.ReaderAt of the specified size from client to service as parallel upload
+func UploadReaderAtParallel(
+	client service.DataPlaneClient,
+	r io.ReaderAt,
+	size int64,
+	uploadID *common.UUID,
+	parts int,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	return UploadReaderAtParallelContext(context.Background(), client, r, size, uploadID, parts, options)
+}
+
+// UploadReaderAtParallelContext sends data of io.ReaderAt of the specified size from client to service as parallel upload
+// identified by uploadID. Data are split into specified number of parts, which are sent concurrently, each one on
+// a separate stream. Each part is tagged with uploadID and its offset, len and total size of the object,
+// thus service is able to assemble the object out of the parts. Failed part is retried individually.
+func UploadReaderAtParallelContext(
+	ctx context.Context,
+	client service.DataPlaneClient,
+	r io.ReaderAt,
+	size int64,
+	uploadID *common.UUID,
+	parts int,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	log.Info("UploadReaderAtParallel() - start")
+	defer log.Info("UploadReaderAtParallel() - end")
+
+	if uploadID == nil {
+		return NewDataExchangeResultError(fmt.Errorf("upload ID is not specified"))
+	}
+
+	options = options.Ensure()
+	if options.GetDataPacketFileOptions().GetWriteCompression() != common.CompressionNone {
+		// Parts are assembled as-is, thus each part would be a separate compressed stream
+		return NewDataExchangeResultError(fmt.Errorf("parallel upload does not support compression"))
+	}
+	if options.GetEncryptionKeyID() != "" {
+		// Parts are assembled as-is, thus each part would be a separate encrypted stream
+		return NewDataExchangeResultError(fmt.Errorf("parallel upload does not support encryption"))
+	}
+
+	partSize := getUploadPartSize(size, parts)
+	retries := options.GetPartRetries()
+	if retries <= 0 {
+		retries = defaultPartRetries
+	}
+
+	// Zero-sized object is sent as one empty part, which consists of the last packet with payload metadata only
+	var offsets []int64
+	for offset := int64(0); (offset < size) || (len(offsets) == 0); offset += partSize {
+		offsets = append(offsets, offset)
+	}
+	log.Infof("upload %s of %d bytes is split into %d parts", uploadID, size, len(offsets))
+
+	progress := newPartsProgress(options, size)
+
+	results := make([]*DataExchangeResult, len(offsets))
+	wg := sync.WaitGroup{}
+	for i, offset := range offsets {
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+		wg.Add(1)
+		go func(i int, offset, length int64) {
+			defer wg.Done()
+			results[i] = uploadPart(ctx, client, r, offset, length, size, uploadID, retries, progress.getOptions(options, offset))
+		}(i, offset, length)
+	}
+	wg.Wait()
+
+	result := NewDataExchangeResult()
+	defer func() {
+		progress.done(result.Error == nil)
+	}()
+	for _, partResult := range results {
+		result.Send.Data.Len += partResult.Send.Data.Len
+		if partResult.Error != nil {
+			result.Errors = append(result.Errors, partResult.Error)
+			if result.Error == nil {
+				result.Error = partResult.Error
+			}
+			continue
+		}
+		// Status of the object is reported by the part which has completed the object
+		if (result.Recv.ObjectStatus == nil) || partResult.Recv.ObjectStatus.GetStatus().Equals(common.StatusCreated) {
+			result.Recv.ObjectStatus = partResult.Recv.ObjectStatus
+		}
+	}
+
+	return result
+}
+
+// partsProgress aggregates progress of the parts of the parallel upload into progress of the whole object
+type partsProgress struct {
+	mutex   sync.Mutex
+	tracker *common.ProgressTracker
+	// sent maps offset of each part to number of bytes of the part sent so far
+	sent map[int64]int64
+}
+
+// newPartsProgress creates new partsProgress. Returns nil in case options have no progress listener
+func newPartsProgress(options *DataExchangeOptions, size int64) *partsProgress {
+	listener := options.GetProgressListener()
+	if listener == nil {
+		return nil
+	}
+	return &partsProgress{
+		tracker: common.NewProgressTracker(listener, options.GetProgressInterval(), false).SetTotal(size),
+		sent:    make(map[int64]int64),
+	}
+}
+
+// getOptions gets options for the part at offset, which report progress of the part into the aggregate
+func (p *partsProgress) getOptions(options *DataExchangeOptions, offset int64) *DataExchangeOptions {
+	if p == nil {
+		return options
+	}
+	return options.Clone().SetProgressListener(func(progress *common.Progress) {
+		p.observe(offset, progress)
+	})
+}
+
+// observe observes progress of the part at offset
+func (p *partsProgress) observe(offset int64, progress *common.Progress) {
+	p.mutex.Lock()
+	// Failed part is retried from its offset, thus progress of the part is replaced rather than accumulated
+	p.sent[offset] = progress.Transferred - offset
+	sent := p.sum()
+	p.mutex.Unlock()
+
+	p.tracker.Observe(0, sent, false)
+}
+
+// sum gets number of bytes sent in all parts
+func (p *partsProgress) sum() int64 {
+	sent := int64(0)
+	for _, n := range p.sent {
+		sent += n
+	}
+	return sent
+}
+
+// done reports the final progress of the upload
+func (p *partsProgress) done(success bool) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	sent := p.sum()
+	p.mutex.Unlock()
+
+	p.tracker.Observe(0, sent, success)
+}
+
+// getUploadPartSize calculates size of the part for data of the specified size to be split into specified number of parts
+func getUploadPartSize(size int64, parts int) int64 {
+	if parts < 1 {
+		parts = 1
+	}
+	partSize := (size + int64(parts) - 1) / int64(parts)
+	if partSize < MinUploadPartSize {
+		partSize = MinUploadPartSize
+	}
+	return partSize
+}
+
+// uploadPart sends one part of the parallel upload, retrying failed attempts.
+// Delay between attempts is specified by retry policy of the options, in case any.
+func uploadPart(
+	ctx context.Context,
+	client service.DataPlaneClient,
+	r io.ReaderAt,
+	offset, length, total int64,
+	uploadID *common.UUID,
+	retries int,
+	options *DataExchangeOptions,
+) *DataExchangeResult {
+	var result *DataExchangeResult
+	for attempt := 0; attempt <= retries; attempt++ {
+		// Part has its own retries, which are not limited to retryable status codes
+		partOptions := options.Clone().SetOffset(offset).SetRetryPolicy(nil)
+		partOptions.EnsureMetadata().
+			SetUploadUUID(uploadID).
+			EnsureProperties().SetOffset(offset).SetLen(length).SetTotal(total)
+
+		result = UploadContext(ctx, client, io.NewSectionReader(r, offset, length), partOptions)
+		if result.Error == nil {
+			log.Infof("part at %d of upload %s sent %d bytes", offset, uploadID, result.Send.Data.Len)
+			return result
+		}
+		log.Warnf("part at %d of upload %s failed on attempt %d of %d. err: %v", offset, uploadID, attempt+1, retries+1, result.Error)
+		if attempt == retries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			// No reason to retry, since the context is done
+			return result
+		case <-time.After(options.GetRetryPolicy().GetBackoff(attempt + 1)):
+		}
+	}
+	return result
+}
@@ -0,0 +1,246 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// Directory tree is transferred as multi-object stream, one object per directory or regular file.
+// Relative path of the entry is specified in metadata addresses: Dirname holds relative directory and
+// Filename holds name of the file. Directory entry has no Filename. Permissions are specified in metadata mode
+// and modification time is specified in metadata timestamp.
+
+// DirTreeEntry describes one entry of the directory tree - either directory or regular file
+type DirTreeEntry struct {
+	// Path is the path of the entry on the local filesystem
+	Path string
+	// Rel is the slash-separated path of the entry relative to the root of the tree
+	Rel string
+	// Info describes the entry
+	Info fs.FileInfo
+}
+
+// IsDir checks whether the entry is a directory
+func (e *DirTreeEntry) IsDir() bool {
+	return e.Info.IsDir()
+}
+
+// Reader provides data of the entry. File is opened on the first read and is closed as soon as it is read completely,
+// thus readers of many entries can be prepared in advance. Directory entry has no data.
+func (e *DirTreeEntry) Reader() io.Reader {
+	if e.IsDir() {
+		return bytes.NewReader(nil)
+	}
+	return &dirTreeEntryReader{
+		path: e.Path,
+	}
+}
+
+// dirTreeEntryReader reads regular file of the entry, opening it on the first read
+type dirTreeEntryReader struct {
+	path string
+	file *os.File
+	err  error
+}
+
+// Read is an io.Reader interface function
+func (r *dirTreeEntryReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.file == nil {
+		if r.file, r.err = os.Open(r.path); r.err != nil {
+			return 0, r.err
+		}
+	}
+
+	n, err := r.file.Read(p)
+	if err != nil {
+		// File is either read completely or is broken, no need to keep it open
+		_ = r.file.Close()
+		r.err = err
+	}
+	return n, err
+}
+
+// SetMetadata sets relative path, permissions and modification time of the entry in metadata
+func (e *DirTreeEntry) SetMetadata(metadata *common.Metadata) *common.Metadata {
+	if e.IsDir() {
+		metadata.SetDirname(e.Rel)
+	} else {
+		if dir := path.Dir(e.Rel); dir != "." {
+			metadata.SetDirname(dir)
+		}
+		metadata.SetFilename(path.Base(e.Rel))
+	}
+	mtime := e.Info.ModTime()
+	return metadata.
+		SetMode(int32(e.Info.Mode().Perm())).
+		SetTimestamp(mtime.Unix(), int32(mtime.Nanosecond()))
+}
+
+// WalkDirTree walks directory tree of the root and calls fn for each directory and regular file within it,
+// parent directory goes before its content. Root itself is not reported. Other entries, such as symlinks, are skipped.
+func WalkDirTree(root string, fn func(*DirTreeEntry) error) error {
+	return filepath.WalkDir(root, func(_path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if _path == root {
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			log.Infof("skip %s of type %v", _path, d.Type())
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, _path)
+		if err != nil {
+			return err
+		}
+
+		return fn(&DirTreeEntry{
+			Path: _path,
+			Rel:  filepath.ToSlash(rel),
+			Info: info,
+		})
+	})
+}
+
+// DirTreeWriter recreates directory tree from entries described by metadata
+type DirTreeWriter struct {
+	root string
+	// dirs are directories created, their permissions and modification times are applied on Close()
+	dirs []*dirTreeWriterDir
+}
+
+// dirTreeWriterDir describes directory to be finalized
+type dirTreeWriterDir struct {
+	path  string
+	mode  fs.FileMode
+	mtime time.Time
+}
+
+// NewDirTreeWriter creates new DirTreeWriter, which recreates tree within the root
+func NewDirTreeWriter(root string) *DirTreeWriter {
+	return &DirTreeWriter{
+		root: root,
+	}
+}
+
+// getRel gets relative path of the entry described by metadata.
+// Relative path has to stay within the root of the tree and can not be the root itself.
+func getRel(metadata *common.Metadata) (string, error) {
+	rel := path.Join(metadata.GetDirname(), metadata.GetFilename())
+	if (rel == "") || (rel == ".") || path.IsAbs(rel) || (rel == "..") || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("invalid path %q of the directory tree entry", rel)
+	}
+	return rel, nil
+}
+
+// getMode gets permissions of the entry described by metadata
+func getMode(metadata *common.Metadata, _default fs.FileMode) fs.FileMode {
+	if metadata.HasMode() {
+		return fs.FileMode(metadata.GetMode()).Perm()
+	}
+	return _default
+}
+
+// Write creates entry described by metadata. Data of the regular file are read from reader.
+// Returns number of bytes written into the file.
+func (w *DirTreeWriter) Write(metadata *common.Metadata, reader io.Reader) (int64, error) {
+	rel, err := getRel(metadata)
+	if err != nil {
+		return 0, err
+	}
+	_path := filepath.Join(w.root, filepath.FromSlash(rel))
+
+	if metadata.GetFilename() == "" {
+		// Directory entry
+		if err := os.MkdirAll(_path, 0700); err != nil {
+			return 0, err
+		}
+		dir := &dirTreeWriterDir{
+			path: _path,
+			mode: getMode(metadata, 0755),
+		}
+		if metadata.HasTimestamp() {
+			dir.mtime = metadata.GetTs().AsTime()
+		}
+		w.dirs = append(w.dirs, dir)
+		return 0, nil
+	}
+
+	// Regular file entry
+	if err := os.MkdirAll(filepath.Dir(_path), 0700); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(_path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, reader)
+	if err != nil {
+		_ = f.Close()
+		return n, err
+	}
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+	if err := os.Chmod(_path, getMode(metadata, 0644)); err != nil {
+		return n, err
+	}
+	if metadata.HasTimestamp() {
+		mtime := metadata.GetTs().AsTime()
+		return n, os.Chtimes(_path, mtime, mtime)
+	}
+	return n, nil
+}
+
+// Close applies permissions and modification times of directories,
+// which are postponed because creating entries within directory modifies it
+func (w *DirTreeWriter) Close() error {
+	// Children go after parents, thus apply in reverse order
+	for i := len(w.dirs) - 1; i >= 0; i-- {
+		dir := w.dirs[i]
+		if err := os.Chmod(dir.path, dir.mode); err != nil {
+			return err
+		}
+		if !dir.mtime.IsZero() {
+			if err := os.Chtimes(dir.path, dir.mtime, dir.mtime); err != nil {
+				return err
+			}
+		}
+	}
+	w.dirs = nil
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+func TestGetRel(t *testing.T) {
+	tests := []struct {
+		dirname  string
+		filename string
+		rel      string
+		err      bool
+	}{
+		{dirname: "a/b", filename: "x", rel: "a/b/x"},
+		{dirname: "a/b", filename: "", rel: "a/b"},
+		{dirname: "", filename: "x", rel: "x"},
+		{dirname: "a/./b/", filename: "x", rel: "a/b/x"},
+		{dirname: "a/../b", filename: "x", rel: "b/x"},
+		{dirname: "", filename: "", err: true},
+		{dirname: ".", filename: "", err: true},
+		{dirname: "", filename: "../x", err: true},
+		{dirname: "..", filename: "x", err: true},
+		{dirname: "..", filename: "", err: true},
+		{dirname: "/etc", filename: "passwd", err: true},
+		{dirname: "", filename: "/x", err: true},
+		{dirname: "a/../..", filename: "", err: true},
+		{dirname: "a", filename: "../../x", err: true},
+		{dirname: "a/b/../../..", filename: "x", err: true},
+	}
+	for _, test := range tests {
+		rel, err := getRel(common.NewMetadata().SetDirname(test.dirname).SetFilename(test.filename))
+		if (err != nil) != test.err {
+			t.Fatalf("%q %q: unexpected err: %v", test.dirname, test.filename, err)
+		}
+		if rel != test.rel {
+			t.Fatalf("%q %q: relative path %q", test.dirname, test.filename, rel)
+		}
+	}
+}
+
+func TestDirTreeRoundTrip(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []struct {
+		rel  string
+		data []byte
+		mode fs.FileMode
+		dir  bool
+	}{
+		{rel: "a", mode: 0750, dir: true},
+		{rel: "a/b", mode: 0700, dir: true},
+		{rel: "a/b/empty", mode: 0600},
+		{rel: "a/x", data: bytes.Repeat([]byte("0123456789abcdef"), 4*1024), mode: 0640},
+		{rel: "z", data: []byte("z"), mode: 0644},
+	}
+	// Directories are finalized after their content, since creating content modifies directory
+	for i := range entries {
+		entry := entries[len(entries)-1-i]
+		path := filepath.Join(src, filepath.FromSlash(entry.rel))
+		if entry.dir {
+			if err := os.MkdirAll(path, 0700); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, entry.data, entry.mode); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for i := range entries {
+		entry := entries[len(entries)-1-i]
+		path := filepath.Join(src, filepath.FromSlash(entry.rel))
+		if err := os.Chmod(path, entry.mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Send the tree as multi-object stream
+	q := &packetQueue{}
+	w := NewObjectsWriter(q)
+	err := WalkDirTree(src, func(entry *DirTreeEntry) error {
+		options := common.NewDataPacketFileOptions().SetChunkSize(1024)
+		options.SetMetadata(entry.SetMetadata(common.NewMetadata()))
+		_, err := w.WriteObject(entry.Reader(), options)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Receive the tree
+	r := NewObjectsReader(q, nil)
+	tree := NewDirTreeWriter(dst)
+	for i := range entries {
+		metadata, reader, err := r.Next()
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if _, err := tree.Write(metadata, reader); err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dst, filepath.FromSlash(entry.rel))
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (info.IsDir() != entry.dir) || (info.Mode().Perm() != entry.mode) || !info.ModTime().Equal(mtime) {
+			t.Fatalf("%s is recreated as %v %v", entry.rel, info.Mode(), info.ModTime())
+		}
+		if entry.dir {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, entry.data) {
+			t.Fatalf("%s has %d bytes instead of %d", entry.rel, len(data), len(entry.data))
+		}
+	}
+
+	// Entry out of the root is rejected and is not created
+	if _, err := tree.Write(common.NewMetadata().SetDirname("..").SetFilename("escaped"), bytes.NewReader(nil)); err == nil {
+		t.Fatalf("entry out of the root is written")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "..", "escaped")); !os.IsNotExist(err) {
+		t.Fatalf("entry out of the root is created. err: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// Multi-object stream is a stream of DataPackets, which carries multiple objects one after another.
+// Each object is a separate DataPacketFile: payload metadata of the object arrives with its first packet
+// and the object is finalized with the last packet. Empty object is sent as one metadata-only last packet.
+
+// ObjectsWriter writes multi-object stream
+type ObjectsWriter struct {
+	writer common.DataPacketWriter
+}
+
+// NewObjectsWriter creates new ObjectsWriter
+func NewObjectsWriter(writer common.DataPacketWriter) *ObjectsWriter {
+	return &ObjectsWriter{
+		writer: writer,
+	}
+}
+
+// WriteObject sends data read from src as one object described by options. Returns number of bytes read from src.
+func (w *ObjectsWriter) WriteObject(src io.Reader, options *common.DataPacketFileOptions) (int64, error) {
+	f, err := common.OpenDataPacketFileWOptions(w.writer, nil, options)
+	if err != nil {
+		return 0, err
+	}
+	// Object with payload metadata is finalized even in case it is empty, so receiver knows where it ends
+	f.EnsurePayloadMetadata()
+
+	var n int64
+	if src != nil {
+		if n, err = f.ReadFrom(src); err != nil {
+			return n, err
+		}
+	}
+	// Finalize the object, so receiver knows where the next object starts
+	return n, f.Close()
+}
+
+// ObjectsReader reads multi-object stream object by object
+type ObjectsReader struct {
+	reader  common.DataPacketReader
+	options *common.DataPacketFileOptions
+
+	// current is the object being read
+	current *common.DataPacketFileWithOptions
+	// index is the index of the current object within the stream
+	index int
+}
+
+// NewObjectsReader creates new ObjectsReader. Options are applied to each object read.
+func NewObjectsReader(reader common.DataPacketReader, options *common.DataPacketFileOptions) *ObjectsReader {
+	return &ObjectsReader{
+		reader:  reader,
+		options: options,
+		index:   -1,
+	}
+}
+
+// Next skips the rest of the current object and opens the next object.
+// Returns payload metadata and data of the object. Returns io.EOF in case the stream has no more objects.
+func (r *ObjectsReader) Next() (*common.Metadata, io.Reader, error) {
+	if err := r.skip(); err != nil {
+		return nil, nil, err
+	}
+
+	f, err := common.OpenDataPacketFileWOptions(nil, r.reader, r.options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Payload metadata arrives with the first chunk of the object
+	buf := make([]byte, 32*1024)
+	n, readErr := f.Read(buf)
+	if (readErr != nil) && (readErr != io.EOF) {
+		log.Warnf("unable to read object %d. err: %v", r.index+1, readErr)
+		return nil, nil, readErr
+	}
+	if !f.IsAnyReceived() {
+		// No more objects in the stream
+		return nil, nil, io.EOF
+	}
+
+	r.current = f
+	r.index++
+
+	reader := io.Reader(bytes.NewReader(buf[:n]))
+	if readErr == nil {
+		reader = io.MultiReader(reader, f)
+	}
+	return f.GetPayloadMetadata(), reader, nil
+}
+
+// Index gets index of the current object within the stream
+func (r *ObjectsReader) Index() int {
+	return r.index
+}
+
+// skip skips data of the current object not consumed by the reader, so the next object starts at its first packet
+func (r *ObjectsReader) skip() error {
+	if r.current == nil {
+		return nil
+	}
+
+	f := r.current
+	r.current = nil
+	if _, err := io.Copy(io.Discard, f); err != nil {
+		log.Warnf("unable to read object %d. err: %v", r.index, err)
+		return err
+	}
+	if !f.IsLastReceived() {
+		return fmt.Errorf("object %d is not finalized", r.index)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// packetQueue emulates gRPC stream by keeping DataPacket(s) sent to be received afterwards
+type packetQueue struct {
+	packets []*common.DataPacket
+}
+
+// Send is a DataPacketWriter interface function
+func (q *packetQueue) Send(packet *common.DataPacket) error {
+	q.packets = append(q.packets, proto.Clone(packet).(*common.DataPacket))
+	return nil
+}
+
+// Recv is a DataPacketReader interface function
+func (q *packetQueue) Recv() (*common.DataPacket, error) {
+	if len(q.packets) == 0 {
+		return nil, io.EOF
+	}
+	packet := q.packets[0]
+	q.packets = q.packets[1:]
+	return packet, nil
+}
+
+// writeObjects writes objects as multi-object stream, each object is named after its index
+func writeObjects(t *testing.T, objects [][]byte) *packetQueue {
+	q := &packetQueue{}
+	w := NewObjectsWriter(q)
+	for i, object := range objects {
+		options := common.NewDataPacketFileOptions().
+			SetChunkSize(1024).
+			SetMetadata(common.NewMetadata().SetFilename(string(rune('a' + i))))
+		n, err := w.WriteObject(bytes.NewReader(object), options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(object)) {
+			t.Fatalf("object %d: %d bytes written", i, n)
+		}
+	}
+	return q
+}
+
+func TestObjectsRoundTrip(t *testing.T) {
+	// Object bigger than the buffer of the first read is included, as well as empty objects
+	big := bytes.Repeat([]byte("0123456789abcdef"), 4*1024)
+	tests := []struct {
+		name    string
+		objects [][]byte
+	}{
+		{name: "empty in the middle", objects: [][]byte{[]byte("first"), nil, big}},
+		{name: "empty at the ends", objects: [][]byte{nil, big, nil}},
+		{name: "only empty", objects: [][]byte{nil}},
+		{name: "no objects", objects: nil},
+	}
+	for _, test := range tests {
+		// Objects are either read completely or skipped by the next object
+		for _, consume := range []bool{true, false} {
+			r := NewObjectsReader(writeObjects(t, test.objects), nil)
+			for i, object := range test.objects {
+				metadata, reader, err := r.Next()
+				if err != nil {
+					t.Fatalf("%s: object %d: %v", test.name, i, err)
+				}
+				if (r.Index() != i) || (metadata.GetFilename() != string(rune('a'+i))) {
+					t.Fatalf("%s: object %d is read as %d %q", test.name, i, r.Index(), metadata.GetFilename())
+				}
+				if !consume {
+					continue
+				}
+				data, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("%s: object %d: %v", test.name, i, err)
+				}
+				if !bytes.Equal(data, object) {
+					t.Fatalf("%s: object %d has %d bytes instead of %d", test.name, i, len(data), len(object))
+				}
+			}
+			if _, _, err := r.Next(); err != io.EOF {
+				t.Fatalf("%s: unexpected err at the end of the stream: %v", test.name, err)
+			}
+		}
+	}
+}
+
+func TestObjectsTruncated(t *testing.T) {
+	q := writeObjects(t, [][]byte{[]byte("first"), bytes.Repeat([]byte("0123456789abcdef"), 1024)})
+	// Stream breaks before the last object is finalized
+	q.packets = q.packets[:len(q.packets)-1]
+
+	r := NewObjectsReader(q, nil)
+	for i := 0; i < 2; i++ {
+		if _, _, err := r.Next(); err != nil {
+			t.Fatalf("object %d: %v", i, err)
+		}
+	}
+	if _, _, err := r.Next(); (err == nil) || (err == io.EOF) {
+		t.Fatalf("truncated stream is not reported. err: %v", err)
+	}
+}
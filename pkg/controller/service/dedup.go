@@ -0,0 +1,351 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+)
+
+var (
+	// ErrDedupDigestUnspecified specifies the situation when object has no SHA256 digest specified
+	ErrDedupDigestUnspecified = fmt.Errorf("object digest is not specified")
+	// ErrDedupDestinationUnspecified specifies the situation when no destination is provided for the object
+	ErrDedupDestinationUnspecified = fmt.Errorf("object destination is not specified")
+	// ErrDedupUnauthorized specifies the situation when caller is not authorized to link the blob
+	ErrDedupUnauthorized = fmt.Errorf("linking of the blob is not authorized")
+)
+
+const (
+	// DedupDigestType specifies type of the digest objects are deduplicated by
+	DedupDigestType = common.DigestType_DIGEST_SHA256
+	// DefaultDedupBlobsPrefix specifies prefix of the blobs within the bucket of the object, see DefaultDedupBlobAddress
+	DefaultDedupBlobsPrefix = "blobs"
+)
+
+// DedupIndex maps digest of the data to the address of the blob, which holds the data
+type DedupIndex interface {
+	// Lookup looks for the blob with the data of the digest. Returns nil address in case no blob found
+	Lookup(digest *common.Digest) (*common.S3Address, error)
+	// Add adds blob with the data of the digest into the index
+	Add(digest *common.Digest, addr *common.S3Address) error
+}
+
+// DedupStorage stores blobs and links existing blobs to new addresses.
+// MinIO satisfies this interface.
+type DedupStorage interface {
+	// PutA stores data of the blob at the address
+	PutA(addr *common.S3Address, reader io.Reader) (int64, error)
+	// CopyA links data of the existing blob to the new address
+	CopyA(dst, src *common.S3Address) error
+	// SizeA gets size of the blob
+	SizeA(addr *common.S3Address) (int64, error)
+	// RemoveA removes the blob
+	RemoveA(addr *common.S3Address) error
+}
+
+// DedupMetadataStorage stores descriptive metadata along with blobs. Optional extension of DedupStorage,
+// used in case storage provides it. MinIO satisfies this interface.
+type DedupMetadataStorage interface {
+	// PutMetadataA stores data of the blob at the address along with the metadata
+	PutMetadataA(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (int64, error)
+	// CopyMetadataA links data of the existing blob to the new address along with the metadata
+	CopyMetadataA(dst, src *common.S3Address, metadata *common.Metadata) error
+}
+
+// DedupBlobAddress provides address of the blob, which keeps data of the digest, for the object to be stored at dst
+type DedupBlobAddress func(digest *common.Digest, dst *common.S3Address, claims jwt.Claims) *common.S3Address
+
+// DefaultDedupBlobAddress keeps blobs in the bucket of the object, named by the key of the digest,
+// such as blobs/sha256/<hex>
+func DefaultDedupBlobAddress(digest *common.Digest, dst *common.S3Address, _ jwt.Claims) *common.S3Address {
+	return common.NewS3Address(dst.GetBucket(), DefaultDedupBlobsPrefix+"/"+digest.GetKey())
+}
+
+// DedupAuthorizer checks whether caller, identified by the claims, is authorized to link the existing blob src,
+// which holds the data of the digest, to the address of the object dst. Returns nil in case linking is authorized.
+// Digest is claimed by the caller, who does not necessarily have the data, thus caller should be authorized
+// to link blobs it has access to only, such as blobs uploaded by the same tenant.
+type DedupAuthorizer func(src, dst *common.S3Address, claims jwt.Claims) error
+
+// Dedup provides content-addressable deduplicated uploads.
+// Client calculates digest of the object's data and asks via LinkObject call whether the data are known already.
+// In case they are, existing blob is linked to the address of the new object and client skips data transfer.
+// Otherwise, client uploads the object with UploadObject call, data are stored and indexed by their digest.
+// Blob, which caller is not authorized to link, is reported as not found, so client uploads the data.
+// Data are deduplicated by blobs, which are named by digest of their data and are written once by Dedup only,
+// so objects linked do not depend on objects uploaded before, which may be overwritten later.
+type Dedup struct {
+	// Index is a user-provided index of the blobs
+	Index DedupIndex
+	// Storage is a user-provided storage of the blobs
+	Storage DedupStorage
+	// Destination provides address of the object described by payload metadata, such as task and filename
+	Destination func(metadata *common.Metadata, claims jwt.Claims) *common.S3Address
+	// Authorize is a user-provided authorization check of linking. Nothing is linked in case it is not provided
+	Authorize DedupAuthorizer
+	// BlobAddress is an optional user-provided address of the blobs, such as blobs of the tenant.
+	// DefaultDedupBlobAddress is used in case it is not provided. Blobs are not expected to be written by anyone else
+	BlobAddress DedupBlobAddress
+}
+
+// NewDedup creates new Dedup
+func NewDedup(
+	index DedupIndex,
+	storage DedupStorage,
+	destination func(metadata *common.Metadata, claims jwt.Claims) *common.S3Address,
+	authorize DedupAuthorizer,
+) *Dedup {
+	return &Dedup{
+		Index:       index,
+		Storage:     storage,
+		Destination: destination,
+		Authorize:   authorize,
+	}
+}
+
+// SetBlobAddress sets user-provided address of the blobs
+func (d *Dedup) SetBlobAddress(blobAddress DedupBlobAddress) *Dedup {
+	if d == nil {
+		return nil
+	}
+	d.BlobAddress = blobAddress
+	return d
+}
+
+// getBlobAddress gets address of the blob with the data of the digest, for the object to be stored at dst
+func (d *Dedup) getBlobAddress(digest *common.Digest, dst *common.S3Address, claims jwt.Claims) *common.S3Address {
+	if d.BlobAddress == nil {
+		return DefaultDedupBlobAddress(digest, dst, claims)
+	}
+	return d.BlobAddress(digest, dst, claims)
+}
+
+// getDestination gets address of the object described by payload metadata
+func (d *Dedup) getDestination(metadata *common.Metadata, claims jwt.Claims) (*common.S3Address, error) {
+	if d.Destination == nil {
+		return nil, ErrDedupDestinationUnspecified
+	}
+	dst := d.Destination(metadata, claims)
+	if dst == nil {
+		return nil, ErrDedupDestinationUnspecified
+	}
+	return dst, nil
+}
+
+// authorize checks whether linking of the blob is authorized
+func (d *Dedup) authorize(src, dst *common.S3Address, claims jwt.Claims) error {
+	if d.Authorize == nil {
+		return ErrDedupUnauthorized
+	}
+	return d.Authorize(src, dst, claims)
+}
+
+// copy links data of the existing blob, along with the metadata in case storage is able to
+func (d *Dedup) copy(dst, src *common.S3Address, metadata *common.Metadata) error {
+	if storage, ok := d.Storage.(DedupMetadataStorage); ok {
+		return storage.CopyMetadataA(dst, src, metadata)
+	}
+	return d.Storage.CopyA(dst, src)
+}
+
+// newDedupObjectStatus builds ObjectStatus of the object stored at the address
+func newDedupObjectStatus(status *common.Status, addr *common.S3Address, digest *common.Digest, length int64) *common.ObjectStatus {
+	objectStatus := common.NewObjectStatus(status)
+	if addr != nil {
+		objectStatus.SetDomain(common.DomainS3)
+		objectStatus.SetAddress(common.NewAddress(addr))
+	}
+	objectStatus.EnsureProperties().SetDigest(digest).SetLen(length)
+	return objectStatus
+}
+
+// Link links existing blob with the data of the digest specified in payload metadata to the address of the object.
+// Reports StatusCreated along with the size of the blob in case the blob is linked and StatusNotFound in case
+// no blob with the data is known or caller is not authorized to link it.
+func (d *Dedup) Link(metadata *common.Metadata, claims jwt.Claims) (*common.ObjectStatus, error) {
+	log.Info("Dedup.Link() - start")
+	defer log.Info("Dedup.Link() - end")
+
+	if (d.Index == nil) || (d.Storage == nil) {
+		return nil, ErrHandlerUnavailable
+	}
+
+	properties := metadata.GetProperties()
+	digest := properties.GetDigest()
+	if (digest.GetType() != DedupDigestType) || (len(digest.GetData()) == 0) {
+		return nil, ErrDedupDigestUnspecified
+	}
+
+	src, err := d.Index.Lookup(digest)
+	if err != nil {
+		log.Warnf("unable to lookup digest %s. err: %v", digest, err)
+		return nil, err
+	}
+	if src == nil {
+		log.Infof("no blob with digest %s", digest)
+		return newDedupObjectStatus(common.StatusNotFound, nil, digest, 0), nil
+	}
+
+	dst, err := d.getDestination(metadata, claims)
+	if err != nil {
+		return nil, err
+	}
+	if blob := d.getBlobAddress(digest, dst, claims); src.String() != blob.String() {
+		// Only blobs, which are written by Dedup, are known to keep data of the digest
+		log.Warnf("digest %s is indexed with %s instead of blob %s", digest, src, blob)
+		return newDedupObjectStatus(common.StatusNotFound, nil, digest, 0), nil
+	}
+	if err := d.authorize(src, dst, claims); err != nil {
+		log.Warnf("unable to link blob %s to %s. err: %v", src, dst, err)
+		return newDedupObjectStatus(common.StatusNotFound, nil, digest, 0), nil
+	}
+	size, err := d.Storage.SizeA(src)
+	if err != nil {
+		// Index may refer to the blob, which is not available anymore, data have to be uploaded
+		log.Warnf("unable to get size of blob %s. err: %v", src, err)
+		return newDedupObjectStatus(common.StatusNotFound, nil, digest, 0), nil
+	}
+	if err := d.copy(dst, src, metadata); err != nil {
+		log.Warnf("unable to link blob %s to %s. err: %v", src, dst, err)
+		return nil, err
+	}
+	log.Infof("blob %s with digest %s linked to %s", src, digest, dst)
+
+	return newDedupObjectStatus(common.StatusCreated, dst, digest, size), nil
+}
+
+// Receive receives incoming stream with the object, stores its data and indexes them by digest.
+// Digest is calculated by the server, digest provided by the client is verified.
+// Data are staged next to the object first, then are stored as the blob of the digest, in case there is no such blob
+// yet, and the object is linked to the blob. Thus blob keeps data of its digest, whatever happens to the object later.
+func (d *Dedup) Receive(UploadObjectServer service.DataPlane_UploadObjectServer, claims jwt.Claims) (*common.ObjectStatus, error) {
+	log.Info("Dedup.Receive() - start")
+	defer log.Info("Dedup.Receive() - end")
+
+	if (d.Index == nil) || (d.Storage == nil) {
+		return nil, ErrHandlerUnavailable
+	}
+
+	options := common.NewDataPacketFileOptions().SetDecompress(true).SetVerifyDigest(true)
+	f, err := common.OpenDataPacketFileWOptions(nil, UploadObjectServer, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payload metadata arrives with the first chunk of the stream
+	buf := make([]byte, 32*1024)
+	n, readErr := f.Read(buf)
+	if (readErr != nil) && (readErr != io.EOF) {
+		return nil, readErr
+	}
+
+	dst, err := d.getDestination(f.GetPayloadMetadata(), claims)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := io.Reader(bytes.NewReader(buf[:n]))
+	if readErr == nil {
+		reader = io.MultiReader(reader, f)
+	}
+	hash, _ := common.NewDigestHash(DedupDigestType)
+	// Staging address is unique, thus staged data can not be overwritten till they are stored as the blob
+	staging := common.NewS3Address(dst.GetBucket(), dst.GetObject()+".upload-"+common.NewUuidRandom().String())
+	written, err := d.Storage.PutA(staging, io.TeeReader(reader, hash))
+	defer func() {
+		if err := d.Storage.RemoveA(staging); err != nil {
+			log.Warnf("unable to remove staged object %s. err: %v", staging, err)
+		}
+	}()
+	if err != nil {
+		log.Warnf("unable to store object %s. err: %v", dst, err)
+		return nil, err
+	}
+
+	digest := common.NewDigest().SetType(DedupDigestType).SetData(hash.Sum(nil))
+	blob := d.getBlobAddress(digest, dst, claims)
+	if _, err := d.Storage.SizeA(blob); err != nil {
+		// No blob with the data yet
+		if err := d.Storage.CopyA(blob, staging); err != nil {
+			log.Warnf("unable to store blob %s. err: %v", blob, err)
+			return nil, err
+		}
+	}
+	if err := d.copy(dst, blob, f.GetPayloadMetadata()); err != nil {
+		log.Warnf("unable to link blob %s to %s. err: %v", blob, dst, err)
+		return nil, err
+	}
+	if err := d.Index.Add(digest, blob); err != nil {
+		// Object is stored, it is just not deduplicated against
+		log.Warnf("unable to index blob %s with digest %s. err: %v", blob, digest, err)
+	}
+	log.Infof("object %s stored with %d bytes and digest %s", dst, written, digest)
+
+	return newDedupObjectStatus(common.StatusCreated, dst, digest, written), nil
+}
+
+// LinkObjectHandler is a handler for LinkObject call, which can be installed into DataPlaneServer
+func (d *Dedup) LinkObjectHandler(metadata *common.Metadata, claims jwt.Claims) (*common.ObjectStatus, error) {
+	return d.Link(metadata, claims)
+}
+
+// UploadObjectHandler is a handler for UploadObject call, which can be installed into DataPlaneServer
+func (d *Dedup) UploadObjectHandler(UploadObjectServer service.DataPlane_UploadObjectServer, claims jwt.Claims) error {
+	status, err := d.Receive(UploadObjectServer, claims)
+	if err != nil {
+		log.Warnf("unable to receive object. err: %v", err)
+		return err
+	}
+	return UploadObjectServer.SendAndClose(status)
+}
+
+// DedupIndexMap is an in-memory DedupIndex
+type DedupIndexMap struct {
+	mutex sync.Mutex
+	blobs map[string]*common.S3Address
+}
+
+// NewDedupIndexMap creates new DedupIndexMap
+func NewDedupIndexMap() *DedupIndexMap {
+	return &DedupIndexMap{
+		blobs: make(map[string]*common.S3Address),
+	}
+}
+
+// Lookup is a DedupIndex interface function
+func (i *DedupIndexMap) Lookup(digest *common.Digest) (*common.S3Address, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.blobs[digest.GetKey()], nil
+}
+
+// Add is a DedupIndex interface function
+func (i *DedupIndexMap) Add(digest *common.Digest, addr *common.S3Address) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.blobs[digest.GetKey()] = addr
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// dedupStorageMap is an in-memory DedupStorage
+type dedupStorageMap map[string][]byte
+
+// PutA is a DedupStorage interface function
+func (s dedupStorageMap) PutA(addr *common.S3Address, reader io.Reader) (int64, error) {
+	data, err := io.ReadAll(reader)
+	s[addr.String()] = data
+	return int64(len(data)), err
+}
+
+// CopyA is a DedupStorage interface function
+func (s dedupStorageMap) CopyA(dst, src *common.S3Address) error {
+	s[dst.String()] = s[src.String()]
+	return nil
+}
+
+// RemoveA is a DedupStorage interface function
+func (s dedupStorageMap) RemoveA(addr *common.S3Address) error {
+	delete(s, addr.String())
+	return nil
+}
+
+// SizeA is a DedupStorage interface function
+func (s dedupStorageMap) SizeA(addr *common.S3Address) (int64, error) {
+	data, ok := s[addr.String()]
+	if !ok {
+		return 0, fmt.Errorf("no blob %s", addr)
+	}
+	return int64(len(data)), nil
+}
+
+// newTenantDedup creates Dedup, which keeps objects and blobs of the tenant, the audience of the claims, apart
+func newTenantDedup(index DedupIndex, storage DedupStorage) *Dedup {
+	tenant := func(claims jwt.Claims) string {
+		return claims.(*jwt.StandardClaims).Audience
+	}
+	destination := func(metadata *common.Metadata, claims jwt.Claims) *common.S3Address {
+		return common.NewS3Address("bucket", tenant(claims)+"/"+metadata.GetFilename())
+	}
+	sameTenant := func(src, dst *common.S3Address, claims jwt.Claims) error {
+		if !strings.HasPrefix(src.GetObject(), tenant(claims)+"/") {
+			return fmt.Errorf("blob %s belongs to another tenant", src)
+		}
+		return nil
+	}
+	blobAddress := func(digest *common.Digest, dst *common.S3Address, claims jwt.Claims) *common.S3Address {
+		return common.NewS3Address(dst.GetBucket(), tenant(claims)+"/blobs/"+digest.GetKey())
+	}
+	return NewDedup(index, storage, destination, sameTenant).SetBlobAddress(blobAddress)
+}
+
+// newDedupMetadata creates payload metadata of the object with the digest and length claimed by the client
+func newDedupMetadata(filename string, digest *common.Digest, length int64) *common.Metadata {
+	metadata := common.NewMetadata().SetFilename(filename)
+	metadata.EnsureProperties().SetDigest(digest).SetLen(length)
+	return metadata
+}
+
+func TestDedupLink(t *testing.T) {
+	data := "some data"
+	digest := common.NewDigest().SetType(DedupDigestType).Calculate([]byte(data))
+	blob := common.NewS3Address("bucket", "tenant-a/blobs/"+digest.GetKey())
+	object := common.NewS3Address("bucket", "tenant-a/object")
+
+	tests := []struct {
+		name string
+		// indexed specifies address the digest is indexed with
+		indexed *common.S3Address
+		// noAuthorizer specifies whether Dedup has no authorizer
+		noAuthorizer bool
+		tenant       string
+		status       *common.Status
+	}{
+		{name: "no authorizer", indexed: blob, noAuthorizer: true, tenant: "tenant-a", status: common.StatusNotFound},
+		{name: "another tenant", indexed: blob, tenant: "tenant-b", status: common.StatusNotFound},
+		{name: "same tenant", indexed: blob, tenant: "tenant-a", status: common.StatusCreated},
+		{name: "not indexed", tenant: "tenant-a", status: common.StatusNotFound},
+		{name: "indexed object", indexed: object, tenant: "tenant-a", status: common.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storage := dedupStorageMap{}
+			_, _ = storage.PutA(blob, strings.NewReader(data))
+			_, _ = storage.PutA(object, strings.NewReader(data))
+			index := NewDedupIndexMap()
+			if test.indexed != nil {
+				_ = index.Add(digest, test.indexed)
+			}
+			d := newTenantDedup(index, storage)
+			if test.noAuthorizer {
+				d.Authorize = nil
+			}
+
+			// Length claimed by the client is not trusted
+			status, err := d.Link(newDedupMetadata("file", digest, 1000), &jwt.StandardClaims{Audience: test.tenant})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !status.GetStatus().Equals(test.status) {
+				t.Fatalf("unexpected status %v", status.GetStatus())
+			}
+			linked, ok := storage[common.NewS3Address("bucket", test.tenant+"/file").String()]
+			if ok != test.status.Equals(common.StatusCreated) {
+				t.Fatalf("blob is linked: %v", ok)
+			}
+			if ok && ((string(linked) != data) || (status.GetProperties().GetLen() != int64(len(data)))) {
+				t.Fatalf("unexpected data %q of len %d linked", linked, status.GetProperties().GetLen())
+			}
+		})
+	}
+}
+
+func TestDedupReceive(t *testing.T) {
+	tests := []struct {
+		name string
+		// uploads specifies data the object "file" is uploaded with, one upload after another
+		uploads []string
+		// link specifies data, which digest the object "copy" is linked with
+		link   string
+		status *common.Status
+		// objects specifies data of the objects expected, empty data means no object
+		objects map[string]string
+		// stored specifies number of objects and blobs stored, staged data are expected to be removed
+		stored int
+	}{
+		{
+			name:    "uploaded",
+			uploads: []string{"original"},
+			link:    "original",
+			status:  common.StatusCreated,
+			objects: map[string]string{"tenant-a/file": "original", "tenant-a/copy": "original"},
+			stored:  3,
+		},
+		{
+			// Data of the original object are linked even after it is overwritten
+			name:    "overwritten",
+			uploads: []string{"original", "overwritten"},
+			link:    "original",
+			status:  common.StatusCreated,
+			objects: map[string]string{"tenant-a/file": "overwritten", "tenant-a/copy": "original"},
+			stored:  4,
+		},
+		{
+			name:    "not uploaded",
+			uploads: []string{"original"},
+			link:    "another",
+			status:  common.StatusNotFound,
+			objects: map[string]string{"tenant-a/file": "original", "tenant-a/copy": ""},
+			stored:  2,
+		},
+	}
+	for _, test := range tests {
+		storage := dedupStorageMap{}
+		d := newTenantDedup(NewDedupIndexMap(), storage)
+		claims := &jwt.StandardClaims{Audience: "tenant-a"}
+
+		for _, data := range test.uploads {
+			options := common.NewDataPacketFileOptions().SetMetadata(common.NewMetadata().SetFilename("file"))
+			server := newUploadObjectServer(t, []byte(data), options)
+			if err := d.UploadObjectHandler(server, claims); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if !server.status.GetStatus().Equals(common.StatusCreated) {
+				t.Fatalf("%s: unexpected upload status %v", test.name, server.status.GetStatus())
+			}
+		}
+
+		digest := common.NewDigest().SetType(DedupDigestType).Calculate([]byte(test.link))
+		status, err := d.Link(newDedupMetadata("copy", digest, 0), claims)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !status.GetStatus().Equals(test.status) {
+			t.Fatalf("%s: unexpected link status %v", test.name, status.GetStatus())
+		}
+		for object, data := range test.objects {
+			got, ok := storage[common.NewS3Address("bucket", object).String()]
+			if (ok != (data != "")) || (string(got) != data) {
+				t.Fatalf("%s: object %s has data %q", test.name, object, got)
+			}
+		}
+		if len(storage) != test.stored {
+			t.Fatalf("%s: %d objects stored", test.name, len(storage))
+		}
+	}
+}
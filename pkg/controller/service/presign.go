@@ -0,0 +1,212 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/adapter"
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/config/sections"
+	"github.com/sunsingerus/tbox/pkg/minio"
+)
+
+var (
+	// ErrPresignFileUnspecified specifies the situation when request has no task or file specified
+	ErrPresignFileUnspecified = fmt.Errorf("task file is not specified")
+	// ErrPresignUnauthorized specifies the situation when caller is not authorized to access the file
+	ErrPresignUnauthorized = fmt.Errorf("access to task file is not authorized")
+)
+
+// PresignStorage issues presigned URLs of the objects.
+// MinIO satisfies this interface.
+type PresignStorage interface {
+	// PresignedGetA returns URL to get the object directly, valid for expiry
+	PresignedGetA(addr *common.S3Address, expiry time.Duration) (*url.URL, error)
+	// PresignedPutA returns URL to put the object directly, valid for expiry
+	PresignedPutA(addr *common.S3Address, expiry time.Duration) (*url.URL, error)
+}
+
+// PresignAuthorizer checks whether caller, identified by the claims, is authorized to access file of the task
+// with the HTTP method. Returns nil in case access is authorized.
+type PresignAuthorizer func(taskID *common.UUID, filename string, method string, claims jwt.Claims) error
+
+// Presign issues time-limited presigned URLs of the task files via ObjectsReport call.
+// Request has DomainPresignedGet or DomainPresignedPut result domain and each object request
+// addresses the file by DomainTaskID and DomainFilename addresses, where filename is relative to the task,
+// such as in/data.bin or out/result.json.
+// Client fetches or stores the file directly in the object storage instead of streaming it via DataPlane.
+type Presign struct {
+	// Storage is a user-provided storage of the task files
+	Storage PresignStorage
+	// Address provides address of the file of the task
+	Address func(taskID *common.UUID, filename string) *common.S3Address
+	// Authorize is a user-provided authorization check. Nothing is authorized in case it is not provided
+	Authorize PresignAuthorizer
+	// Expiry specifies how long URLs are valid
+	Expiry time.Duration
+}
+
+// NewPresign creates new Presign
+func NewPresign(
+	storage PresignStorage,
+	address func(taskID *common.UUID, filename string) *common.S3Address,
+	authorize PresignAuthorizer,
+	expiry time.Duration,
+) *Presign {
+	return &Presign{
+		Storage:   storage,
+		Address:   address,
+		Authorize: authorize,
+		Expiry:    expiry,
+	}
+}
+
+// NewPresignFromConfig creates new Presign of the task files stored in MinIO.
+// Files are addressed by adapter.TaskMinIO paths and URLs expire as specified by config.
+func NewPresignFromConfig(cfg sections.MinIOConfigurator, authorize PresignAuthorizer) (*Presign, error) {
+	mi, err := minio.NewMinIOFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	address := func(taskID *common.UUID, filename string) *common.S3Address {
+		task := common.NewTask().SetUuid(common.NewAddress(taskID))
+		return adapter.NewTaskMinIOAdapter(cfg, task).GetTaskFileAddress(filename)
+	}
+	return NewPresign(mi, address, authorize, cfg.GetMinIOPresignExpiry()), nil
+}
+
+// IsPresignRequest checks whether request asks for presigned URLs
+func IsPresignRequest(req *common.ObjectsRequest) bool {
+	return presignMethod(req.GetResultDomain()) != ""
+}
+
+// presignMethod gets HTTP method of the presigned URL requested by the result domain
+func presignMethod(domain *common.Domain) string {
+	switch {
+	case domain.Equals(common.DomainPresignedGet):
+		return http.MethodGet
+	case domain.Equals(common.DomainPresignedPut):
+		return http.MethodPut
+	}
+	return ""
+}
+
+// ObjectsReport issues presigned URLs of the files requested. Each URL is reported with its own status,
+// thus files not authorized or not issued do not fail the whole request.
+func (p *Presign) ObjectsReport(_ context.Context, req *common.ObjectsRequest, claims jwt.Claims) (*common.ObjectsList, error) {
+	log.Tracef("Presign.ObjectsReport() - start")
+	defer log.Tracef("Presign.ObjectsReport() - end")
+
+	if (p.Storage == nil) || (p.Address == nil) {
+		return nil, ErrHandlerUnavailable
+	}
+
+	list := common.NewObjectsList()
+	issued := 0
+	for _, request := range req.GetRequests() {
+		method := presignMethod(req.GetResultDomain())
+		if m := presignMethod(request.GetResultDomain()); m != "" {
+			// Object request may override method of the whole request
+			method = m
+		}
+		taskID := request.GetAddress(common.DomainTaskID).GetUuid()
+		filename := request.GetAddress(common.DomainFilename).GetFilename().GetFilename()
+
+		presigned := common.NewPresignedURL().SetFilename(filename).SetMethod(method)
+		if u, expires, err := p.presign(taskID, filename, method, claims); err == nil {
+			presigned.SetStatus(common.StatusOK).SetURL(u.String()).SetExpires(expires)
+			issued++
+		} else {
+			log.Warnf("unable to presign %s %s/%s err: %v", method, taskID, filename, err)
+			presigned.SetStatus(common.StatusFailed)
+		}
+		list.AddPresignedURL(presigned)
+	}
+
+	switch issued {
+	case len(req.GetRequests()):
+		list.SetStatus(common.StatusOK)
+	case 0:
+		list.SetStatus(common.StatusFailed)
+	default:
+		list.SetStatus(common.StatusPartial)
+	}
+	return list, nil
+}
+
+// presign issues presigned URL of the file of the task
+func (p *Presign) presign(taskID *common.UUID, filename, method string, claims jwt.Claims) (*url.URL, time.Time, error) {
+	if (taskID == nil) || !isTaskFilenameValid(filename) {
+		return nil, time.Time{}, ErrPresignFileUnspecified
+	}
+	if p.Authorize == nil {
+		return nil, time.Time{}, ErrPresignUnauthorized
+	}
+	if err := p.Authorize(taskID, filename, method, claims); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	addr := p.Address(taskID, filename)
+	if addr == nil {
+		return nil, time.Time{}, ErrPresignFileUnspecified
+	}
+	expires := time.Now().Add(p.Expiry)
+	var u *url.URL
+	var err error
+	switch method {
+	case http.MethodGet:
+		u, err = p.Storage.PresignedGetA(addr, p.Expiry)
+	case http.MethodPut:
+		u, err = p.Storage.PresignedPutA(addr, p.Expiry)
+	default:
+		err = fmt.Errorf("unsupported method %s", method)
+	}
+	return u, expires, err
+}
+
+// isTaskFilenameValid checks whether filename stays within the task
+func isTaskFilenameValid(filename string) bool {
+	if (filename == "") || strings.HasPrefix(filename, "/") {
+		return false
+	}
+	clean := path.Clean(filename)
+	return (clean != ".") && (clean != "..") && !strings.HasPrefix(clean, "../")
+}
+
+// ObjectsReportHandler is a handler for ObjectsReport call, which can be installed as ObjectsReportHandler.
+// Requests of presigned URLs are served by Presign, all other requests are passed to the next handler.
+func (p *Presign) ObjectsReportHandler(
+	next func(context.Context, *common.ObjectsRequest, jwt.Claims) (*common.ObjectsList, error),
+) func(context.Context, *common.ObjectsRequest, jwt.Claims) (*common.ObjectsList, error) {
+	return func(ctx context.Context, req *common.ObjectsRequest, claims jwt.Claims) (*common.ObjectsList, error) {
+		if IsPresignRequest(req) {
+			return p.ObjectsReport(ctx, req, claims)
+		}
+		if next == nil {
+			return nil, ErrHandlerUnavailable
+		}
+		return next(ctx, req, claims)
+	}
+}
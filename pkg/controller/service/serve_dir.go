@@ -0,0 +1,53 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/controller"
+)
+
+// ServeDir sends directory tree of the root to the client as multi-object stream, one object per directory or
+// regular file. Relative path, permissions and modification time of each entry are specified in payload metadata
+// of the entry. Payload metadata is optional and is used as a template of metadata of each entry.
+func ServeDir(
+	DownloadObjectServer service.DataPlane_DownloadObjectServer,
+	root string,
+	metadata *common.Metadata,
+) error {
+	log.Info("ServeDir() - start")
+	defer log.Info("ServeDir() - end")
+
+	objects := controller.NewObjectsWriter(DownloadObjectServer)
+	return controller.WalkDirTree(root, func(entry *controller.DirTreeEntry) error {
+		entryMetadata := common.NewMetadata()
+		if metadata != nil {
+			entryMetadata = proto.Clone(metadata).(*common.Metadata)
+		}
+		entry.SetMetadata(entryMetadata)
+
+		n, err := objects.WriteObject(entry.Reader(), common.NewDataPacketFileOptions().SetMetadata(entryMetadata))
+		if err != nil {
+			log.Warnf("unable to send %s. err: %v", entry.Path, err)
+			return err
+		}
+		log.Debugf("sent %s with %d bytes", entry.Rel, n)
+		return nil
+	})
+}
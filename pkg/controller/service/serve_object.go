@@ -0,0 +1,174 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/minio"
+	"github.com/sunsingerus/tbox/pkg/store"
+)
+
+// ServeObject sends data of the object of the specified size to the client, honouring range of the request.
+// Payload metadata is optional. Payload metadata sent to the client specifies offset and len of the data sent
+// along with total size of the object.
+func ServeObject(
+	DownloadObjectServer service.DataPlane_DownloadObjectServer,
+	request *common.ObjectRequest,
+	object io.ReaderAt,
+	size int64,
+	metadata *common.Metadata,
+) error {
+	log.Info("ServeObject() - start")
+	defer log.Info("ServeObject() - end")
+
+	offset, length, err := request.ResolveRange(size)
+	if err != nil {
+		log.Warnf("unable to serve object. err: %v", err)
+		return err
+	}
+
+	if metadata == nil {
+		metadata = common.NewMetadata()
+	}
+	metadata.EnsureProperties().SetOffset(offset).SetLen(length).SetTotal(size)
+
+	if length == 0 {
+		// No data to be sent, however client expects payload metadata
+		packet := common.NewDataPacket()
+		packet.SetPayloadMetadata(metadata)
+		packet.SetOffset(offset)
+		packet.SetLast(true)
+		return DownloadObjectServer.Send(packet)
+	}
+
+	f, err := common.OpenDataPacketFileWOptions(
+		DownloadObjectServer,
+		nil,
+		common.NewDataPacketFileOptions().
+			SetMetadata(metadata).
+			SetOffset(offset),
+	)
+	if err != nil {
+		log.Warnf("unable to open data packet file. err: %v", err)
+		return err
+	}
+
+	n, err := f.ReadFrom(io.NewSectionReader(object, offset, length))
+	if err != nil {
+		log.Warnf("unable to send object. err: %v", err)
+		return err
+	}
+	log.Infof("sent %d bytes of object starting at %d", n, offset)
+
+	return f.Close()
+}
+
+// ServeMinIOObject sends data of the object stored in MinIO to the client, honouring range of the request.
+// Payload metadata sent to the client is the metadata stored with the object, such as by MinIO.PutMetadata.
+func ServeMinIOObject(
+	DownloadObjectServer service.DataPlane_DownloadObjectServer,
+	request *common.ObjectRequest,
+	mi *minio.MinIO,
+	addr *common.S3Address,
+) error {
+	log.Info("ServeMinIOObject() - start")
+	defer log.Info("ServeMinIOObject() - end")
+
+	f, err := minio.OpenFile(mi, addr)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Warnf("unable to stat object %s. err: %v", addr, err)
+		return err
+	}
+
+	return ServeObject(DownloadObjectServer, request, f, info.Size, minio.MetadataFromObjectInfo(info))
+}
+
+// ServeStoreObject sends data of the object stored in the object store to the client, honouring range of the request.
+// Payload metadata sent to the client is the metadata stored with the object.
+// Reports store.ErrNotFound in case the object does not exist.
+func ServeStoreObject(
+	DownloadObjectServer service.DataPlane_DownloadObjectServer,
+	request *common.ObjectRequest,
+	s store.ObjectStore,
+	addr *common.S3Address,
+) error {
+	log.Info("ServeStoreObject() - start")
+	defer log.Info("ServeStoreObject() - end")
+
+	info, err := s.Stat(addr)
+	if err != nil {
+		log.Warnf("unable to stat object %s. err: %v", addr, err)
+		return err
+	}
+	reader, err := s.Get(addr)
+	if err != nil {
+		log.Warnf("unable to get object %s. err: %v", addr, err)
+		return err
+	}
+	defer reader.Close()
+
+	object, ok := reader.(io.ReaderAt)
+	if !ok {
+		object = &sequentialReaderAt{reader: reader}
+	}
+
+	var metadata *common.Metadata
+	if info.Metadata != nil {
+		// Properties stored describe the data as it was uploaded, they do not describe the data being sent
+		metadata = proto.Clone(info.Metadata).(*common.Metadata).SetProperties(nil)
+	}
+	return ServeObject(DownloadObjectServer, request, object, info.Size, metadata)
+}
+
+// sequentialReaderAt provides io.ReaderAt over io.Reader, as long as reads go forward only,
+// which is the way ServeObject reads the object.
+type sequentialReaderAt struct {
+	reader io.Reader
+	offset int64
+}
+
+// ReadAt is an io.ReaderAt interface function
+func (r *sequentialReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < r.offset {
+		return 0, fmt.Errorf("unable to read backward at %d from %d", offset, r.offset)
+	}
+	if offset > r.offset {
+		n, err := io.CopyN(io.Discard, r.reader, offset-r.offset)
+		r.offset += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := io.ReadFull(r.reader, p)
+	r.offset += int64(n)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
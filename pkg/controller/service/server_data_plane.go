@@ -15,6 +15,8 @@
 package controller_service
 
 import (
+	"context"
+
 	"github.com/sunsingerus/tbox/pkg/api/common"
 	"github.com/golang-jwt/jwt"
 	log "github.com/sirupsen/logrus"
@@ -31,6 +33,12 @@ type DataPlaneServer struct {
 	UploadObjectHandler func(service.DataPlane_UploadObjectServer, jwt.Claims) error
 	// DownloadObjectHandler is a user-provided handler for DownloadObject call
 	DownloadObjectHandler func(*common.ObjectRequest, service.DataPlane_DownloadObjectServer, jwt.Claims) error
+	// UploadObjectStatusHandler is a user-provided handler for UploadObjectStatus call
+	UploadObjectStatusHandler func(*common.ObjectRequest, jwt.Claims) (*common.ObjectStatus, error)
+	// UploadObjectsHandler is a user-provided handler for UploadObjects call
+	UploadObjectsHandler func(service.DataPlane_UploadObjectsServer, jwt.Claims) error
+	// LinkObjectHandler is a user-provided handler for LinkObject call
+	LinkObjectHandler func(*common.Metadata, jwt.Claims) (*common.ObjectStatus, error)
 }
 
 // Verify interface compatibility
@@ -51,6 +59,39 @@ func NewDataPlaneServer(
 	}
 }
 
+// SetUploadObjectStatusHandler sets user-provided handler for UploadObjectStatus call
+func (s *DataPlaneServer) SetUploadObjectStatusHandler(
+	uploadObjectStatusHandler func(*common.ObjectRequest, jwt.Claims) (*common.ObjectStatus, error),
+) *DataPlaneServer {
+	if s == nil {
+		return nil
+	}
+	s.UploadObjectStatusHandler = uploadObjectStatusHandler
+	return s
+}
+
+// SetUploadObjectsHandler sets user-provided handler for UploadObjects call
+func (s *DataPlaneServer) SetUploadObjectsHandler(
+	uploadObjectsHandler func(service.DataPlane_UploadObjectsServer, jwt.Claims) error,
+) *DataPlaneServer {
+	if s == nil {
+		return nil
+	}
+	s.UploadObjectsHandler = uploadObjectsHandler
+	return s
+}
+
+// SetLinkObjectHandler sets user-provided handler for LinkObject call
+func (s *DataPlaneServer) SetLinkObjectHandler(
+	linkObjectHandler func(*common.Metadata, jwt.Claims) (*common.ObjectStatus, error),
+) *DataPlaneServer {
+	if s == nil {
+		return nil
+	}
+	s.LinkObjectHandler = linkObjectHandler
+	return s
+}
+
 // DataChunks gRPC call
 func (s *DataPlaneServer) DataChunks(DataChunksServer service.DataPlane_DataChunksServer) error {
 	log.Info("DataChunks() - start")
@@ -73,6 +114,17 @@ func (s *DataPlaneServer) UploadObject(UploadObjectServer service.DataPlane_Uplo
 	return s.UploadObjectHandler(UploadObjectServer, ExtractClaims(UploadObjectServer.Context()))
 }
 
+// UploadObjects gRPC call
+func (s *DataPlaneServer) UploadObjects(UploadObjectsServer service.DataPlane_UploadObjectsServer) error {
+	log.Info("UploadObjects() - start")
+	defer log.Info("UploadObjects() - end")
+
+	if s.UploadObjectsHandler == nil {
+		return ErrHandlerUnavailable
+	}
+	return s.UploadObjectsHandler(UploadObjectsServer, ExtractClaims(UploadObjectsServer.Context()))
+}
+
 // DownloadObject gRPC call
 func (s *DataPlaneServer) DownloadObject(request *common.ObjectRequest, DownloadObjectServer service.DataPlane_DownloadObjectServer) error {
 	log.Info("DownloadObject() - start")
@@ -83,3 +135,25 @@ func (s *DataPlaneServer) DownloadObject(request *common.ObjectRequest, Download
 	}
 	return s.DownloadObjectHandler(request, DownloadObjectServer, ExtractClaims(DownloadObjectServer.Context()))
 }
+
+// UploadObjectStatus gRPC call
+func (s *DataPlaneServer) UploadObjectStatus(ctx context.Context, request *common.ObjectRequest) (*common.ObjectStatus, error) {
+	log.Info("UploadObjectStatus() - start")
+	defer log.Info("UploadObjectStatus() - end")
+
+	if s.UploadObjectStatusHandler == nil {
+		return nil, ErrHandlerUnavailable
+	}
+	return s.UploadObjectStatusHandler(request, ExtractClaims(ctx))
+}
+
+// LinkObject gRPC call
+func (s *DataPlaneServer) LinkObject(ctx context.Context, metadata *common.Metadata) (*common.ObjectStatus, error) {
+	log.Info("LinkObject() - start")
+	defer log.Info("LinkObject() - end")
+
+	if s.LinkObjectHandler == nil {
+		return nil, ErrHandlerUnavailable
+	}
+	return s.LinkObjectHandler(metadata, ExtractClaims(ctx))
+}
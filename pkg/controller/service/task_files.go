@@ -0,0 +1,296 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunsingerus/tbox/pkg/adapter"
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/config/sections"
+	"github.com/sunsingerus/tbox/pkg/journal"
+	"github.com/sunsingerus/tbox/pkg/minio"
+	"github.com/sunsingerus/tbox/pkg/store"
+)
+
+var (
+	// ErrTaskFileUnspecified specifies the situation when object has no task or file specified
+	ErrTaskFileUnspecified = fmt.Errorf("task file is not specified")
+	// ErrTaskFileUnauthorized specifies the situation when caller is not authorized to access the task file
+	ErrTaskFileUnauthorized = fmt.Errorf("access to task file is not authorized")
+)
+
+// TaskFilesAuthorizer checks whether caller, identified by the claims, is authorized to access file of the task.
+// Returns nil in case access is authorized.
+type TaskFilesAuthorizer func(taskID *common.UUID, filename string, claims jwt.Claims) error
+
+// TaskFiles provides ready-made DataPlane handlers, which store files of the tasks in the object store,
+// laid out the same way as adapter.TaskMinIO does.
+// Uploaded object is stored into `in` folder of the task specified by payload metadata task UUID and filename.
+// Download request addresses the file by DomainTaskID and DomainFilename addresses, the same way as client.Download does,
+// and the file is served from `result` folder of the task, or from `out` folder in case there is no such result.
+type TaskFiles struct {
+	// Store is a storage of the task files
+	Store store.ObjectStore
+	// Bucket specifies bucket of the Store the task files are stored in
+	Bucket string
+	// Authorize is a user-provided authorization check. Nothing is authorized in case it is not provided
+	Authorize TaskFilesAuthorizer
+	// Journal is an optional journal, objects saved are journaled into
+	Journal journal.Journaller
+}
+
+// NewTaskFiles creates new TaskFiles
+func NewTaskFiles(s store.ObjectStore, bucket string, authorize TaskFilesAuthorizer) *TaskFiles {
+	return &TaskFiles{
+		Store:     s,
+		Bucket:    bucket,
+		Authorize: authorize,
+	}
+}
+
+// NewTaskFilesFromConfig creates new TaskFiles of the task files stored in MinIO
+func NewTaskFilesFromConfig(cfg sections.MinIOConfigurator, authorize TaskFilesAuthorizer) (*TaskFiles, error) {
+	s, err := minio.NewObjectStoreFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewTaskFiles(s, cfg.GetMinIOBucket(), authorize), nil
+}
+
+// SetAuthorize sets user-provided authorization check
+func (t *TaskFiles) SetAuthorize(authorize TaskFilesAuthorizer) *TaskFiles {
+	if t == nil {
+		return nil
+	}
+	t.Authorize = authorize
+	return t
+}
+
+// SetJournal sets journal
+func (t *TaskFiles) SetJournal(j journal.Journaller) *TaskFiles {
+	if t == nil {
+		return nil
+	}
+	t.Journal = j
+	return t
+}
+
+// getAdapter gets adapter of the task to the store
+func (t *TaskFiles) getAdapter(taskID *common.UUID) *adapter.TaskMinIO {
+	task := common.NewTask().SetUuid(common.NewAddress(taskID))
+	return adapter.NewTaskStoreAdapter(t.Store, t.Bucket, task)
+}
+
+// authorize checks whether access to the file of the task is authorized
+func (t *TaskFiles) authorize(taskID *common.UUID, filename string, claims jwt.Claims) error {
+	if (taskID == nil) || !isTaskFilenameValid(filename) {
+		return ErrTaskFileUnspecified
+	}
+	if t.Authorize == nil {
+		return ErrTaskFileUnauthorized
+	}
+	return t.Authorize(taskID, filename, claims)
+}
+
+// getJournal gets journal of the task. Returns nil in case no journal is specified
+func (t *TaskFiles) getJournal(taskID *common.UUID) journal.Journaller {
+	if t.Journal == nil {
+		return nil
+	}
+	return t.Journal.WithTask(common.NewTask().SetUuid(common.NewAddress(taskID)))
+}
+
+// Receive receives incoming stream with the object and stores it into `in` folder of the task.
+// Reports StatusCreated along with the address of the object stored.
+func (t *TaskFiles) Receive(UploadObjectServer service.DataPlane_UploadObjectServer, claims jwt.Claims) (*common.ObjectStatus, error) {
+	log.Info("TaskFiles.Receive() - start")
+	defer log.Info("TaskFiles.Receive() - end")
+
+	if t.Store == nil {
+		return nil, ErrHandlerUnavailable
+	}
+
+	options := common.NewDataPacketFileOptions().SetDecompress(true).SetVerifyDigest(true)
+	f, err := common.OpenDataPacketFileWOptions(nil, UploadObjectServer, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payload metadata arrives with the first chunk of the stream
+	buf := make([]byte, 32*1024)
+	n, readErr := f.Read(buf)
+	if (readErr != nil) && (readErr != io.EOF) {
+		return nil, readErr
+	}
+
+	metadata := f.GetPayloadMetadata()
+	taskID := metadata.GetTaskUuid()
+	filename := metadata.GetFilename()
+	if err := t.authorize(taskID, filename, claims); err != nil {
+		log.Warnf("unable to accept object %s/%s. err: %v", taskID, filename, err)
+		return nil, err
+	}
+	dst := t.getAdapter(taskID).GetInFileAddress(filename, false)
+	j := t.getJournal(taskID)
+
+	reader := io.Reader(bytes.NewReader(buf[:n]))
+	if readErr == nil {
+		reader = io.MultiReader(reader, f)
+	}
+	// Stream, which is truncated or does not match its digest, fails to be read
+	written, err := t.Store.Put(dst, reader, metadata)
+	if err != nil {
+		log.Warnf("unable to store object %s. err: %v", dst, err)
+		// Incomplete object should not be taken as the input of the task
+		_ = t.Store.Remove(dst)
+		if j != nil {
+			j.SaveDataError(err)
+		}
+		return nil, err
+	}
+	log.Infof("object %s stored with %d bytes", dst, written)
+	if j != nil {
+		j.SaveData(common.NewAddress(dst), written, metadata, nil)
+	}
+
+	objectStatus := common.NewObjectStatus(common.StatusCreated).
+		SetDomain(common.DomainS3).
+		SetAddress(common.NewAddress(dst))
+	objectStatus.EnsureProperties().SetLen(written)
+	return objectStatus, nil
+}
+
+// Lookup looks for the file of the task to be downloaded, in `result` folder first and in `out` folder next.
+// Reports store.ErrNotFound in case file is found in neither of them.
+func (t *TaskFiles) Lookup(taskID *common.UUID, filename string) (*store.ObjectInfo, error) {
+	a := t.getAdapter(taskID)
+	for _, addr := range []*common.S3Address{
+		a.GetResultFileAddress(filename, false),
+		a.GetOutFileAddress(filename, false),
+	} {
+		info, err := t.Store.Stat(addr)
+		if store.IsNotFound(err) {
+			continue
+		}
+		return info, err
+	}
+	return nil, store.NotFound(a.GetResultFileAddress(filename, false))
+}
+
+// Serve serves the file of the task requested. Missing file is reported with codes.NotFound gRPC status,
+// so client does not retry the download.
+func (t *TaskFiles) Serve(
+	request *common.ObjectRequest,
+	DownloadObjectServer service.DataPlane_DownloadObjectServer,
+	claims jwt.Claims,
+) error {
+	log.Info("TaskFiles.Serve() - start")
+	defer log.Info("TaskFiles.Serve() - end")
+
+	if t.Store == nil {
+		return ErrHandlerUnavailable
+	}
+
+	taskID := request.GetAddress(common.DomainTaskID).GetUuid()
+	filename := request.GetAddress(common.DomainFilename).GetFilename().GetFilename()
+	if err := t.authorize(taskID, filename, claims); err != nil {
+		log.Warnf("unable to serve object %s/%s. err: %v", taskID, filename, err)
+		if errors.Is(err, ErrTaskFileUnspecified) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	info, err := t.Lookup(taskID, filename)
+	if store.IsNotFound(err) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	return ServeStoreObject(DownloadObjectServer, request, t.Store, info.Address)
+}
+
+// Status reports status of the file of the task uploaded: StatusCreated in case the file is stored in `in` folder
+// of the task and StatusNotFound otherwise. Request addresses the file the same way as download request does.
+func (t *TaskFiles) Status(request *common.ObjectRequest, claims jwt.Claims) (*common.ObjectStatus, error) {
+	log.Info("TaskFiles.Status() - start")
+	defer log.Info("TaskFiles.Status() - end")
+
+	if t.Store == nil {
+		return nil, ErrHandlerUnavailable
+	}
+
+	taskID := request.GetAddress(common.DomainTaskID).GetUuid()
+	filename := request.GetAddress(common.DomainFilename).GetFilename().GetFilename()
+	if err := t.authorize(taskID, filename, claims); err != nil {
+		return nil, err
+	}
+
+	addr := t.getAdapter(taskID).GetInFileAddress(filename, false)
+	info, err := t.Store.Stat(addr)
+	if store.IsNotFound(err) {
+		return common.NewObjectStatus(common.StatusNotFound), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objectStatus := common.NewObjectStatus(common.StatusCreated).
+		SetDomain(common.DomainS3).
+		SetAddress(common.NewAddress(addr))
+	objectStatus.EnsureProperties().SetLen(info.Size)
+	return objectStatus, nil
+}
+
+// UploadObjectHandler is a handler for UploadObject call, which can be installed into DataPlaneServer
+func (t *TaskFiles) UploadObjectHandler(UploadObjectServer service.DataPlane_UploadObjectServer, claims jwt.Claims) error {
+	objectStatus, err := t.Receive(UploadObjectServer, claims)
+	if err != nil {
+		log.Warnf("unable to receive object. err: %v", err)
+		return err
+	}
+	return UploadObjectServer.SendAndClose(objectStatus)
+}
+
+// DownloadObjectHandler is a handler for DownloadObject call, which can be installed into DataPlaneServer
+func (t *TaskFiles) DownloadObjectHandler(
+	request *common.ObjectRequest,
+	DownloadObjectServer service.DataPlane_DownloadObjectServer,
+	claims jwt.Claims,
+) error {
+	return t.Serve(request, DownloadObjectServer, claims)
+}
+
+// UploadObjectStatusHandler is a handler for UploadObjectStatus call, which can be installed into DataPlaneServer
+func (t *TaskFiles) UploadObjectStatusHandler(request *common.ObjectRequest, claims jwt.Claims) (*common.ObjectStatus, error) {
+	return t.Status(request, claims)
+}
+
+// NewDataPlaneServer creates new DataPlaneServer with TaskFiles handlers installed
+func (t *TaskFiles) NewDataPlaneServer() *DataPlaneServer {
+	return NewDataPlaneServer(nil, t.UploadObjectHandler, t.DownloadObjectHandler).
+		SetUploadObjectStatusHandler(t.UploadObjectStatusHandler)
+}
@@ -0,0 +1,124 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/mem"
+)
+
+// uploadObjectServer emulates server side of UploadObject gRPC stream
+type uploadObjectServer struct {
+	grpc.ServerStream
+	packets []*common.DataPacket
+	status  *common.ObjectStatus
+}
+
+// newUploadObjectServer creates new uploadObjectServer with the object sent by client
+func newUploadObjectServer(t *testing.T, data []byte, options *common.DataPacketFileOptions) *uploadObjectServer {
+	s := &uploadObjectServer{}
+	f, err := common.OpenDataPacketFileWOptions(s, nil, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Send is a DataPacketWriter interface function, which is used by client to send the object
+func (s *uploadObjectServer) Send(packet *common.DataPacket) error {
+	s.packets = append(s.packets, proto.Clone(packet).(*common.DataPacket))
+	return nil
+}
+
+// Recv is a DataPlane_UploadObjectServer interface function
+func (s *uploadObjectServer) Recv() (*common.DataPacket, error) {
+	if len(s.packets) == 0 {
+		return nil, io.EOF
+	}
+	packet := s.packets[0]
+	s.packets = s.packets[1:]
+	return packet, nil
+}
+
+// Context is a grpc.ServerStream interface function
+func (s *uploadObjectServer) Context() context.Context {
+	return context.Background()
+}
+
+// SendAndClose is a DataPlane_UploadObjectServer interface function
+func (s *uploadObjectServer) SendAndClose(status *common.ObjectStatus) error {
+	s.status = status
+	return nil
+}
+
+func TestTaskFilesReceive(t *testing.T) {
+	store := mem.NewObjectStore()
+	allowed := func(*common.UUID, string, jwt.Claims) error { return nil }
+	denied := fmt.Errorf("denied")
+
+	tests := []struct {
+		name      string
+		authorize TaskFilesAuthorizer
+		data      []byte
+		err       error
+	}{
+		{name: "file", authorize: allowed, data: []byte("data")},
+		{name: "empty", authorize: allowed},
+		{name: "no authorizer", data: []byte("data"), err: ErrTaskFileUnauthorized},
+		{name: "denied", authorize: func(*common.UUID, string, jwt.Claims) error { return denied }, data: []byte("data"), err: denied},
+	}
+	for _, test := range tests {
+		files := NewTaskFiles(store, "bucket", test.authorize)
+		metadata := common.NewMetadata().SetTaskUUID(common.NewUuidRandom()).SetFilename("file.txt")
+		server := newUploadObjectServer(t, test.data, common.NewDataPacketFileOptions().SetMetadata(metadata))
+		err := files.UploadObjectHandler(server, nil)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+		if test.err != nil {
+			if server.status != nil {
+				t.Fatalf("%s: unexpected status %v", test.name, server.status)
+			}
+			continue
+		}
+		if !server.status.GetStatus().Equals(common.StatusCreated) {
+			t.Fatalf("%s: unexpected status %v", test.name, server.status.GetStatus())
+		}
+		info, err := files.Store.Stat(server.status.GetAddress().GetS3())
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if info.Size != int64(len(test.data)) {
+			t.Fatalf("%s: file of %d bytes is stored with %d bytes", test.name, len(test.data), info.Size)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/json"
+)
+
+var (
+	// ErrTaskUnauthorized specifies the situation when caller is not authorized to access the task
+	ErrTaskUnauthorized = fmt.Errorf("access to task is not authorized")
+)
+
+// TaskRegistry keeps tasks along with their reports and files, which are reported by TaskReports
+type TaskRegistry interface {
+	// PutTask adds the task or replaces the task with the same UUID
+	PutTask(task *common.Task) error
+	// GetTask gets the task. Returns nil task in case the task is not known
+	GetTask(taskID *common.UUID) (*common.Task, error)
+	// AddReport adds report of the task
+	AddReport(taskID *common.UUID, report *common.Report) error
+	// GetReports gets reports of the task in the order they were added
+	GetReports(taskID *common.UUID) ([]*common.Report, error)
+	// PutFile adds file of the task or replaces the file of the task with the same filename
+	PutFile(taskID *common.UUID, file *common.File) error
+	// GetFiles gets files of the task in filename order
+	GetFiles(taskID *common.UUID) ([]*common.File, error)
+}
+
+// TaskReportsAuthorizer checks whether caller, identified by the claims, is authorized to access the task.
+// Returns nil in case access is authorized.
+type TaskReportsAuthorizer func(taskID *common.UUID, claims jwt.Claims) error
+
+// TaskReports is a ready-made ObjectsReport handler, which reports tasks kept in the task registry.
+// Request has DomainTask request domain and each object request addresses the task by its UUID,
+// the same way as client.GetTaskStatus, client.GetTask, client.GetTaskReport and client.GetTaskFiles do.
+// Depending on the result domain of the request, the list reports:
+//   - DomainStatus: status of the task as the status of the object
+//   - DomainTask: the task
+//   - DomainReport: reports of the task
+//   - DomainFile: files of the task, or the file requested by DomainFilename address only
+//
+// Each object request is reported with its own object status, in the same order as requested. Object status
+// properties len specifies how many tasks, reports or files are listed for the object.
+// JSONPath filters of the object request are applied to JSON reports and JSON files, which are then listed
+// with JSON array of the values matched. Object with invalid filter fails with the error of the object status.
+type TaskReports struct {
+	// Registry is a registry of the tasks
+	Registry TaskRegistry
+	// Authorize is a user-provided authorization check. Nothing is authorized in case it is not provided
+	Authorize TaskReportsAuthorizer
+}
+
+// NewTaskReports creates new TaskReports
+func NewTaskReports(registry TaskRegistry, authorize TaskReportsAuthorizer) *TaskReports {
+	return &TaskReports{
+		Registry:  registry,
+		Authorize: authorize,
+	}
+}
+
+// SetAuthorize sets user-provided authorization check
+func (r *TaskReports) SetAuthorize(authorize TaskReportsAuthorizer) *TaskReports {
+	if r == nil {
+		return nil
+	}
+	r.Authorize = authorize
+	return r
+}
+
+// IsTaskReportsRequest checks whether request is one of the requests TaskReports reports
+func IsTaskReportsRequest(req *common.ObjectsRequest) bool {
+	if !req.GetRequestDomain().Equals(common.DomainTask) {
+		return false
+	}
+	domain := req.GetResultDomain()
+	return domain.Equals(common.DomainStatus) ||
+		domain.Equals(common.DomainTask) ||
+		domain.Equals(common.DomainReport) ||
+		domain.Equals(common.DomainFile)
+}
+
+// getTaskID gets UUID of the task the object request addresses
+func getTaskID(request *common.ObjectRequest) *common.UUID {
+	if id := request.GetAddress(common.DomainTaskID).GetUuid(); id != nil {
+		return id
+	}
+	return request.GetAddress().GetUuid()
+}
+
+// authorize checks whether access to the task is authorized
+func (r *TaskReports) authorize(taskID *common.UUID, claims jwt.Claims) error {
+	if r.Authorize == nil {
+		return ErrTaskUnauthorized
+	}
+	return r.Authorize(taskID, claims)
+}
+
+// ObjectsReport reports tasks requested. Objects not found or failed do not fail the whole request.
+func (r *TaskReports) ObjectsReport(_ context.Context, req *common.ObjectsRequest, claims jwt.Claims) (*common.ObjectsList, error) {
+	log.Tracef("TaskReports.ObjectsReport() - start")
+	defer log.Tracef("TaskReports.ObjectsReport() - end")
+
+	if (r.Registry == nil) || !IsTaskReportsRequest(req) {
+		return nil, ErrHandlerUnavailable
+	}
+
+	list := common.NewObjectsList()
+	found := 0
+	for _, request := range req.GetRequests() {
+		objectStatus, ok := r.report(list, req.GetResultDomain(), request, claims)
+		if ok {
+			found++
+		}
+		list.AddObjectStatus(objectStatus)
+	}
+
+	switch found {
+	case len(req.GetRequests()):
+		list.SetStatus(common.StatusOK)
+	case 0:
+		list.SetStatus(common.StatusNotFound)
+	default:
+		list.SetStatus(common.StatusPartial)
+	}
+	return list, nil
+}
+
+// report adds entities of the object requested into the list and returns status of the object along with
+// whether the object is reported
+func (r *TaskReports) report(
+	list *common.ObjectsList,
+	domain *common.Domain,
+	request *common.ObjectRequest,
+	claims jwt.Claims,
+) (*common.ObjectStatus, bool) {
+	taskID := getTaskID(request)
+	if taskID == nil {
+		log.Warnf("no task specified")
+		return common.NewObjectStatus(common.StatusFailed), false
+	}
+	paths, err := json.CompilePaths(request.GetJsonPaths())
+	if err != nil {
+		log.Warnf("unable to report task %s. err: %v", taskID, err)
+		return newTaskObjectStatus(common.StatusFailed, taskID, 0).SetError(common.NewError(err.Error())), false
+	}
+	if err := r.authorize(taskID, claims); err != nil {
+		log.Warnf("unable to report task %s. err: %v", taskID, err)
+		return common.NewObjectStatus(common.StatusFailed), false
+	}
+
+	task, err := r.Registry.GetTask(taskID)
+	if err != nil {
+		log.Warnf("unable to get task %s. err: %v", taskID, err)
+		return newTaskObjectStatus(common.StatusInternalError, taskID, 0), false
+	}
+	if task == nil {
+		return newTaskObjectStatus(common.StatusNotFound, taskID, 0), false
+	}
+
+	switch {
+	case domain.Equals(common.DomainStatus):
+		return newTaskObjectStatus(common.NewStatus(task.GetStatus()), taskID, 0), true
+	case domain.Equals(common.DomainTask):
+		list.AddTask(task)
+		return newTaskObjectStatus(common.StatusOK, taskID, 1), true
+	case domain.Equals(common.DomainReport):
+		reports, err := r.Registry.GetReports(taskID)
+		if err != nil {
+			log.Warnf("unable to get reports of task %s. err: %v", taskID, err)
+			return newTaskObjectStatus(common.StatusInternalError, taskID, 0), false
+		}
+		if reports, err = filterReports(reports, paths); err != nil {
+			log.Warnf("unable to filter reports of task %s. err: %v", taskID, err)
+			return newTaskObjectStatus(common.StatusFailed, taskID, 0).SetError(common.NewError(err.Error())), false
+		}
+		list.AddReport(reports...)
+		return newTaskObjectStatus(common.StatusOK, taskID, len(reports)), true
+	case domain.Equals(common.DomainFile):
+		files, err := r.Registry.GetFiles(taskID)
+		if err != nil {
+			log.Warnf("unable to get files of task %s. err: %v", taskID, err)
+			return newTaskObjectStatus(common.StatusInternalError, taskID, 0), false
+		}
+		if filename := request.GetAddress(common.DomainFilename).GetFilename(); filename != nil {
+			files = selectFiles(files, filename)
+			if len(files) == 0 {
+				return newTaskObjectStatus(common.StatusNotFound, taskID, 0), false
+			}
+		}
+		if files, err = filterFiles(files, paths); err != nil {
+			log.Warnf("unable to filter files of task %s. err: %v", taskID, err)
+			return newTaskObjectStatus(common.StatusFailed, taskID, 0).SetError(common.NewError(err.Error())), false
+		}
+		list.AddFile(files...)
+		return newTaskObjectStatus(common.StatusOK, taskID, len(files)), true
+	}
+	return common.NewObjectStatus(common.StatusFailed), false
+}
+
+// selectFiles selects files with the filename
+func selectFiles(files []*common.File, filename *common.Filename) []*common.File {
+	var res []*common.File
+	for _, file := range files {
+		if file.GetFilename().Equals(filename) {
+			res = append(res, file)
+		}
+	}
+	return res
+}
+
+// filterJSON applies JSONPath filters to the data. Data, which is not JSON, is returned as is
+func filterJSON(data []byte, paths []*json.Path) ([]byte, error) {
+	if (len(paths) == 0) || !json.IsJSON(data) {
+		return data, nil
+	}
+	return json.SelectPaths(data, paths)
+}
+
+// filterReports applies JSONPath filters to the reports along with their sub-reports.
+// Filtered copies are returned, reports provided are not modified
+func filterReports(reports []*common.Report, paths []*json.Path) ([]*common.Report, error) {
+	if len(paths) == 0 {
+		return reports, nil
+	}
+	res := make([]*common.Report, 0, len(reports))
+	for _, report := range reports {
+		report = proto.Clone(report).(*common.Report)
+		if err := filterReport(report, paths); err != nil {
+			return nil, err
+		}
+		res = append(res, report)
+	}
+	return res, nil
+}
+
+// filterReport applies JSONPath filters to the report and its sub-reports in place
+func filterReport(report *common.Report, paths []*json.Path) error {
+	data, err := filterJSON(report.GetBytes(), paths)
+	if err != nil {
+		return err
+	}
+	report.SetBytes(data)
+	for _, subReport := range report.GetChildren() {
+		if err := filterReport(subReport, paths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterFiles applies JSONPath filters to the data of the files.
+// Filtered copies are returned, files provided are not modified
+func filterFiles(files []*common.File, paths []*json.Path) ([]*common.File, error) {
+	if len(paths) == 0 {
+		return files, nil
+	}
+	res := make([]*common.File, 0, len(files))
+	for _, file := range files {
+		data, err := filterJSON(file.GetData(), paths)
+		if err != nil {
+			return nil, err
+		}
+		file = proto.Clone(file).(*common.File)
+		res = append(res, file.SetData(data))
+	}
+	return res, nil
+}
+
+// newTaskObjectStatus builds ObjectStatus of the task with n entities listed
+func newTaskObjectStatus(status *common.Status, taskID *common.UUID, n int) *common.ObjectStatus {
+	objectStatus := common.NewObjectStatus(status).
+		SetDomain(common.DomainUUID).
+		SetAddress(common.NewAddress(taskID))
+	objectStatus.EnsureProperties().SetLen(int64(n))
+	return objectStatus
+}
+
+// ObjectsReportHandler is a handler for ObjectsReport call, which can be installed as ObjectsReportHandler.
+// Requests of the tasks are served by TaskReports, all other requests are passed to the next handler.
+func (r *TaskReports) ObjectsReportHandler(
+	next func(context.Context, *common.ObjectsRequest, jwt.Claims) (*common.ObjectsList, error),
+) func(context.Context, *common.ObjectsRequest, jwt.Claims) (*common.ObjectsList, error) {
+	return func(ctx context.Context, req *common.ObjectsRequest, claims jwt.Claims) (*common.ObjectsList, error) {
+		if IsTaskReportsRequest(req) {
+			return r.ObjectsReport(ctx, req, claims)
+		}
+		if next == nil {
+			return nil, ErrHandlerUnavailable
+		}
+		return next(ctx, req, claims)
+	}
+}
+
+// TaskRegistryMap is an in-memory TaskRegistry.
+// Entities are copied on put and on get, thus callers are free to modify them.
+type TaskRegistryMap struct {
+	mutex   sync.RWMutex
+	tasks   map[string]*common.Task
+	reports map[string][]*common.Report
+	files   map[string]map[string]*common.File
+}
+
+// NewTaskRegistryMap creates new TaskRegistryMap
+func NewTaskRegistryMap() *TaskRegistryMap {
+	return &TaskRegistryMap{
+		tasks:   make(map[string]*common.Task),
+		reports: make(map[string][]*common.Report),
+		files:   make(map[string]map[string]*common.File),
+	}
+}
+
+// PutTask is a TaskRegistry interface function
+func (m *TaskRegistryMap) PutTask(task *common.Task) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.tasks[task.GetUuid().String()] = proto.Clone(task).(*common.Task)
+	return nil
+}
+
+// GetTask is a TaskRegistry interface function
+func (m *TaskRegistryMap) GetTask(taskID *common.UUID) (*common.Task, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if task, ok := m.tasks[taskID.String()]; ok {
+		return proto.Clone(task).(*common.Task), nil
+	}
+	return nil, nil
+}
+
+// AddReport is a TaskRegistry interface function
+func (m *TaskRegistryMap) AddReport(taskID *common.UUID, report *common.Report) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := taskID.String()
+	m.reports[key] = append(m.reports[key], proto.Clone(report).(*common.Report))
+	return nil
+}
+
+// GetReports is a TaskRegistry interface function
+func (m *TaskRegistryMap) GetReports(taskID *common.UUID) ([]*common.Report, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var res []*common.Report
+	for _, report := range m.reports[taskID.String()] {
+		res = append(res, proto.Clone(report).(*common.Report))
+	}
+	return res, nil
+}
+
+// PutFile is a TaskRegistry interface function
+func (m *TaskRegistryMap) PutFile(taskID *common.UUID, file *common.File) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := taskID.String()
+	if m.files[key] == nil {
+		m.files[key] = make(map[string]*common.File)
+	}
+	m.files[key][file.GetFilename().String()] = proto.Clone(file).(*common.File)
+	return nil
+}
+
+// GetFiles is a TaskRegistry interface function
+func (m *TaskRegistryMap) GetFiles(taskID *common.UUID) ([]*common.File, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	files := m.files[taskID.String()]
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	res := make([]*common.File, 0, len(filenames))
+	for _, filename := range filenames {
+		res = append(res, proto.Clone(files[filename]).(*common.File))
+	}
+	return res, nil
+}
@@ -0,0 +1,180 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/json"
+)
+
+// newTaskObjectsRequest creates request of the result domain of the tasks
+func newTaskObjectsRequest(domain *common.Domain, requests ...*common.ObjectRequest) *common.ObjectsRequest {
+	return common.NewObjectsRequest().
+		SetRequestDomain(common.DomainTask).
+		SetResultDomain(domain).
+		Append(requests...)
+}
+
+func TestTaskReportsAuthorize(t *testing.T) {
+	registry := NewTaskRegistryMap()
+	taskID := common.NewUuidRandom()
+	_ = registry.PutTask(common.NewTask().SetUuid(common.NewAddress(taskID)))
+	req := newTaskObjectsRequest(common.DomainTask, common.NewObjectRequest().AppendAddress(common.DomainTaskID, common.NewAddress(taskID)))
+
+	tests := []struct {
+		name      string
+		authorize TaskReportsAuthorizer
+		status    *common.Status
+		tasks     int
+	}{
+		{name: "no authorizer", status: common.StatusNotFound},
+		{name: "denied", authorize: func(*common.UUID, jwt.Claims) error { return fmt.Errorf("denied") }, status: common.StatusNotFound},
+		{name: "authorized", authorize: func(*common.UUID, jwt.Claims) error { return nil }, status: common.StatusOK, tasks: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := NewTaskReports(registry, test.authorize).ObjectsReport(context.Background(), req, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !list.GetStatus().Equals(test.status) {
+				t.Fatalf("unexpected status %v", list.GetStatus())
+			}
+			if list.LenTasks() != test.tasks {
+				t.Fatalf("unexpected %d tasks", list.LenTasks())
+			}
+		})
+	}
+}
+
+func TestTaskReportsJsonPaths(t *testing.T) {
+	registry := NewTaskRegistryMap()
+	taskID := common.NewUuidRandom()
+	_ = registry.PutTask(common.NewTask().SetUuid(common.NewAddress(taskID)))
+	_ = registry.AddReport(taskID, common.NewReport().SetBytes([]byte(`{"a":1,"b":2}`)).
+		AddSubReport(common.NewReport().SetBytes([]byte(`{"a":3}`))))
+	_ = registry.AddReport(taskID, common.NewReport().SetBytes([]byte("plain text")))
+	_ = registry.PutFile(taskID, common.NewFile().SetFilename("file.json").SetData([]byte(`{"a":[4,5]}`)))
+	_ = registry.PutFile(taskID, common.NewFile().SetFilename("file.txt").SetData([]byte("plain text")))
+	authorize := func(*common.UUID, jwt.Claims) error { return nil }
+
+	// Object requests are: filtered, with invalid filter and not filtered
+	newRequest := func(domain *common.Domain) *common.ObjectsRequest {
+		address := common.NewAddress(taskID)
+		return newTaskObjectsRequest(domain,
+			common.NewObjectRequest().AppendAddress(common.DomainTaskID, address).AddJsonPath("$.a", "$.a[0]"),
+			common.NewObjectRequest().AppendAddress(common.DomainTaskID, address).AddJsonPath("$.a", "$.a["),
+			common.NewObjectRequest().AppendAddress(common.DomainTaskID, address),
+		)
+	}
+	check := func(t *testing.T, list *common.ObjectsList, want []string, got func() []string) {
+		if !list.GetStatus().Equals(common.StatusPartial) {
+			t.Fatalf("unexpected status %v", list.GetStatus())
+		}
+		statuses := list.GetObjectStatuses()
+		if len(statuses) != 3 {
+			t.Fatalf("unexpected %d object statuses", len(statuses))
+		}
+		for i, status := range []*common.Status{common.StatusOK, common.StatusFailed, common.StatusOK} {
+			if !statuses[i].GetStatus().Equals(status) {
+				t.Fatalf("object %d has unexpected status %v", i, statuses[i].GetStatus())
+			}
+		}
+		if statuses[0].HasError() || statuses[2].HasError() {
+			t.Fatalf("objects reported have error")
+		}
+		if msg := statuses[1].GetError().GetMsg(); !strings.Contains(msg, "invalid JSONPath") {
+			t.Fatalf("object with invalid filter has unexpected error %q", msg)
+		}
+		if strings.Join(got(), "|") != strings.Join(want, "|") {
+			t.Fatalf("got: %q want: %q", got(), want)
+		}
+	}
+
+	t.Run("reports", func(t *testing.T) {
+		list, err := NewTaskReports(registry, authorize).ObjectsReport(context.Background(), newRequest(common.DomainReport), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, list, []string{
+			`[1]`, `[3]`, "plain text",
+			`{"a":1,"b":2}`, `{"a":3}`, "plain text",
+		}, func() []string {
+			var res []string
+			for _, report := range list.GetReports() {
+				res = append(res, string(report.GetBytes()))
+				for _, subReport := range report.GetChildren() {
+					res = append(res, string(subReport.GetBytes()))
+				}
+			}
+			return res
+		})
+	})
+
+	t.Run("files", func(t *testing.T) {
+		list, err := NewTaskReports(registry, authorize).ObjectsReport(context.Background(), newRequest(common.DomainFile), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, list, []string{
+			`[[4,5],4]`, "plain text",
+			`{"a":[4,5]}`, "plain text",
+		}, func() []string {
+			var res []string
+			for _, file := range list.GetFiles() {
+				res = append(res, string(file.GetData()))
+			}
+			return res
+		})
+	})
+}
+
+func TestFilterKeepsOriginals(t *testing.T) {
+	paths, err := json.CompilePaths([]string{"$.a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Data, which only looks like JSON, is not filtered
+	report := common.NewReport().SetBytes([]byte(`{"a":1}`)).AddSubReport(common.NewReport().SetBytes([]byte(`{"a":`)))
+	reports, err := filterReports([]*common.Report{report}, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(reports[0].GetBytes()) + string(reports[0].GetChildren()[0].GetBytes()); got != `[1]{"a":` {
+		t.Fatalf("report is filtered into %s", got)
+	}
+	if got := string(report.GetBytes()) + string(report.GetChildren()[0].GetBytes()); got != `{"a":1}{"a":` {
+		t.Fatalf("report is modified into %s", got)
+	}
+
+	file := common.NewFile().SetData([]byte(`{"a":2}`))
+	files, err := filterFiles([]*common.File{file}, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(files[0].GetData()); got != `[2]` {
+		t.Fatalf("file is filtered into %s", got)
+	}
+	if got := string(file.GetData()); got != `{"a":2}` {
+		t.Fatalf("file is modified into %s", got)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"io"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+	"github.com/sunsingerus/tbox/pkg/controller"
+)
+
+// ObjectHandler is a user-provided handler of one object received within multi-object stream.
+// Metadata is the payload metadata of the object, reader provides data of the object.
+// Data not read by the handler are discarded.
+type ObjectHandler func(metadata *common.Metadata, reader io.Reader, claims jwt.Claims) (*common.ObjectStatus, error)
+
+// ReceiveObjects receives multi-object stream and calls handler for each object.
+// Objects within the stream are delimited by the last packet of each object, payload metadata of the object
+// arrives with its first packet. Statuses of the objects are returned in the same order as objects arrive.
+// Handler error fails the object, but not the whole stream.
+func ReceiveObjects(
+	UploadObjectsServer service.DataPlane_UploadObjectsServer,
+	claims jwt.Claims,
+	options *common.DataPacketFileOptions,
+	handler ObjectHandler,
+) (*common.ObjectsList, error) {
+	log.Info("ReceiveObjects() - start")
+	defer log.Info("ReceiveObjects() - end")
+
+	list := common.NewObjectsList()
+	objects := controller.NewObjectsReader(UploadObjectsServer, options)
+	for {
+		metadata, reader, err := objects.Next()
+		if err == io.EOF {
+			// No more objects in the stream
+			return list, nil
+		}
+		if err != nil {
+			return list, err
+		}
+
+		status, err := handler(metadata, reader, claims)
+		if err != nil {
+			log.Warnf("unable to handle object %d. err: %v", objects.Index(), err)
+			status = common.NewObjectStatus(common.StatusFailed)
+		}
+		if status == nil {
+			status = common.NewObjectStatus(common.StatusCreated)
+		}
+		list.AddObjectStatus(status)
+	}
+}
+
+// NewUploadObjectsHandler creates handler for UploadObjects call, which can be installed into DataPlaneServer.
+// Incoming objects are decompressed and provided to the object handler one by one.
+func NewUploadObjectsHandler(handler ObjectHandler) func(service.DataPlane_UploadObjectsServer, jwt.Claims) error {
+	return func(UploadObjectsServer service.DataPlane_UploadObjectsServer, claims jwt.Claims) error {
+		options := common.NewDataPacketFileOptions().SetDecompress(true)
+		list, err := ReceiveObjects(UploadObjectsServer, claims, options, handler)
+		if err != nil {
+			return err
+		}
+		return UploadObjectsServer.SendAndClose(list)
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+)
+
+var (
+	// ErrUploadPartUnspecified specifies the situation when incoming stream does not specify offset, len and total of the part
+	ErrUploadPartUnspecified = fmt.Errorf("upload part is not specified")
+	// ErrUploadPartIncomplete specifies the situation when incoming stream ends before all data of the part are received
+	ErrUploadPartIncomplete = fmt.Errorf("upload part is incomplete")
+	// ErrUploadPartTotal specifies the situation when parts of the same upload specify different total size of the object
+	ErrUploadPartTotal = fmt.Errorf("upload part total size mismatch")
+)
+
+// UploadPartsStorage stores parts of parallel uploads and assembles objects out of the parts
+type UploadPartsStorage interface {
+	// PutPart stores data of the part of the upload. Part starts at offset within the object.
+	// Part stored previously at the same offset is replaced.
+	PutPart(uploadID string, offset int64, reader io.Reader) (int64, error)
+	// Compose assembles the object of the upload out of the parts, specified by their offsets in ascending order
+	Compose(uploadID string, metadata *common.Metadata, offsets []int64) error
+	// RemoveParts removes all parts of the upload stored so far
+	RemoveParts(uploadID string) error
+}
+
+// PartsUpload describes state of one parallel upload
+type PartsUpload struct {
+	// ID of the upload, provided by the client
+	ID string
+	// Metadata of the payload, as received with the first part of the upload
+	Metadata *common.Metadata
+	// Total specifies total number of bytes of the object
+	Total int64
+	// Parts maps offset of each received part to its len
+	Parts map[int64]int64
+	// Completed specifies whether the object is assembled out of the parts
+	Completed bool
+	// Updated specifies when the upload was updated last time
+	Updated time.Time
+
+	// composing specifies whether the object is being assembled right now
+	composing bool
+	// receiving specifies number of parts being received right now
+	receiving int
+}
+
+// GetReceived gets number of bytes received in all parts
+func (u *PartsUpload) GetReceived() int64 {
+	if u == nil {
+		return 0
+	}
+	received := int64(0)
+	for _, length := range u.Parts {
+		received += length
+	}
+	return received
+}
+
+// GetOffsets gets offsets of received parts in ascending order
+func (u *PartsUpload) GetOffsets() []int64 {
+	if u == nil {
+		return nil
+	}
+	offsets := make([]int64, 0, len(u.Parts))
+	for offset := range u.Parts {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
+}
+
+// IsReceived checks whether received parts cover the whole object without gaps and overlaps
+func (u *PartsUpload) IsReceived() bool {
+	if u == nil {
+		return false
+	}
+	expected := int64(0)
+	for _, offset := range u.GetOffsets() {
+		if offset != expected {
+			return false
+		}
+		expected += u.Parts[offset]
+	}
+	return (expected == u.Total) && (len(u.Parts) > 0)
+}
+
+// GetObjectStatus builds ObjectStatus of the upload
+func (u *PartsUpload) GetObjectStatus() *common.ObjectStatus {
+	if u == nil {
+		return common.NewObjectStatus(common.StatusNotFound)
+	}
+
+	status := common.NewObjectStatus(common.StatusInProgress)
+	if u.Completed {
+		status.SetStatus(common.StatusCreated)
+	}
+	status.SetDomain(common.DomainUpload)
+	status.SetAddress(common.NewAddress(common.NewUuidFromString(u.ID)))
+	status.EnsureProperties().SetLen(u.GetReceived()).SetTotal(u.Total).SetLast(u.Completed)
+	return status
+}
+
+// UploadParts keeps track of parallel uploads.
+// Client sends parts of the object concurrently, each one on a separate stream tagged with upload UUID and
+// offset, len and total size of the object in payload metadata. Server stores each part via Storage and
+// assembles the object as soon as received parts cover the whole object.
+// Uploads, which are not updated within TTL, are evicted along with their parts, thus abandoned uploads
+// do not hold parts in the storage forever. Client, which continues evicted upload, has to start the upload over.
+type UploadParts struct {
+	mutex   sync.Mutex
+	uploads map[string]*PartsUpload
+
+	// Storage is a user-provided storage of the parts
+	Storage UploadPartsStorage
+	// TTL specifies how long upload is kept since it was updated last time. Zero TTL means uploads are kept forever
+	TTL time.Duration
+}
+
+// NewUploadParts creates new UploadParts. Uploads are kept for DefaultUploadSessionTTL, the same as upload sessions
+func NewUploadParts(storage UploadPartsStorage) *UploadParts {
+	return &UploadParts{
+		uploads: make(map[string]*PartsUpload),
+		Storage: storage,
+		TTL:     DefaultUploadSessionTTL,
+	}
+}
+
+// SetTTL sets how long upload is kept since it was updated last time. Zero TTL means uploads are kept forever
+func (p *UploadParts) SetTTL(ttl time.Duration) *UploadParts {
+	if p == nil {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.TTL = ttl
+	return p
+}
+
+// Get gets upload by its ID. Returns nil in case no upload found
+func (p *UploadParts) Get(id string) *PartsUpload {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.uploads[id]
+}
+
+// Remove removes upload by its ID. Parts of incomplete upload are removed from the storage.
+func (p *UploadParts) Remove(id string) {
+	p.mutex.Lock()
+	upload, ok := p.uploads[id]
+	delete(p.uploads, id)
+	p.mutex.Unlock()
+
+	if ok {
+		p.removeParts(upload)
+	}
+}
+
+// removeParts removes parts of incomplete upload from the storage. Parts of completed upload are removed on compose
+func (p *UploadParts) removeParts(upload *PartsUpload) {
+	if upload.Completed || (p.Storage == nil) {
+		return
+	}
+	if err := p.Storage.RemoveParts(upload.ID); err != nil {
+		log.Warnf("unable to remove parts of upload %s. err: %v", upload.ID, err)
+	}
+}
+
+// Evict removes uploads, which are not updated within TTL as of now, along with their parts.
+// Uploads, which are being received or composed, are kept. Returns number of uploads evicted
+func (p *UploadParts) Evict(now time.Time) int {
+	var expired []*PartsUpload
+
+	p.mutex.Lock()
+	if p.TTL > 0 {
+		for id, upload := range p.uploads {
+			if (upload.receiving == 0) && !upload.composing && (now.Sub(upload.Updated) > p.TTL) {
+				expired = append(expired, upload)
+				delete(p.uploads, id)
+			}
+		}
+	}
+	p.mutex.Unlock()
+
+	// Parts are removed outside of the mutex, since removal may take a while
+	for _, upload := range expired {
+		log.Infof("upload %s expired with %d bytes", upload.ID, upload.GetReceived())
+		p.removeParts(upload)
+	}
+	return len(expired)
+}
+
+// Run evicts expired uploads periodically, till the context is done.
+// Expired uploads are evicted on new parts anyway, Run is needed in case uploads may stop for a long time.
+func (p *UploadParts) Run(ctx context.Context) {
+	log.Info("UploadParts.Run() - start")
+	defer log.Info("UploadParts.Run() - end")
+
+	ticker := time.NewTicker(uploadSessionsEvictInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.Evict(now)
+		}
+	}
+}
+
+// GetObjectStatus builds ObjectStatus of the upload specified by its ID
+func (p *UploadParts) GetObjectStatus(id string) *common.ObjectStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.uploads[id].GetObjectStatus()
+}
+
+// acquire gets existing or creates new upload and marks part of it as being received
+func (p *UploadParts) acquire(id string, metadata *common.Metadata) (*PartsUpload, error) {
+	p.Evict(time.Now())
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	total := metadata.GetProperties().GetTotal()
+	upload, ok := p.uploads[id]
+	if !ok {
+		upload = &PartsUpload{
+			ID:       id,
+			Metadata: metadata,
+			Total:    total,
+			Parts:    make(map[int64]int64),
+		}
+		p.uploads[id] = upload
+	}
+
+	switch {
+	case upload.Completed:
+		return nil, ErrUploadSessionCompleted
+	case upload.Total != total:
+		return nil, ErrUploadPartTotal
+	}
+
+	upload.receiving++
+	upload.Updated = time.Now()
+	return upload, nil
+}
+
+// release marks part of the upload as not being received anymore
+func (p *UploadParts) release(upload *PartsUpload) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	upload.receiving--
+	upload.Updated = time.Now()
+}
+
+// commit commits part of the upload and checks whether the object is ready to be assembled
+func (p *UploadParts) commit(upload *PartsUpload, offset, length int64) (compose bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	upload.Parts[offset] = length
+	upload.Updated = time.Now()
+
+	if upload.Completed || upload.composing || !upload.IsReceived() {
+		return false
+	}
+	upload.composing = true
+	return true
+}
+
+// compose assembles the object out of the parts of the upload
+func (p *UploadParts) compose(upload *PartsUpload) error {
+	p.mutex.Lock()
+	offsets := upload.GetOffsets()
+	p.mutex.Unlock()
+
+	err := p.Storage.Compose(upload.ID, upload.Metadata, offsets)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	upload.composing = false
+	upload.Completed = err == nil
+	upload.Updated = time.Now()
+	return err
+}
+
+// Receive receives incoming stream with one part of the parallel upload and stores it.
+// The object is assembled by the stream which completes the upload.
+func (p *UploadParts) Receive(UploadObjectServer service.DataPlane_UploadObjectServer) (*PartsUpload, error) {
+	log.Info("UploadParts.Receive() - start")
+	defer log.Info("UploadParts.Receive() - end")
+
+	if p.Storage == nil {
+		return nil, ErrHandlerUnavailable
+	}
+
+	f, err := common.OpenDataPacketFile(nil, UploadObjectServer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payload metadata arrives with the first chunk of the stream
+	buf := make([]byte, 32*1024)
+	n, readErr := f.Read(buf)
+	if (readErr != nil) && (readErr != io.EOF) {
+		return nil, readErr
+	}
+
+	metadata := f.GetPayloadMetadata()
+	id := metadata.GetUploadUuid()
+	if id == nil {
+		return nil, ErrUploadSessionUnspecified
+	}
+	properties := metadata.GetProperties()
+	if !properties.HasOffset() || !properties.HasLen() || !properties.HasTotal() {
+		return nil, ErrUploadPartUnspecified
+	}
+	offset := properties.GetOffset()
+	length := properties.GetLen()
+	if f.GetReceivedInitialOffset() != offset {
+		log.Warnf("upload part starts at %d, but stream starts at %d", offset, f.GetReceivedInitialOffset())
+		return nil, ErrUploadSessionOffset
+	}
+
+	upload, err := p.acquire(id.String(), metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(upload)
+
+	reader := io.Reader(bytes.NewReader(buf[:n]))
+	if readErr == nil {
+		reader = io.MultiReader(reader, f)
+	}
+	written, err := p.Storage.PutPart(upload.ID, offset, reader)
+	if err != nil {
+		log.Warnf("unable to store part at %d of upload %s. err: %v", offset, upload.ID, err)
+		return upload, err
+	}
+	if (written != length) || !f.IsLastReceived() {
+		log.Warnf("part at %d of upload %s has %d bytes of %d", offset, upload.ID, written, length)
+		return upload, ErrUploadPartIncomplete
+	}
+	log.Infof("part at %d of upload %s stored with %d bytes", offset, upload.ID, written)
+
+	if p.commit(upload, offset, written) {
+		if err := p.compose(upload); err != nil {
+			log.Warnf("unable to compose upload %s. err: %v", upload.ID, err)
+			return upload, err
+		}
+		log.Infof("upload %s completed with %d bytes", upload.ID, upload.Total)
+	}
+
+	return upload, nil
+}
+
+// UploadObjectHandler is a handler for UploadObject call, which can be installed into DataPlaneServer
+func (p *UploadParts) UploadObjectHandler(UploadObjectServer service.DataPlane_UploadObjectServer, _ jwt.Claims) error {
+	upload, err := p.Receive(UploadObjectServer)
+	if err != nil {
+		// Client retries failed part, thus it has to be reported as failed
+		log.Warnf("unable to receive upload part. err: %v", err)
+		return err
+	}
+	return UploadObjectServer.SendAndClose(p.GetObjectStatus(upload.ID))
+}
+
+// UploadObjectStatusHandler is a handler for UploadObjectStatus call, which can be installed into DataPlaneServer
+func (p *UploadParts) UploadObjectStatusHandler(request *common.ObjectRequest, _ jwt.Claims) (*common.ObjectStatus, error) {
+	id := request.GetAddress(common.DomainUpload).GetUuid()
+	if id == nil {
+		return nil, ErrUploadSessionUnspecified
+	}
+	return p.GetObjectStatus(id.String()), nil
+}
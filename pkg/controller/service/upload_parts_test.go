@@ -0,0 +1,136 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// partsStorageMap is an UploadPartsStorage, which keeps parts and composed objects in memory
+type partsStorageMap struct {
+	parts   map[string]map[int64][]byte
+	objects map[string][]byte
+}
+
+// newPartsStorageMap creates new partsStorageMap
+func newPartsStorageMap() *partsStorageMap {
+	return &partsStorageMap{
+		parts:   map[string]map[int64][]byte{},
+		objects: map[string][]byte{},
+	}
+}
+
+// PutPart is an UploadPartsStorage interface function
+func (s *partsStorageMap) PutPart(uploadID string, offset int64, reader io.Reader) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if s.parts[uploadID] == nil {
+		s.parts[uploadID] = map[int64][]byte{}
+	}
+	s.parts[uploadID][offset] = data
+	return int64(len(data)), err
+}
+
+// Compose is an UploadPartsStorage interface function
+func (s *partsStorageMap) Compose(uploadID string, _ *common.Metadata, offsets []int64) error {
+	var object []byte
+	for _, offset := range offsets {
+		object = append(object, s.parts[uploadID][offset]...)
+	}
+	s.objects[uploadID] = object
+	return s.RemoveParts(uploadID)
+}
+
+// RemoveParts is an UploadPartsStorage interface function
+func (s *partsStorageMap) RemoveParts(uploadID string) error {
+	delete(s.parts, uploadID)
+	return nil
+}
+
+// sendPart sends part of the object to the parallel upload
+func sendPart(t *testing.T, parts *UploadParts, uploadID *common.UUID, data []byte, offset, length int64) {
+	options := common.NewDataPacketFileOptions().SetOffset(offset).SetMetadata(
+		common.NewMetadata().SetUploadUUID(uploadID),
+	)
+	options.GetMetadata().EnsureProperties().SetOffset(offset).SetLen(length).SetTotal(int64(len(data)))
+	server := newUploadObjectServer(t, data[offset:offset+length], options)
+	if err := parts.UploadObjectHandler(server, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadPartsEvict(t *testing.T) {
+	storage := newPartsStorageMap()
+	parts := NewUploadParts(storage).SetTTL(time.Hour)
+	data := []byte("0123456789")
+
+	// Uploads are: abandoned with one part of two, completed long ago, being received and recently updated
+	ids := map[string]*common.UUID{}
+	for _, name := range []string{"abandoned", "completed", "active", "recent"} {
+		ids[name] = common.NewUuidRandom()
+		sendPart(t, parts, ids[name], data, 0, 5)
+	}
+	sendPart(t, parts, ids["completed"], data, 5, 5)
+	if _, err := parts.acquire(ids["active"].String(), parts.Get(ids["active"].String()).Metadata); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, name := range []string{"abandoned", "completed", "active"} {
+		parts.Get(ids[name].String()).Updated = now.Add(-2 * time.Hour)
+	}
+
+	if n := parts.Evict(now); n != 2 {
+		t.Fatalf("%d uploads evicted", n)
+	}
+	tests := []struct {
+		name    string
+		evicted bool
+		parts   int
+	}{
+		{name: "abandoned", evicted: true, parts: 0},
+		{name: "completed", evicted: true, parts: 0},
+		{name: "active", evicted: false, parts: 1},
+		{name: "recent", evicted: false, parts: 1},
+	}
+	for _, test := range tests {
+		id := ids[test.name].String()
+		if (parts.Get(id) == nil) != test.evicted {
+			t.Fatalf("upload %s is evicted: %v", test.name, !test.evicted)
+		}
+		if len(storage.parts[id]) != test.parts {
+			t.Fatalf("upload %s has %d parts stored", test.name, len(storage.parts[id]))
+		}
+	}
+	// Object of the completed upload is kept
+	if !bytes.Equal(storage.objects[ids["completed"].String()], data) {
+		t.Fatalf("completed object is %q", storage.objects[ids["completed"].String()])
+	}
+
+	// Removed upload has its parts removed as well
+	parts.Remove(ids["recent"].String())
+	if (parts.Get(ids["recent"].String()) != nil) || (len(storage.parts[ids["recent"].String()]) != 0) {
+		t.Fatalf("removed upload is kept")
+	}
+
+	// Uploads are kept forever with zero TTL
+	parts.SetTTL(0)
+	if n := parts.Evict(now.Add(1000 * time.Hour)); n != 0 {
+		t.Fatalf("%d uploads evicted", n)
+	}
+}
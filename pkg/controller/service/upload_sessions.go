@@ -0,0 +1,354 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/api/service"
+)
+
+var (
+	// ErrUploadSessionUnspecified specifies the situation when incoming stream has no upload session ID
+	ErrUploadSessionUnspecified = fmt.Errorf("upload session is not specified")
+	// ErrUploadSessionBusy specifies the situation when upload session is being received by another stream
+	ErrUploadSessionBusy = fmt.Errorf("upload session is busy")
+	// ErrUploadSessionCompleted specifies the situation when data arrive into already completed upload session
+	ErrUploadSessionCompleted = fmt.Errorf("upload session is already completed")
+	// ErrUploadSessionOffset specifies the situation when incoming stream does not continue upload session
+	ErrUploadSessionOffset = fmt.Errorf("upload session offset mismatch")
+)
+
+const (
+	// DefaultUploadSessionTTL specifies how long upload session is kept since it was updated last time
+	DefaultUploadSessionTTL = 24 * time.Hour
+	// uploadSessionsEvictInterval specifies how often Run evicts expired sessions
+	uploadSessionsEvictInterval = time.Minute
+)
+
+// UploadSession describes state of one resumable upload
+type UploadSession struct {
+	// ID of the upload session, provided by the client
+	ID string
+	// Metadata of the payload, as received with the first stream of the session
+	Metadata *common.Metadata
+	// Offset specifies number of bytes committed by the server
+	Offset int64
+	// Total specifies total number of bytes expected. Is negative when unknown
+	Total int64
+	// Completed specifies whether the client has finalized the upload
+	Completed bool
+	// Updated specifies when the session was updated last time
+	Updated time.Time
+
+	// writer is where data of the session are appended into
+	writer io.WriteCloser
+	// active specifies whether the session is being received right now
+	active bool
+}
+
+// GetObjectStatus builds ObjectStatus of the session
+func (s *UploadSession) GetObjectStatus() *common.ObjectStatus {
+	if s == nil {
+		return common.NewObjectStatus(common.StatusNotFound)
+	}
+
+	status := common.NewObjectStatus(common.StatusInProgress)
+	if s.Completed {
+		status.SetStatus(common.StatusCreated)
+	}
+	status.SetDomain(common.DomainUpload)
+	status.SetAddress(common.NewAddress(common.NewUuidFromString(s.ID)))
+	status.EnsureProperties().SetOffset(s.Offset).SetLen(s.Offset).SetLast(s.Completed)
+	if s.Total >= 0 {
+		status.EnsureProperties().SetTotal(s.Total)
+	}
+	return status
+}
+
+// UploadSessions keeps track of resumable uploads.
+// Server remembers number of bytes received per upload session, thus client is able to query committed offset
+// and continue interrupted upload from there.
+// Sessions, which are not updated within TTL, are evicted, thus abandoned uploads do not hold their writers forever.
+// Client, which continues evicted session, has to start the upload over.
+type UploadSessions struct {
+	mutex    sync.Mutex
+	sessions map[string]*UploadSession
+
+	// NewWriter is a user-provided function, which provides writer to append data of the new session into.
+	// Writer is closed as soon as the session is completed or evicted.
+	NewWriter func(*UploadSession) (io.WriteCloser, error)
+	// TTL specifies how long session is kept since it was updated last time. Zero TTL means sessions are kept forever
+	TTL time.Duration
+}
+
+// NewUploadSessions creates new UploadSessions
+func NewUploadSessions(newWriter func(*UploadSession) (io.WriteCloser, error)) *UploadSessions {
+	return &UploadSessions{
+		sessions:  make(map[string]*UploadSession),
+		NewWriter: newWriter,
+		TTL:       DefaultUploadSessionTTL,
+	}
+}
+
+// SetTTL sets how long session is kept since it was updated last time. Zero TTL means sessions are kept forever
+func (s *UploadSessions) SetTTL(ttl time.Duration) *UploadSessions {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.TTL = ttl
+	return s
+}
+
+// Get gets session by its ID. Returns nil in case no session found
+func (s *UploadSessions) Get(id string) *UploadSession {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.sessions[id]
+}
+
+// Remove removes session by its ID. Writer of incomplete session is closed.
+func (s *UploadSessions) Remove(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		if !session.Completed && (session.writer != nil) {
+			_ = session.writer.Close()
+		}
+		delete(s.sessions, id)
+	}
+}
+
+// Evict removes sessions, which are not updated within TTL as of now. Sessions being received are kept.
+// Writers of incomplete sessions are closed. Returns number of sessions evicted
+func (s *UploadSessions) Evict(now time.Time) int {
+	var expired []*UploadSession
+
+	s.mutex.Lock()
+	if s.TTL > 0 {
+		for id, session := range s.sessions {
+			if !session.active && (now.Sub(session.Updated) > s.TTL) {
+				expired = append(expired, session)
+				delete(s.sessions, id)
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	// Writers are closed outside of the mutex, since closing may take a while, such as flushing data
+	for _, session := range expired {
+		log.Infof("upload session %s expired with %d bytes", session.ID, session.Offset)
+		if !session.Completed && (session.writer != nil) {
+			if err := session.writer.Close(); err != nil {
+				log.Warnf("unable to close writer of upload session %s. err: %v", session.ID, err)
+			}
+		}
+	}
+	return len(expired)
+}
+
+// Run evicts expired sessions periodically, till the context is done.
+// Expired sessions are evicted on new uploads anyway, Run is needed in case uploads may stop for a long time.
+func (s *UploadSessions) Run(ctx context.Context) {
+	log.Info("UploadSessions.Run() - start")
+	defer log.Info("UploadSessions.Run() - end")
+
+	ticker := time.NewTicker(uploadSessionsEvictInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Evict(now)
+		}
+	}
+}
+
+// acquire gets existing or creates new session and marks it as being received
+func (s *UploadSessions) acquire(id string, metadata *common.Metadata) (*UploadSession, error) {
+	s.Evict(time.Now())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		session = &UploadSession{
+			ID:       id,
+			Metadata: metadata,
+			Total:    -1,
+		}
+		if metadata.GetProperties().HasTotal() {
+			session.Total = metadata.GetProperties().GetTotal()
+		}
+		if s.NewWriter == nil {
+			return nil, ErrHandlerUnavailable
+		}
+		writer, err := s.NewWriter(session)
+		if err != nil {
+			return nil, err
+		}
+		session.writer = writer
+		s.sessions[id] = session
+	}
+
+	switch {
+	case session.active:
+		return nil, ErrUploadSessionBusy
+	case session.Completed:
+		return nil, ErrUploadSessionCompleted
+	}
+
+	session.active = true
+	session.Updated = time.Now()
+	return session, nil
+}
+
+// commit commits specified number of bytes as received by the session
+func (s *UploadSessions) commit(session *UploadSession, n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session.Offset += int64(n)
+	session.Updated = time.Now()
+}
+
+// complete marks session as completed
+func (s *UploadSessions) complete(session *UploadSession) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session.Completed = true
+	session.Updated = time.Now()
+}
+
+// GetObjectStatus builds ObjectStatus of the session specified by its ID
+func (s *UploadSessions) GetObjectStatus(id string) *common.ObjectStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.sessions[id].GetObjectStatus()
+}
+
+// release marks session as not being received anymore
+func (s *UploadSessions) release(session *UploadSession) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session.active = false
+	session.Updated = time.Now()
+}
+
+// Receive receives incoming stream of the resumable upload and appends its data to the upload session.
+// Upload session is identified by upload UUID specified in payload metadata of the stream.
+func (s *UploadSessions) Receive(UploadObjectServer service.DataPlane_UploadObjectServer) (*UploadSession, error) {
+	log.Info("UploadSessions.Receive() - start")
+	defer log.Info("UploadSessions.Receive() - end")
+
+	f, err := common.OpenDataPacketFile(nil, UploadObjectServer)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *UploadSession
+	defer func() {
+		if session != nil {
+			s.release(session)
+		}
+	}()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := f.Read(buf)
+
+		if session == nil {
+			// Payload metadata arrives with the first chunk of the stream
+			id := f.GetPayloadMetadata().GetUploadUuid()
+			if id == nil {
+				return nil, ErrUploadSessionUnspecified
+			}
+			if session, err = s.acquire(id.String(), f.GetPayloadMetadata()); err != nil {
+				return nil, err
+			}
+			if f.GetReceivedInitialOffset() != session.Offset {
+				log.Warnf("upload session %s has offset %d, but stream starts at %d", session.ID, session.Offset, f.GetReceivedInitialOffset())
+				return session, ErrUploadSessionOffset
+			}
+		}
+
+		if n > 0 {
+			written, err := session.writer.Write(buf[:n])
+			// Only bytes which are actually written are committed
+			s.commit(session, written)
+			if err != nil {
+				return session, err
+			}
+		}
+
+		if readErr != nil {
+			if readErr != io.EOF {
+				// Stream is broken, data committed so far can be continued by the next stream
+				return session, readErr
+			}
+			break
+		}
+	}
+
+	if f.IsLastReceived() {
+		// Client has finalized the upload
+		s.complete(session)
+		if err := session.writer.Close(); err != nil {
+			return session, err
+		}
+		log.Infof("upload session %s completed with %d bytes", session.ID, session.Offset)
+	}
+
+	return session, nil
+}
+
+// UploadObjectHandler is a handler for UploadObject call, which can be installed into DataPlaneServer
+func (s *UploadSessions) UploadObjectHandler(UploadObjectServer service.DataPlane_UploadObjectServer, _ jwt.Claims) error {
+	session, err := s.Receive(UploadObjectServer)
+	if err != nil {
+		log.Warnf("unable to receive upload session. err: %v", err)
+		if session == nil {
+			return err
+		}
+	}
+	return UploadObjectServer.SendAndClose(s.GetObjectStatus(session.ID))
+}
+
+// UploadObjectStatusHandler is a handler for UploadObjectStatus call, which can be installed into DataPlaneServer
+func (s *UploadSessions) UploadObjectStatusHandler(request *common.ObjectRequest, _ jwt.Claims) (*common.ObjectStatus, error) {
+	id := request.GetAddress(common.DomainUpload).GetUuid()
+	if id == nil {
+		return nil, ErrUploadSessionUnspecified
+	}
+	return s.GetObjectStatus(id.String()), nil
+}
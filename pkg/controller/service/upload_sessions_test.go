@@ -0,0 +1,100 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller_service
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// sessionWriter is a writer of the upload session, which counts how many times it is closed
+type sessionWriter struct {
+	bytes.Buffer
+	closed int
+}
+
+// Close is an io.Closer interface function
+func (w *sessionWriter) Close() error {
+	w.closed++
+	return nil
+}
+
+func TestUploadSessionsEvict(t *testing.T) {
+	writers := map[string]*sessionWriter{}
+	sessions := NewUploadSessions(func(session *UploadSession) (io.WriteCloser, error) {
+		w := &sessionWriter{}
+		writers[session.ID] = w
+		return w, nil
+	}).SetTTL(time.Hour)
+
+	tests := []struct {
+		id        string
+		completed bool
+		// active specifies whether the session is being received
+		active bool
+		// stale specifies whether the session is updated longer than TTL ago
+		stale   bool
+		evicted bool
+		// closed specifies how many times the writer is closed, either on completion or on eviction
+		closed int
+	}{
+		{id: "abandoned", stale: true, evicted: true, closed: 1},
+		{id: "completed", completed: true, stale: true, evicted: true, closed: 1},
+		{id: "active", active: true, stale: true},
+		{id: "recent"},
+	}
+	now := time.Now()
+	for _, test := range tests {
+		session, err := sessions.acquire(test.id, common.NewMetadata())
+		if err != nil {
+			t.Fatalf("%s: %v", test.id, err)
+		}
+		if test.completed {
+			sessions.complete(session)
+			_ = session.writer.Close()
+		}
+		if !test.active {
+			sessions.release(session)
+		}
+	}
+	// Sessions become stale once all of them are acquired, since acquiring evicts stale sessions
+	for _, test := range tests {
+		if test.stale {
+			sessions.Get(test.id).Updated = now.Add(-2 * time.Hour)
+		}
+	}
+
+	if n := sessions.Evict(now); n != 2 {
+		t.Fatalf("%d sessions evicted", n)
+	}
+	for _, test := range tests {
+		if (sessions.Get(test.id) == nil) != test.evicted {
+			t.Fatalf("%s: session is evicted: %v", test.id, !test.evicted)
+		}
+		if writers[test.id].closed != test.closed {
+			t.Fatalf("%s: writer is closed %d times", test.id, writers[test.id].closed)
+		}
+	}
+
+	// Sessions are kept forever with zero TTL
+	sessions.SetTTL(0)
+	if n := sessions.Evict(now.Add(1000 * time.Hour)); n != 0 {
+		t.Fatalf("%d sessions evicted", n)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	dbsql "database/sql"
+	"fmt"
+	"time"
+
+	databasesql "github.com/jmoiron/sqlx"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Executor runs sql queries w/o response. Both Connection and Tx are executors,
+// thus statements can be run either autocommitted or within the transaction
+type Executor interface {
+	// Exec runs given sql query w/o response
+	Exec(sql string, args ...interface{}) error
+}
+
+// Ensure interface compatibility
+var (
+	_ Executor = &Connection{}
+	_ Executor = &Tx{}
+)
+
+// Tx is a transaction started on the connection
+type Tx struct {
+	params *ConnectionParameters
+	tx     *databasesql.Tx
+}
+
+// Begin starts new transaction. Whether statements are run transactionally depends on the database,
+// such as ClickHouse does not support transactions
+func (c *Connection) Begin() (*Tx, error) {
+	if !c.ensureConnected() {
+		s := fmt.Sprintf("FAILED connect(%s) for transaction", c.GetParams().GetDSNWithHiddenCredentials())
+		log.Warnf(s)
+		return nil, fmt.Errorf(s)
+	}
+
+	tx, err := c.conn.Beginx()
+	if err != nil {
+		log.Warnf("FAILED Begin(%s) err: %v", c.GetParams().GetDSNWithHiddenCredentials(), err)
+		return nil, err
+	}
+	return &Tx{
+		params: c.GetParams(),
+		tx:     tx,
+	}, nil
+}
+
+// Exec runs given sql query w/o response within the transaction
+func (t *Tx) Exec(sql string, args ...interface{}) error {
+	if len(sql) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(t.params.GetExecTimeout()))
+	defer cancel()
+
+	sql = t.tx.Rebind(sql)
+	if _, err := t.tx.ExecContext(ctx, sql, args...); err != nil {
+		log.Warnf("FAILED Tx.Exec(%s) err: %v for SQL: %s", t.params.GetDSNWithHiddenCredentials(), err, sql)
+		return err
+	}
+
+	log.Debugf("tx.Exec():%s", sql)
+
+	return nil
+}
+
+// Commit commits the transaction
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback rolls the transaction back. Rollback of the transaction committed already does nothing
+func (t *Tx) Rollback() error {
+	if err := t.tx.Rollback(); (err != nil) && (err != dbsql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
@@ -15,39 +15,195 @@
 package db
 
 import (
+	"fmt"
+	"io"
+
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/sunsingerus/tbox/pkg/api/common"
 )
 
-// DataChunkTransport defines transport level interface
-// Has the following functions:
-//   Send(*DataChunk) error
-//   Recv() (*DataChunk, error)
+const (
+	// DefaultDataChunksTable specifies default name of the table of the data chunks
+	DefaultDataChunksTable = "data_chunks"
+	// dataChunksBatchSize specifies how many data chunks are fetched by one query
+	dataChunksBatchSize = 64
+)
 
+// DataChunkTransport stores data chunks of the stream in the database table.
+// Each DataPacket of the stream is stored as a row, keyed by UUID of the stream and offset of the chunk.
+// Table has the following columns: stream_uuid, chunk_offset, is_last and packet, which holds serialized DataPacket.
+// Stream is expected to carry one object only, since offsets of the chunks have to be unique within the stream.
+// Each packet is autocommitted on send, unless the transport sends within the transaction specified by SetExecutor.
+// Packets are always received by the connection.
 type DataChunkTransport struct {
+	conn   *Connection
+	table  string
+	stream string
+	// exec runs statements of Send and Remove. Connection is used in case it is not specified
+	exec Executor
+
+	// packets are fetched from the table, but not received yet
+	packets []*common.DataPacket
+	// fetched specifies whether any packet was fetched from the table
+	fetched bool
+	// fetchedOffset specifies offset of the last packet fetched from the table
+	fetchedOffset int64
+	// last specifies whether the last packet of the stream is received
+	last bool
+}
+
+// Ensure interface compatibility
+var (
+	_ common.DataPacketReader = &DataChunkTransport{}
+	_ common.DataPacketWriter = &DataChunkTransport{}
+)
+
+// NewDataChunkTransport creates new transport of the stream specified by UUID. Empty table means DefaultDataChunksTable
+func NewDataChunkTransport(conn *Connection, table string, stream *common.UUID) *DataChunkTransport {
+	log.Infof("db.NewDataChunkTransport() - start")
+	defer log.Infof("db.NewDataChunkTransport() - end")
+
+	if table == "" {
+		table = DefaultDataChunksTable
+	}
+	return &DataChunkTransport{
+		conn:   conn,
+		table:  table,
+		stream: stream.String(),
+	}
+}
+
+// SetExecutor sets executor, which packets are sent and removed with, such as transaction Tx, so packets of the stream
+// are committed along with rows of the caller. Nil executor means packets are autocommitted by the connection
+func (t *DataChunkTransport) SetExecutor(exec Executor) *DataChunkTransport {
+	if t == nil {
+		return nil
+	}
+	t.exec = exec
+	return t
 }
 
-// NewDataChunkTransport
-func NewDataChunkTransport() *DataChunkTransport {
-	log.Infof("clickhouse.NewDataChunkTransport() - start")
-	defer log.Infof("clickhouse.NewDataChunkTransport() - end")
+// getExecutor gets executor packets are sent and removed with
+func (t *DataChunkTransport) getExecutor() Executor {
+	if t.exec == nil {
+		return t.conn
+	}
+	return t.exec
+}
 
-	return &DataChunkTransport{}
+// CreateTable creates table of the data chunks, in case it does not exist.
+// Serialized packet is stored as bytea in PostgreSQL, as LONGBLOB in MySQL and as String in ClickHouse.
+func (t *DataChunkTransport) CreateTable() error {
+	var sql string
+	switch t.conn.GetParams().GetDriverName() {
+	case "pgx":
+		sql = "CREATE TABLE IF NOT EXISTS %s (stream_uuid VARCHAR(36) NOT NULL, chunk_offset BIGINT NOT NULL, is_last SMALLINT NOT NULL, packet BYTEA NOT NULL, PRIMARY KEY (stream_uuid, chunk_offset))"
+	case "mysql":
+		sql = "CREATE TABLE IF NOT EXISTS %s (stream_uuid VARCHAR(36) NOT NULL, chunk_offset BIGINT NOT NULL, is_last TINYINT NOT NULL, packet LONGBLOB NOT NULL, PRIMARY KEY (stream_uuid, chunk_offset))"
+	case "clickhouse":
+		sql = "CREATE TABLE IF NOT EXISTS %s (stream_uuid String, chunk_offset Int64, is_last UInt8, packet String) ENGINE = MergeTree() ORDER BY (stream_uuid, chunk_offset)"
+	default:
+		return fmt.Errorf("unsupported driver %s", t.conn.GetParams().GetDriverName())
+	}
+	return t.conn.Exec(fmt.Sprintf(sql, t.table))
 }
 
-// Send
-func (t *DataChunkTransport) Send(dataChunk *common.DataChunk) error {
-	log.Infof("clickhouse.DataChunkTransport.Send() - start")
-	defer log.Infof("clickhouse.DataChunkTransport.Send() - end")
+// Send stores the packet as a row of the stream, with executor specified by SetExecutor
+func (t *DataChunkTransport) Send(packet *common.DataPacket) error {
+	log.Tracef("db.DataChunkTransport.Send() - start")
+	defer log.Tracef("db.DataChunkTransport.Send() - end")
 
-	return nil
+	buf, err := proto.Marshal(packet)
+	if err != nil {
+		return err
+	}
+	last := 0
+	if packet.GetLast() {
+		last = 1
+	}
+	sql := fmt.Sprintf("INSERT INTO %s (stream_uuid, chunk_offset, is_last, packet) VALUES (?, ?, ?, ?)", t.table)
+	return t.getExecutor().Exec(sql, t.stream, packet.GetOffset(), last, buf)
 }
 
-// Recv
-func (t *DataChunkTransport) Recv() (*common.DataChunk, error) {
-	log.Infof("clickhouse.DataChunkTransport.Recv() - start")
-	defer log.Infof("clickhouse.DataChunkTransport.Recv() - end")
+// Recv receives packets of the stream in offset order. Returns io.EOF after the last packet of the stream is received
+// or in case the stream has no more packets stored. Rows stored after the last packet are ignored.
+// Empty stream has no packets stored at all, thus whether the stream is complete is checked by the receiver,
+// such as with IsLastReceived() of the DataChunkFile.
+// Recv does not wait for the writer: io.EOF is returned as well in case the stream is being written and
+// rows stored so far are received. Such stream is reported as truncated by the DataPacketFileWithOptions,
+// which verifies stream digest. Stream, which is sent within one transaction, is seen either completely or not at all.
+func (t *DataChunkTransport) Recv() (*common.DataPacket, error) {
+	log.Tracef("db.DataChunkTransport.Recv() - start")
+	defer log.Tracef("db.DataChunkTransport.Recv() - end")
+
+	if t.last {
+		return nil, io.EOF
+	}
+	if len(t.packets) == 0 {
+		if err := t.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	if len(t.packets) == 0 {
+		return nil, io.EOF
+	}
+
+	packet := t.packets[0]
+	t.packets = t.packets[1:]
+	if packet.GetLast() {
+		t.last = true
+		t.packets = nil
+	}
+	return packet, nil
+}
+
+// fetch fetches the next batch of packets of the stream from the table
+func (t *DataChunkTransport) fetch() error {
+	sql := fmt.Sprintf(
+		"SELECT chunk_offset, packet FROM %s WHERE stream_uuid = ? AND chunk_offset > ? ORDER BY chunk_offset LIMIT %d",
+		t.table,
+		dataChunksBatchSize,
+	)
+	// The first packet of the stream may start at any offset, including zero
+	after := int64(-1)
+	if t.fetched {
+		after = t.fetchedOffset
+	}
+
+	result := t.conn.Query(sql, t.stream, after)
+	if result.Failed() {
+		return result.GetError()
+	}
+	defer result.Close()
+
+	rows := result.GetRows()
+	for rows.Next() {
+		var offset int64
+		var buf []byte
+		if err := rows.Scan(&offset, &buf); err != nil {
+			return err
+		}
+		packet := common.NewDataPacket()
+		if err := proto.Unmarshal(buf, packet); err != nil {
+			return err
+		}
+		t.packets = append(t.packets, packet)
+		t.fetched = true
+		t.fetchedOffset = offset
+	}
+	return rows.Err()
+}
 
-	return &common.DataChunk{}, nil
+// Remove removes all packets of the stream from the table, with executor specified by SetExecutor
+func (t *DataChunkTransport) Remove() error {
+	var sql string
+	switch t.conn.GetParams().GetDriverName() {
+	case "clickhouse":
+		sql = "ALTER TABLE %s DELETE WHERE stream_uuid = ?"
+	default:
+		sql = "DELETE FROM %s WHERE stream_uuid = ?"
+	}
+	return t.getExecutor().Exec(fmt.Sprintf(sql, t.table), t.stream)
 }
@@ -0,0 +1,65 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// recorder is an Executor, which records statements instead of running them
+type recorder struct {
+	sqls []string
+}
+
+// Exec is an Executor interface function
+func (r *recorder) Exec(sql string, args ...interface{}) error {
+	r.sqls = append(r.sqls, sql)
+	return nil
+}
+
+func TestDataChunkTransportExecutor(t *testing.T) {
+	exec := &recorder{}
+	// Connection is not used, thus it is not specified
+	transport := NewDataChunkTransport(nil, "", common.NewUuidRandom()).SetExecutor(exec)
+
+	f, err := common.OpenDataPacketFileWOptions(transport, nil, common.NewDataPacketFileOptions().SetChunkSize(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Remove(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exec.sqls) < 3 {
+		t.Fatalf("%d statements are run with executor", len(exec.sqls))
+	}
+	for _, sql := range exec.sqls[:len(exec.sqls)-1] {
+		if !strings.HasPrefix(sql, "INSERT INTO "+DefaultDataChunksTable) {
+			t.Fatalf("unexpected statement %s", sql)
+		}
+	}
+	if sql := exec.sqls[len(exec.sqls)-1]; !strings.HasPrefix(sql, "DELETE FROM "+DefaultDataChunksTable) {
+		t.Fatalf("unexpected statement %s", sql)
+	}
+}
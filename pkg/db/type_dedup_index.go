@@ -0,0 +1,82 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// DefaultDedupIndexTable specifies default name of the table of the deduplicated blobs index
+const DefaultDedupIndexTable = "dedup_index"
+
+// DedupIndex keeps index of deduplicated blobs in the database table.
+// Table has digest column with the key of the digest of the blob's data, such as sha256/<hex>, as a primary key,
+// and bucket and object columns with the address of the blob.
+type DedupIndex struct {
+	conn  *Connection
+	table string
+}
+
+// NewDedupIndex creates new DedupIndex. Empty table means DefaultDedupIndexTable
+func NewDedupIndex(conn *Connection, table string) *DedupIndex {
+	if table == "" {
+		table = DefaultDedupIndexTable
+	}
+	return &DedupIndex{
+		conn:  conn,
+		table: table,
+	}
+}
+
+// CreateTable creates table of the index, in case it does not exist.
+// For ClickHouse table has to be created by the user with the engine of choice.
+func (i *DedupIndex) CreateTable() error {
+	sql := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (digest VARCHAR(128) NOT NULL PRIMARY KEY, bucket VARCHAR(255) NOT NULL, object VARCHAR(1024) NOT NULL)",
+		i.table,
+	)
+	return i.conn.Exec(sql)
+}
+
+// Lookup looks for the blob with the data of the digest. Returns nil address in case no blob found
+func (i *DedupIndex) Lookup(digest *common.Digest) (*common.S3Address, error) {
+	sql := fmt.Sprintf("SELECT bucket, object FROM %s WHERE digest = ? LIMIT 1", i.table)
+	var bucket, object string
+	err := i.conn.Query(sql, digest.GetKey()).ScanClose(&bucket, &object)
+	if errors.Is(err, ErrEmptyRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return common.NewS3Address(bucket, object), nil
+}
+
+// Add adds blob with the data of the digest into the index. Blob indexed already is kept.
+func (i *DedupIndex) Add(digest *common.Digest, addr *common.S3Address) error {
+	var sql string
+	switch i.conn.GetParams().GetDriverName() {
+	case "pgx":
+		sql = "INSERT INTO %s (digest, bucket, object) VALUES (?, ?, ?) ON CONFLICT (digest) DO NOTHING"
+	case "mysql":
+		sql = "INSERT IGNORE INTO %s (digest, bucket, object) VALUES (?, ?, ?)"
+	default:
+		sql = "INSERT INTO %s (digest, bucket, object) VALUES (?, ?, ?)"
+	}
+	return i.conn.Exec(fmt.Sprintf(sql, i.table), digest.GetKey(), addr.GetBucket(), addr.GetObject())
+}
@@ -0,0 +1,184 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// DefaultTaskRegistryTablePrefix specifies default prefix of the tables of the task registry
+const DefaultTaskRegistryTablePrefix = "task_registry"
+
+// TaskRegistry keeps tasks along with their reports and files in PostgreSQL tables.
+// Entities are stored serialized, keyed by UUID of the task:
+//   - <prefix>_tasks table has task_uuid column as a primary key and task column
+//   - <prefix>_reports table has id serial column, which keeps order of the reports, task_uuid and report columns
+//   - <prefix>_files table has task_uuid and filename columns as a primary key and file column
+type TaskRegistry struct {
+	conn   *Connection
+	prefix string
+}
+
+// NewTaskRegistry creates new TaskRegistry. Empty prefix means DefaultTaskRegistryTablePrefix
+func NewTaskRegistry(conn *Connection, prefix string) *TaskRegistry {
+	if prefix == "" {
+		prefix = DefaultTaskRegistryTablePrefix
+	}
+	return &TaskRegistry{
+		conn:   conn,
+		prefix: prefix,
+	}
+}
+
+// table gets name of the table of the registry
+func (r *TaskRegistry) table(name string) string {
+	return r.prefix + "_" + name
+}
+
+// CreateTables creates tables of the registry, in case they do not exist
+func (r *TaskRegistry) CreateTables() error {
+	for _, sql := range []string{
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (task_uuid VARCHAR(36) NOT NULL PRIMARY KEY, task BYTEA NOT NULL)",
+			r.table("tasks"),
+		),
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (id BIGSERIAL PRIMARY KEY, task_uuid VARCHAR(36) NOT NULL, report BYTEA NOT NULL)",
+			r.table("reports"),
+		),
+		fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s_task_uuid ON %s (task_uuid)",
+			r.table("reports"), r.table("reports"),
+		),
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (task_uuid VARCHAR(36) NOT NULL, filename VARCHAR(1024) NOT NULL, file BYTEA NOT NULL, PRIMARY KEY (task_uuid, filename))",
+			r.table("files"),
+		),
+	} {
+		if err := r.conn.Exec(sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PutTask adds the task or replaces the task with the same UUID
+func (r *TaskRegistry) PutTask(task *common.Task) error {
+	buf, err := proto.Marshal(task)
+	if err != nil {
+		return err
+	}
+	sql := fmt.Sprintf(
+		"INSERT INTO %s (task_uuid, task) VALUES (?, ?) ON CONFLICT (task_uuid) DO UPDATE SET task = EXCLUDED.task",
+		r.table("tasks"),
+	)
+	return r.conn.Exec(sql, task.GetUuid().String(), buf)
+}
+
+// GetTask gets the task. Returns nil task in case the task is not known
+func (r *TaskRegistry) GetTask(taskID *common.UUID) (*common.Task, error) {
+	sql := fmt.Sprintf("SELECT task FROM %s WHERE task_uuid = ?", r.table("tasks"))
+	var buf []byte
+	err := r.conn.Query(sql, taskID.String()).ScanClose(&buf)
+	if errors.Is(err, ErrEmptyRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return common.NewTaskUnmarshalFrom(buf)
+}
+
+// AddReport adds report of the task
+func (r *TaskRegistry) AddReport(taskID *common.UUID, report *common.Report) error {
+	buf, err := proto.Marshal(report)
+	if err != nil {
+		return err
+	}
+	sql := fmt.Sprintf("INSERT INTO %s (task_uuid, report) VALUES (?, ?)", r.table("reports"))
+	return r.conn.Exec(sql, taskID.String(), buf)
+}
+
+// GetReports gets reports of the task in the order they were added
+func (r *TaskRegistry) GetReports(taskID *common.UUID) ([]*common.Report, error) {
+	sql := fmt.Sprintf("SELECT report FROM %s WHERE task_uuid = ? ORDER BY id", r.table("reports"))
+	var res []*common.Report
+	err := r.queryAll(sql, taskID.String(), func(buf []byte) error {
+		report := common.NewReport()
+		if err := proto.Unmarshal(buf, report); err != nil {
+			return err
+		}
+		res = append(res, report)
+		return nil
+	})
+	return res, err
+}
+
+// PutFile adds file of the task or replaces the file of the task with the same filename
+func (r *TaskRegistry) PutFile(taskID *common.UUID, file *common.File) error {
+	buf, err := proto.Marshal(file)
+	if err != nil {
+		return err
+	}
+	sql := fmt.Sprintf(
+		"INSERT INTO %s (task_uuid, filename, file) VALUES (?, ?, ?) ON CONFLICT (task_uuid, filename) DO UPDATE SET file = EXCLUDED.file",
+		r.table("files"),
+	)
+	return r.conn.Exec(sql, taskID.String(), file.GetFilename().String(), buf)
+}
+
+// GetFiles gets files of the task in filename order
+func (r *TaskRegistry) GetFiles(taskID *common.UUID) ([]*common.File, error) {
+	sql := fmt.Sprintf("SELECT file FROM %s WHERE task_uuid = ? ORDER BY filename", r.table("files"))
+	var res []*common.File
+	err := r.queryAll(sql, taskID.String(), func(buf []byte) error {
+		file := common.NewFile()
+		if err := proto.Unmarshal(buf, file); err != nil {
+			return err
+		}
+		res = append(res, file)
+		return nil
+	})
+	return res, err
+}
+
+// queryAll runs the query of one bytes column and calls f for each row
+func (r *TaskRegistry) queryAll(sql string, taskID string, f func([]byte) error) error {
+	result := r.conn.Query(sql, taskID)
+	if result.Failed() {
+		return result.GetError()
+	}
+	defer result.Close()
+
+	rows := result.GetRows()
+	if rows == nil {
+		return ErrNilRows
+	}
+	for rows.Next() {
+		var buf []byte
+		if err := rows.Scan(&buf); err != nil {
+			return err
+		}
+		if err := f(buf); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
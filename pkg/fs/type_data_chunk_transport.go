@@ -0,0 +1,189 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+const (
+	// packetFileExt specifies extension of the files of the packets
+	packetFileExt = ".packet"
+	// tmpFileExt specifies extension of the files of the packets being written
+	tmpFileExt = ".tmp"
+)
+
+// DataChunkTransport is a spool directory transport of DataPackets.
+// Each packet sent is written into the spool directory as a separate file, named by sequence number of the packet.
+// Packets are received from the spool directory in order of their sequence numbers.
+// Spool directory can be written by one process and read by another one later, such as for store-and-forward
+// delivery in case the server is unreachable.
+type DataChunkTransport struct {
+	mutex sync.Mutex
+	dir   string
+	// remove specifies whether received packets are removed from the spool directory
+	remove bool
+	// sendSeq is the sequence number of the next packet to be sent
+	sendSeq uint64
+	// recvSeq is the sequence number of the next packet to be received
+	recvSeq uint64
+}
+
+// Ensure interface compatibility
+var (
+	_ common.DataPacketReader = &DataChunkTransport{}
+	_ common.DataPacketWriter = &DataChunkTransport{}
+)
+
+// NewDataChunkTransport creates new spool directory transport. Directory is created in case it does not exist.
+// Packets already in the directory are received first and new packets are sent after them.
+// Remove specifies whether received packets are removed from the directory.
+func NewDataChunkTransport(dir string, remove bool) (*DataChunkTransport, error) {
+	log.Tracef("fs.NewDataChunkTransport() - start")
+	defer log.Tracef("fs.NewDataChunkTransport() - end")
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	first, last, found, err := scanSpool(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &DataChunkTransport{
+		dir:    dir,
+		remove: remove,
+	}
+	if found {
+		t.recvSeq = first
+		t.sendSeq = last + 1
+	}
+	return t, nil
+}
+
+// scanSpool finds first and last sequence numbers of the packets in the spool directory
+func scanSpool(dir string) (first, last uint64, found bool, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, packetFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, packetFileExt), 10, 64)
+		if err != nil {
+			// Not a packet file
+			continue
+		}
+		if !found || (seq < first) {
+			first = seq
+		}
+		if !found || (seq > last) {
+			last = seq
+		}
+		found = true
+	}
+	return first, last, found, nil
+}
+
+// packetFilename builds name of the file of the packet with the sequence number
+func (t *DataChunkTransport) packetFilename(seq uint64) string {
+	// Zero-padded sequence number keeps packets sorted in listings
+	return filepath.Join(t.dir, fmt.Sprintf("%020d%s", seq, packetFileExt))
+}
+
+// Send writes the packet into the spool directory.
+// Packet file is written under temporary name and renamed afterwards, so reader never sees partially written packet.
+func (t *DataChunkTransport) Send(packet *common.DataPacket) error {
+	log.Tracef("fs.DataChunkTransport.Send() - start")
+	defer log.Tracef("fs.DataChunkTransport.Send() - end")
+
+	buf, err := proto.Marshal(packet)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	filename := t.packetFilename(t.sendSeq)
+	tmp := filename + tmpFileExt
+	if err := os.WriteFile(tmp, buf, 0600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	t.sendSeq++
+	return nil
+}
+
+// Recv reads the next packet from the spool directory. Returns io.EOF in case there are no more packets.
+func (t *DataChunkTransport) Recv() (*common.DataPacket, error) {
+	log.Tracef("fs.DataChunkTransport.Recv() - start")
+	defer log.Tracef("fs.DataChunkTransport.Recv() - end")
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	filename := t.packetFilename(t.recvSeq)
+	buf, err := os.ReadFile(filename)
+	if os.IsNotExist(err) && t.remove {
+		// Received packets are removed, thus any packet in the directory is not received yet.
+		// It may be sent by another transport, which numbers packets on its own.
+		first, _, found, e := scanSpool(t.dir)
+		if e != nil {
+			return nil, e
+		}
+		if found {
+			t.recvSeq = first
+			filename = t.packetFilename(t.recvSeq)
+			buf, err = os.ReadFile(filename)
+		}
+	}
+	if os.IsNotExist(err) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	packet := common.NewDataPacket()
+	if err := proto.Unmarshal(buf, packet); err != nil {
+		return nil, err
+	}
+	if t.remove {
+		if err := os.Remove(filename); err != nil {
+			log.Warnf("unable to remove packet file %s. err: %v", filename, err)
+		}
+	}
+	t.recvSeq++
+	return packet, nil
+}
@@ -0,0 +1,142 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// spoolOptions are options of the streams spooled
+func spoolOptions() *common.DataPacketFileOptions {
+	return common.NewDataPacketFileOptions().
+		SetChunkSize(1024).
+		SetStreamDigest(common.DigestType_DIGEST_SHA256).
+		SetVerifyDigest(true)
+}
+
+// spool writes data as one stream into the spool directory by new transport
+func spool(t *testing.T, dir string, data []byte) {
+	transport, err := NewDataChunkTransport(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := common.OpenDataPacketFileWOptions(transport, nil, spoolOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// unspool reads one stream from the spool directory by the transport
+func unspool(t *testing.T, transport *DataChunkTransport) []byte {
+	f, err := common.OpenDataPacketFileWOptions(nil, transport, spoolOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// spooled counts packets in the spool directory
+func spooled(t *testing.T, dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestDataChunkTransportReopen(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	spool(t, dir, data)
+	n := spooled(t, dir)
+	if n < 2 {
+		t.Fatalf("stream is spooled into %d packets", n)
+	}
+
+	// Transport, which does not remove packets, can be reopened and read again
+	for i := 0; i < 2; i++ {
+		transport, err := NewDataChunkTransport(dir, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if received := unspool(t, transport); !bytes.Equal(received, data) {
+			t.Fatalf("unexpected %d bytes received", len(received))
+		}
+		if _, err := transport.Recv(); err != io.EOF {
+			t.Fatalf("unexpected err %v", err)
+		}
+	}
+	if spooled(t, dir) != n {
+		t.Fatalf("packets are removed")
+	}
+
+	// Reopened transport sends packets after the packets already spooled
+	spool(t, dir, []byte("more data"))
+	transport, err := NewDataChunkTransport(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received := unspool(t, transport); !bytes.Equal(received, data) {
+		t.Fatalf("unexpected %d bytes received", len(received))
+	}
+	if received := unspool(t, transport); string(received) != "more data" {
+		t.Fatalf("unexpected data %q received", received)
+	}
+}
+
+func TestDataChunkTransportRemove(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	spool(t, dir, data)
+
+	transport, err := NewDataChunkTransport(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received := unspool(t, transport); !bytes.Equal(received, data) {
+		t.Fatalf("unexpected %d bytes received", len(received))
+	}
+	if n := spooled(t, dir); n != 0 {
+		t.Fatalf("%d packets are not removed", n)
+	}
+	if _, err := transport.Recv(); err != io.EOF {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	// Writer, opened on the empty directory, numbers packets from the start, thus behind the reader,
+	// which has to rescan the directory to find them
+	spool(t, dir, []byte("more data"))
+	if received := unspool(t, transport); string(received) != "more data" {
+		t.Fatalf("unexpected data %q received", received)
+	}
+	if n := spooled(t, dir); n != 0 {
+		t.Fatalf("%d packets are not removed", n)
+	}
+}
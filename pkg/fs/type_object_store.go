@@ -0,0 +1,373 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/store"
+)
+
+const (
+	// metadataDir specifies directory within the root, where metadata of the objects is stored
+	metadataDir = ".metadata"
+	// tmpDir specifies directory within the root, where objects are written before they are put in place
+	tmpDir = ".tmp"
+)
+
+// ObjectStore is an ObjectStore backed by local filesystem.
+// Each bucket is a directory within the root directory and each object is a file within the bucket's directory.
+// Slashes in object names are treated as directory separators, thus object can not be named the same
+// as a "folder" of another object in the same bucket.
+// Metadata of the objects is stored separately, under .metadata directory of the root.
+type ObjectStore struct {
+	mutex sync.RWMutex
+	root  string
+}
+
+// Ensure interface compatibility
+var _ store.ObjectStore = &ObjectStore{}
+
+// NewObjectStore creates new ObjectStore within the root directory. Directory is created in case it does not exist.
+func NewObjectStore(root string) (*ObjectStore, error) {
+	log.Tracef("fs.NewObjectStore() - start")
+	defer log.Tracef("fs.NewObjectStore() - end")
+
+	if err := os.MkdirAll(filepath.Join(root, tmpDir), 0700); err != nil {
+		return nil, err
+	}
+	return &ObjectStore{
+		root: root,
+	}, nil
+}
+
+// dataFilename builds name of the file of the object's data
+func (s *ObjectStore) dataFilename(addr *common.S3Address) (string, error) {
+	if err := checkAddress(addr); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, addr.Bucket, filepath.FromSlash(addr.Object)), nil
+}
+
+// metadataFilename builds name of the file of the object's metadata
+func (s *ObjectStore) metadataFilename(addr *common.S3Address) string {
+	return filepath.Join(s.root, metadataDir, addr.Bucket, filepath.FromSlash(addr.Object))
+}
+
+// checkAddress checks whether address is a valid address of an object within the store
+func checkAddress(addr *common.S3Address) error {
+	bucket := addr.GetBucket()
+	if (bucket == "") || strings.HasPrefix(bucket, ".") || strings.ContainsAny(bucket, `/\`) {
+		return fmt.Errorf("invalid bucket name %q", bucket)
+	}
+	object := addr.GetObject()
+	if (object == "") || strings.HasPrefix(object, "/") || (path.Clean(object) != object) ||
+		(object == "..") || strings.HasPrefix(object, "../") || strings.Contains(object, `\`) {
+		return fmt.Errorf("invalid object name %q", object)
+	}
+	return nil
+}
+
+// Put is an ObjectStore interface function.
+// Object is written under temporary name and renamed afterwards, so readers never see partially written object.
+func (s *ObjectStore) Put(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (int64, error) {
+	log.Tracef("fs.ObjectStore.Put() - start")
+	defer log.Tracef("fs.ObjectStore.Put() - end")
+
+	filename, err := s.dataFilename(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(s.root, tmpDir), "object-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, reader)
+	if err != nil {
+		_ = tmp.Close()
+		return n, err
+	}
+	if err := tmp.Close(); err != nil {
+		return n, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.writeMetadata(addr, metadata); err != nil {
+		return n, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return n, err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// writeMetadata writes metadata of the object. Metadata of the object is removed in case no metadata provided
+func (s *ObjectStore) writeMetadata(addr *common.S3Address, metadata *common.Metadata) error {
+	filename := s.metadataFilename(addr)
+	if metadata == nil {
+		if err := os.Remove(filename); (err != nil) && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	buf, err := proto.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf, 0600)
+}
+
+// readMetadata reads metadata of the object. Returns nil in case object has no metadata
+func (s *ObjectStore) readMetadata(addr *common.S3Address) *common.Metadata {
+	buf, err := os.ReadFile(s.metadataFilename(addr))
+	if err != nil {
+		return nil
+	}
+	metadata := common.NewMetadata()
+	if err := proto.Unmarshal(buf, metadata); err != nil {
+		log.Warnf("unable to unmarshal metadata of %s. err: %v", addr, err)
+		return nil
+	}
+	return metadata
+}
+
+// Get is an ObjectStore interface function
+func (s *ObjectStore) Get(addr *common.S3Address) (io.ReadCloser, error) {
+	filename, err := s.dataFilename(addr)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, store.NotFound(addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); (err == nil) && info.IsDir() {
+		_ = f.Close()
+		return nil, store.NotFound(addr)
+	}
+	return f, nil
+}
+
+// Stat is an ObjectStore interface function
+func (s *ObjectStore) Stat(addr *common.S3Address) (*store.ObjectInfo, error) {
+	filename, err := s.dataFilename(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) || ((err == nil) && info.IsDir()) {
+		return nil, store.NotFound(addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.newObjectInfo(addr, info), nil
+}
+
+// newObjectInfo builds info of the object out of info of its file
+func (s *ObjectStore) newObjectInfo(addr *common.S3Address, info fs.FileInfo) *store.ObjectInfo {
+	return &store.ObjectInfo{
+		Address:      addr,
+		Size:         info.Size(),
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+		Metadata:     s.readMetadata(addr),
+	}
+}
+
+// List is an ObjectStore interface function
+func (s *ObjectStore) List(bucket, prefix string, n int) ([]*store.ObjectInfo, error) {
+	if err := checkAddress(common.NewS3Address(bucket, "object")); err != nil {
+		return nil, err
+	}
+	bucketDir := filepath.Join(s.root, bucket)
+	// Walk the deepest directory covered by the prefix only
+	walkDir := bucketDir
+	if i := strings.LastIndex(prefix, "/"); i > 0 {
+		walkDir = filepath.Join(bucketDir, filepath.FromSlash(prefix[:i]))
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var res []*store.ObjectInfo
+	err := filepath.WalkDir(walkDir, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(bucketDir, filename)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		res = append(res, s.newObjectInfo(common.NewS3Address(bucket, key), info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Directory walk order differs from key order, since slash is not the lowest character
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Address.Object < res[j].Address.Object
+	})
+	if (n >= 0) && (len(res) > n) {
+		res = res[:n]
+	}
+	return res, nil
+}
+
+// Copy is an ObjectStore interface function
+func (s *ObjectStore) Copy(dst, src *common.S3Address) error {
+	log.Tracef("fs.ObjectStore.Copy() - start")
+	defer log.Tracef("fs.ObjectStore.Copy() - end")
+
+	reader, err := s.Get(src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	s.mutex.RLock()
+	metadata := s.readMetadata(src)
+	s.mutex.RUnlock()
+
+	_, err = s.Put(dst, reader, metadata)
+	return err
+}
+
+// Move is an ObjectStore interface function
+func (s *ObjectStore) Move(dst, src *common.S3Address) error {
+	log.Tracef("fs.ObjectStore.Move() - start")
+	defer log.Tracef("fs.ObjectStore.Move() - end")
+
+	srcFilename, err := s.dataFilename(src)
+	if err != nil {
+		return err
+	}
+	dstFilename, err := s.dataFilename(dst)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if info, err := os.Stat(srcFilename); os.IsNotExist(err) || ((err == nil) && info.IsDir()) {
+		return store.NotFound(src)
+	}
+	if dstFilename == srcFilename {
+		// Object is moved onto itself, nothing to do
+		return nil
+	}
+
+	metadata := s.readMetadata(src)
+	if err := os.MkdirAll(filepath.Dir(dstFilename), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(srcFilename, dstFilename); err != nil {
+		return err
+	}
+	if err := s.writeMetadata(dst, metadata); err != nil {
+		return err
+	}
+	// Data of the src object is moved already, remove its metadata along with directories left empty
+	return s.remove(src)
+}
+
+// Remove is an ObjectStore interface function
+func (s *ObjectStore) Remove(addr *common.S3Address) error {
+	if err := checkAddress(addr); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.remove(addr)
+}
+
+// remove removes files of the object along with directories left empty
+func (s *ObjectStore) remove(addr *common.S3Address) error {
+	filename, _ := s.dataFilename(addr)
+	if err := os.Remove(filename); (err != nil) && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(s.metadataFilename(addr)); (err != nil) && !os.IsNotExist(err) {
+		return err
+	}
+	removeEmptyDirs(filepath.Dir(filename), filepath.Join(s.root, addr.Bucket))
+	removeEmptyDirs(filepath.Dir(s.metadataFilename(addr)), filepath.Join(s.root, metadataDir, addr.Bucket))
+	return nil
+}
+
+// removeEmptyDirs removes empty directories starting with dir up to the top directory, excluding the top one
+func removeEmptyDirs(dir, top string) {
+	for (dir != top) && strings.HasPrefix(dir, top) {
+		if os.Remove(dir) != nil {
+			// Directory is not empty
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// Digest is an ObjectStore interface function
+func (s *ObjectStore) Digest(addr *common.S3Address, _type common.DigestType) (*common.Digest, error) {
+	return store.CalculateDigest(s, addr, _type)
+}
@@ -0,0 +1,72 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fs
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/store"
+)
+
+func TestObjectStoreMove(t *testing.T) {
+	s, err := NewObjectStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := common.NewS3Address("bucket", "task/in/file")
+	dst := common.NewS3Address("bucket", "task/out/file")
+	if _, err := s.Put(src, strings.NewReader("data"), common.NewMetadata().SetFilename("file")); err != nil {
+		t.Fatal(err)
+	}
+
+	// check checks the object has data and metadata put
+	check := func(addr *common.S3Address) {
+		reader, err := s.Get(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "data" {
+			t.Fatalf("unexpected data of %s: %q", addr, data)
+		}
+		info, err := s.Stat(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Metadata.GetFilename() != "file" {
+			t.Fatalf("metadata of %s is lost", addr)
+		}
+	}
+
+	if err := s.Move(src, src); err != nil {
+		t.Fatal(err)
+	}
+	check(src)
+
+	if err := s.Move(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	check(dst)
+	if _, err := s.Stat(src); !store.IsNotFound(err) {
+		t.Fatalf("moved object is still found. err: %v", err)
+	}
+}
@@ -62,7 +62,7 @@ func (ce *AdapterEntry) String() string {
 	// Call section
 	_, _ = fmt.Fprintf(b, "d:%s\n", ce.d)
 	_, _ = fmt.Fprintf(b, "endpointID:%d\n", ce.endpointID)
-	_, _ = fmt.Fprintf(b, "endpointInstanceID:%d\n", ce.endpointInstanceID)
+	_, _ = fmt.Fprintf(b, "endpointInstanceID:%s\n", ce.endpointInstanceID)
 	_, _ = fmt.Fprintf(b, "sourceID:%s\n", ce.sourceID)
 	_, _ = fmt.Fprintf(b, "contextUID:%s\n", ce.contextUID)
 	_, _ = fmt.Fprintf(b, "taskUID:%s\n", ce.taskUID)
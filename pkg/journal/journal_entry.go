@@ -78,7 +78,7 @@ func (e *Entry) String() string {
 	_, _ = fmt.Fprintf(b, "StartTime:%s\n", e.StartTime)
 
 	_, _ = fmt.Fprintf(b, "EndpointID:%d\n", e.EndpointID)
-	_, _ = fmt.Fprintf(b, "EndpointInstanceID:%d\n", e.EndpointInstanceID)
+	_, _ = fmt.Fprintf(b, "EndpointInstanceID:%s\n", e.EndpointInstanceID)
 	_, _ = fmt.Fprintf(b, "SourceID:%s\n", e.SourceID)
 	_, _ = fmt.Fprintf(b, "ContextUID:%s\n", e.ContextUID)
 	_, _ = fmt.Fprintf(b, "TaskUID:%s\n", e.TaskUID)
@@ -0,0 +1,885 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	j "encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidPath is reported in case JSONPath expression can not be parsed
+var ErrInvalidPath = errors.New("invalid JSONPath")
+
+// Path is a compiled JSONPath expression, such as $.store.book[?(@.price < 10)].title
+// Supported are:
+//   - $ root and @ current node
+//   - .name, ['name'] and ["name"] members
+//   - .* and [*] wildcards
+//   - [n] indexes, negative indexes count from the end of the array
+//   - [start:end:step] slices, with any of start, end and step optional
+//   - [a,b,...] unions of the above
+//   - .. recursive descent, such as $..name, $..* or $..[0]
+//   - [?(...)] filters with == != < <= > >= comparisons, =~ unanchored regular expression match,
+//     && || ! logical operators, parentheses, existence tests, such as [?(@.isbn)],
+//     and string, number, true, false and null literals
+//
+// Members of the objects are visited in key order, since objects are unordered.
+type Path struct {
+	expr     string
+	segments []*segment
+}
+
+// CompilePath compiles JSONPath expression. Reports ErrInvalidPath along with position of the error
+// in case expression can not be parsed.
+func CompilePath(expr string) (*Path, error) {
+	p := &parser{expr: expr}
+	p.skipSpaces()
+	if !p.consume("$") {
+		return nil, p.errorf("path has to start with $")
+	}
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return &Path{
+		expr:     expr,
+		segments: segments,
+	}, nil
+}
+
+// CompilePaths compiles set of JSONPath expressions. Reports the first expression, which can not be parsed
+func CompilePaths(exprs []string) ([]*Path, error) {
+	var paths []*Path
+	for _, expr := range exprs {
+		path, err := CompilePath(expr)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// String returns expression the path is compiled from
+func (p *Path) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.expr
+}
+
+// Evaluate evaluates the path against JSON document, decoded into interface{}, such as by encoding/json,
+// and returns values matched
+func (p *Path) Evaluate(doc interface{}) []interface{} {
+	return evaluate(p.segments, doc, doc)
+}
+
+// SelectPaths evaluates paths against JSON data and returns JSON array of the values matched by all paths,
+// in the order of the paths
+func SelectPaths(data []byte, paths []*Path) ([]byte, error) {
+	decoder := j.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as they are, such as big integers
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	res := make([]interface{}, 0)
+	for _, path := range paths {
+		res = append(res, path.Evaluate(doc)...)
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := j.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(res); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// Select evaluates JSONPath expressions against JSON data and returns JSON array of the values matched
+// by all expressions, in the order of the expressions
+func Select(data []byte, exprs ...string) ([]byte, error) {
+	paths, err := CompilePaths(exprs)
+	if err != nil {
+		return nil, err
+	}
+	return SelectPaths(data, paths)
+}
+
+/***********************/
+/*     Evaluation      */
+/***********************/
+
+// segment selects children or, in case of recursive descent, descendants of the node
+type segment struct {
+	recursive bool
+	selectors []selector
+}
+
+// selector selects children of the node
+type selector interface {
+	apply(root, node interface{}, out []interface{}) []interface{}
+}
+
+// evaluate evaluates segments starting with the start node
+func evaluate(segments []*segment, root, start interface{}) []interface{} {
+	nodes := []interface{}{start}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, node := range nodes {
+			if seg.recursive {
+				walk(node, func(descendant interface{}) {
+					next = seg.apply(root, descendant, next)
+				})
+			} else {
+				next = seg.apply(root, node, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// apply applies all selectors of the segment to the node
+func (s *segment) apply(root, node interface{}, out []interface{}) []interface{} {
+	for _, sel := range s.selectors {
+		out = sel.apply(root, node, out)
+	}
+	return out
+}
+
+// walk calls f for the node and all its descendants, depth-first
+func walk(node interface{}, f func(interface{})) {
+	f(node)
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(typed) {
+			walk(typed[key], f)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			walk(item, f)
+		}
+	}
+}
+
+// children gets children of the node
+func children(node interface{}) []interface{} {
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		res := make([]interface{}, 0, len(typed))
+		for _, key := range sortedKeys(typed) {
+			res = append(res, typed[key])
+		}
+		return res
+	case []interface{}:
+		return typed
+	}
+	return nil
+}
+
+// sortedKeys gets keys of the object in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nameSelector selects member of the object
+type nameSelector struct {
+	name string
+}
+
+func (s nameSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		if value, ok := m[s.name]; ok {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// wildcardSelector selects all children of the node
+type wildcardSelector struct{}
+
+func (s wildcardSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	return append(out, children(node)...)
+}
+
+// indexSelector selects item of the array
+type indexSelector struct {
+	index int
+}
+
+func (s indexSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	if a, ok := node.([]interface{}); ok {
+		i := s.index
+		if i < 0 {
+			i += len(a)
+		}
+		if (i >= 0) && (i < len(a)) {
+			out = append(out, a[i])
+		}
+	}
+	return out
+}
+
+// sliceSelector selects items of the array, the same way as Python slices do
+type sliceSelector struct {
+	start, end, step *int
+}
+
+func (s sliceSelector) apply(_, node interface{}, out []interface{}) []interface{} {
+	a, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+	n := len(a)
+	step := 1
+	if s.step != nil {
+		step = *s.step
+	}
+	if step == 0 {
+		return out
+	}
+	// bound normalizes index and clamps it into [min, max]
+	bound := func(i *int, def, min, max int) int {
+		if i == nil {
+			return def
+		}
+		res := *i
+		if res < 0 {
+			res += n
+		}
+		if res < min {
+			return min
+		}
+		if res > max {
+			return max
+		}
+		return res
+	}
+
+	if step > 0 {
+		for i := bound(s.start, 0, 0, n); i < bound(s.end, n, 0, n); i += step {
+			out = append(out, a[i])
+		}
+	} else {
+		for i := bound(s.start, n-1, -1, n-1); i > bound(s.end, -1, -1, n-1); i += step {
+			out = append(out, a[i])
+		}
+	}
+	return out
+}
+
+// filterSelector selects children of the node, which pass the filter
+type filterSelector struct {
+	filter filter
+}
+
+func (s filterSelector) apply(root, node interface{}, out []interface{}) []interface{} {
+	for _, child := range children(node) {
+		if s.filter.test(root, child) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+/***********************/
+/*      Filters        */
+/***********************/
+
+// filter tests the current node
+type filter interface {
+	test(root, current interface{}) bool
+}
+
+// operand provides value for comparison. Reports false in case there is no value
+type operand interface {
+	value(root, current interface{}) (interface{}, bool)
+}
+
+type orFilter struct {
+	left, right filter
+}
+
+func (f orFilter) test(root, current interface{}) bool {
+	return f.left.test(root, current) || f.right.test(root, current)
+}
+
+type andFilter struct {
+	left, right filter
+}
+
+func (f andFilter) test(root, current interface{}) bool {
+	return f.left.test(root, current) && f.right.test(root, current)
+}
+
+type notFilter struct {
+	filter filter
+}
+
+func (f notFilter) test(root, current interface{}) bool {
+	return !f.filter.test(root, current)
+}
+
+// existsFilter tests whether path matches anything
+type existsFilter struct {
+	path pathOperand
+}
+
+func (f existsFilter) test(root, current interface{}) bool {
+	return len(f.path.nodes(root, current)) > 0
+}
+
+// compareFilter compares two operands
+type compareFilter struct {
+	op          string
+	left, right operand
+	re          *regexp.Regexp
+}
+
+func (f compareFilter) test(root, current interface{}) bool {
+	l, lok := f.left.value(root, current)
+	r, rok := f.right.value(root, current)
+	switch f.op {
+	case "==":
+		return equal(l, lok, r, rok)
+	case "!=":
+		return !equal(l, lok, r, rok)
+	case "=~":
+		s, ok := l.(string)
+		return lok && ok && f.re.MatchString(s)
+	}
+
+	if !lok || !rok {
+		return false
+	}
+	c, ok := compare(normalize(l), normalize(r))
+	if !ok {
+		return false
+	}
+	switch f.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// equal checks whether values are equal. Missing values are equal to each other only
+func equal(l interface{}, lok bool, r interface{}, rok bool) bool {
+	if !lok || !rok {
+		return lok == rok
+	}
+	return reflect.DeepEqual(normalize(l), normalize(r))
+}
+
+// compare compares two numbers or two strings. Reports false in case values are not comparable
+func compare(l, r interface{}) (int, bool) {
+	switch lt := l.(type) {
+	case float64:
+		if rt, ok := r.(float64); ok {
+			switch {
+			case lt < rt:
+				return -1, true
+			case lt > rt:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if rt, ok := r.(string); ok {
+			return strings.Compare(lt, rt), true
+		}
+	}
+	return 0, false
+}
+
+// normalize converts numbers decoded as json.Number to float64, so they can be compared with literals
+func normalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case j.Number:
+		if f, err := typed.Float64(); err == nil {
+			return f
+		}
+		return typed.String()
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			res[key] = normalize(item)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(typed))
+		for i, item := range typed {
+			res[i] = normalize(item)
+		}
+		return res
+	}
+	return value
+}
+
+// literal is a constant operand
+type literal struct {
+	v interface{}
+}
+
+func (o literal) value(_, _ interface{}) (interface{}, bool) {
+	return o.v, true
+}
+
+// pathOperand is a path operand, relative to either the current node or the root
+type pathOperand struct {
+	absolute bool
+	segments []*segment
+}
+
+// nodes gets nodes the path matches
+func (o pathOperand) nodes(root, current interface{}) []interface{} {
+	start := current
+	if o.absolute {
+		start = root
+	}
+	return evaluate(o.segments, root, start)
+}
+
+// value gets value of the path. Path has value only in case it matches exactly one node
+func (o pathOperand) value(root, current interface{}) (interface{}, bool) {
+	if nodes := o.nodes(root, current); len(nodes) == 1 {
+		return nodes[0], true
+	}
+	return nil, false
+}
+
+/***********************/
+/*       Parser        */
+/***********************/
+
+// parser parses JSONPath expression
+type parser struct {
+	expr string
+	pos  int
+}
+
+// errorf builds error at the current position
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w %q at %d: %s", ErrInvalidPath, p.expr, p.pos, fmt.Sprintf(format, args...))
+}
+
+// eof checks whether the whole expression is parsed
+func (p *parser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+// peek gets current byte. Returns 0 at the end of the expression
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+// skipSpaces skips whitespace
+func (p *parser) skipSpaces() {
+	for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+		p.pos++
+	}
+}
+
+// consume consumes s in case expression continues with it
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// parseSegments parses segments following $ or @
+func (p *parser) parseSegments() ([]*segment, error) {
+	var segments []*segment
+	for {
+		seg := &segment{}
+		switch {
+		case p.consume(".."):
+			seg.recursive = true
+			if p.peek() == '[' {
+				selectors, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = selectors
+				break
+			}
+			fallthrough
+		case p.consume("."):
+			if p.consume("*") {
+				seg.selectors = []selector{wildcardSelector{}}
+				break
+			}
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = []selector{nameSelector{name: name}}
+		case p.peek() == '[':
+			selectors, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = selectors
+		default:
+			return segments, nil
+		}
+		segments = append(segments, seg)
+	}
+}
+
+// parseName parses member name of dot notation
+func (p *parser) parseName() (string, error) {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && (r != '_') && (r != '-') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.errorf("member name expected")
+	}
+	return p.expr[start:p.pos], nil
+}
+
+// parseBracket parses bracket notation with union of selectors
+func (p *parser) parseBracket() ([]selector, error) {
+	p.consume("[")
+	var selectors []selector
+	for {
+		p.skipSpaces()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		p.skipSpaces()
+		switch {
+		case p.consume(","):
+			continue
+		case p.consume("]"):
+			return selectors, nil
+		}
+		return nil, p.errorf("expected , or ]")
+	}
+}
+
+// parseSelector parses one selector of bracket notation
+func (p *parser) parseSelector() (selector, error) {
+	switch p.peek() {
+	case '\'', '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector{name: name}, nil
+	case '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case '?':
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{filter: f}, nil
+	}
+
+	start, hasStart, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.consume(":") {
+		if !hasStart {
+			return nil, p.errorf("selector expected")
+		}
+		return indexSelector{index: start}, nil
+	}
+
+	s := sliceSelector{}
+	if hasStart {
+		s.start = &start
+	}
+	p.skipSpaces()
+	if end, ok, err := p.parseInt(); err != nil {
+		return nil, err
+	} else if ok {
+		s.end = &end
+	}
+	p.skipSpaces()
+	if p.consume(":") {
+		p.skipSpaces()
+		if step, ok, err := p.parseInt(); err != nil {
+			return nil, err
+		} else if ok {
+			s.step = &step
+		}
+	}
+	return s, nil
+}
+
+// parseInt parses optional integer
+func (p *parser) parseInt() (int, bool, error) {
+	start := p.pos
+	p.consume("-")
+	for !p.eof() && (p.peek() >= '0') && (p.peek() <= '9') {
+		p.pos++
+	}
+	if (p.pos == start) || (p.expr[start:p.pos] == "-") {
+		p.pos = start
+		return 0, false, nil
+	}
+	i, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid integer %s", p.expr[start:p.pos])
+	}
+	return i, true, nil
+}
+
+// parseString parses single- or double-quoted string with JSON escapes
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	b := &strings.Builder{}
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			escaped := p.peek()
+			p.pos++
+			switch escaped {
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.expr) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 16)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				p.pos += 4
+			case '\\', '/', '\'', '"':
+				b.WriteByte(escaped)
+			default:
+				return "", p.errorf("invalid escape \\%c", escaped)
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseOr parses filter expression of || operators
+func (p *parser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consume("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left: left, right: right}
+	}
+}
+
+// parseAnd parses filter expression of && operators
+func (p *parser) parseAnd() (filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consume("&&") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left: left, right: right}
+	}
+}
+
+// parseUnary parses negation, parenthesized expression or comparison
+func (p *parser) parseUnary() (filter, error) {
+	p.skipSpaces()
+	switch {
+	case p.consume("!"):
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{filter: f}, nil
+	case p.consume("("):
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return f, nil
+	}
+	return p.parseComparison()
+}
+
+// comparisonOperators lists comparison operators, longer ones first
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+// parseComparison parses comparison or existence test
+func (p *parser) parseComparison() (filter, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	op := ""
+	for _, candidate := range comparisonOperators {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		if path, ok := left.(pathOperand); ok {
+			return existsFilter{path: path}, nil
+		}
+		return nil, p.errorf("comparison operator expected")
+	}
+
+	p.skipSpaces()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	f := compareFilter{op: op, left: left, right: right}
+	if op == "=~" {
+		lit, _ := right.(literal)
+		pattern, ok := lit.v.(string)
+		if !ok {
+			return nil, p.errorf("regular expression string expected")
+		}
+		if f.re, err = regexp.Compile(pattern); err != nil {
+			return nil, p.errorf("invalid regular expression: %v", err)
+		}
+	}
+	return f, nil
+}
+
+// parseOperand parses path or literal
+func (p *parser) parseOperand() (operand, error) {
+	p.skipSpaces()
+	c := p.peek()
+	switch {
+	case (c == '@') || (c == '$'):
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return pathOperand{absolute: c == '$', segments: segments}, nil
+	case (c == '\'') || (c == '"'):
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literal{v: s}, nil
+	case (c == '-') || ((c >= '0') && (c <= '9')):
+		return p.parseNumber()
+	case p.consume("true"):
+		return literal{v: true}, nil
+	case p.consume("false"):
+		return literal{v: false}, nil
+	case p.consume("null"):
+		return literal{v: nil}, nil
+	}
+	return nil, p.errorf("operand expected")
+}
+
+// parseNumber parses number literal
+func (p *parser) parseNumber() (operand, error) {
+	start := p.pos
+	for !p.eof() && (strings.IndexByte("+-.eE0123456789", p.peek()) >= 0) {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %s", p.expr[start:p.pos])
+	}
+	return literal{v: f}, nil
+}
@@ -0,0 +1,238 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+const store = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"expensive": 10
+}`
+
+const items = `{
+	"items": [
+		{"id": 1, "ok": true, "v": null, "name": "a b"},
+		{"id": 2, "ok": false, "big": 12345678901234567890},
+		{"id": -3, "ok": true, "v": "x", "name": "ünïcode"}
+	]
+}`
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		expr string
+		want string
+	}{
+		// Root, members and dot notation
+		{name: "root", data: `{"a":1}`, expr: "$", want: `[{"a":1}]`},
+		{name: "member", data: store, expr: "$.expensive", want: `[10]`},
+		{name: "nested members", data: store, expr: "$.store.bicycle.color", want: `["red"]`},
+		{name: "bracket members", data: store, expr: `$['store']["bicycle"]['color']`, want: `["red"]`},
+		{name: "escaped bracket member", data: `{"a'b":1,"é":2}`, expr: `$['a\'b', "é"]`, want: `[1,2]`},
+		{name: "unicode member", data: `{"ключ":1}`, expr: "$.ключ", want: `[1]`},
+		{name: "missing member", data: store, expr: "$.nothing", want: `[]`},
+		{name: "member of array", data: store, expr: "$.store.book.title", want: `[]`},
+		{name: "spaces", data: store, expr: " $.store.book[ 0 , 1 ].price ", want: `[8.95,12.99]`},
+
+		// Wildcards, members are visited in key order
+		{name: "dot wildcard", data: store, expr: "$.store.bicycle.*", want: `["red",19.95]`},
+		{name: "bracket wildcard", data: store, expr: "$.store.book[*].author",
+			want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{name: "wildcard of scalar", data: store, expr: "$.expensive.*", want: `[]`},
+
+		// Indexes
+		{name: "index", data: store, expr: "$.store.book[0].title", want: `["Sayings of the Century"]`},
+		{name: "negative index", data: store, expr: "$.store.book[-1].title", want: `["The Lord of the Rings"]`},
+		{name: "index out of range", data: store, expr: "$.store.book[4].title", want: `[]`},
+		{name: "negative index out of range", data: store, expr: "$.store.book[-5].title", want: `[]`},
+		{name: "index of object", data: store, expr: "$.store.bicycle[0]", want: `[]`},
+
+		// Slices
+		{name: "slice", data: store, expr: "$.store.book[1:3].price", want: `[12.99,8.99]`},
+		{name: "slice without start", data: store, expr: "$.store.book[:2].price", want: `[8.95,12.99]`},
+		{name: "slice without end", data: store, expr: "$.store.book[-2:].price", want: `[8.99,22.99]`},
+		{name: "slice with step", data: store, expr: "$.store.book[::2].price", want: `[8.95,8.99]`},
+		{name: "slice with negative step", data: store, expr: "$.store.book[::-1].price", want: `[22.99,8.99,12.99,8.95]`},
+		{name: "slice with negative step and bounds", data: store, expr: "$.store.book[2:0:-1].price", want: `[8.99,12.99]`},
+		{name: "slice with zero step", data: store, expr: "$.store.book[::0]", want: `[]`},
+		{name: "slice out of range", data: store, expr: "$.store.book[10:20]", want: `[]`},
+
+		// Unions
+		{name: "union of indexes", data: store, expr: "$.store.book[0,-1].price", want: `[8.95,22.99]`},
+		{name: "union of members", data: store, expr: "$.store.bicycle['price','color']", want: `[19.95,"red"]`},
+		{name: "union of slice and index", data: store, expr: "$.store.book[:1,3].price", want: `[8.95,22.99]`},
+
+		// Recursive descent
+		{name: "recursive member", data: store, expr: "$..author",
+			want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{name: "recursive member order", data: store, expr: "$..price", want: `[19.95,8.95,12.99,8.99,22.99]`},
+		{name: "recursive wildcard", data: `{"a":{"b":[1,{"c":2}]}}`, expr: "$..*", want: `[{"b":[1,{"c":2}]},[1,{"c":2}],1,{"c":2},2]`},
+		{name: "recursive bracket", data: store, expr: "$..[0].title", want: `["Sayings of the Century"]`},
+		{name: "recursive after member", data: store, expr: "$.store.bicycle..*", want: `["red",19.95]`},
+
+		// Filters with comparisons
+		{name: "filter <", data: store, expr: "$.store.book[?(@.price < 10)].title", want: `["Sayings of the Century","Moby Dick"]`},
+		{name: "filter <=", data: store, expr: "$.store.book[?(@.price <= 8.99)].price", want: `[8.95,8.99]`},
+		{name: "filter >", data: store, expr: "$.store.book[?(@.price > 20)].price", want: `[22.99]`},
+		{name: "filter >=", data: store, expr: "$.store.book[?(@.price >= 12.99)].price", want: `[12.99,22.99]`},
+		{name: "filter ==", data: store, expr: "$.store.book[?(@.category == 'reference')].author", want: `["Nigel Rees"]`},
+		{name: "filter !=", data: store, expr: `$.store.book[?(@.category != "reference")].price`, want: `[12.99,8.99,22.99]`},
+		{name: "filter string order", data: store, expr: "$.store.book[?(@.author < 'F')].price", want: `[12.99]`},
+		{name: "filter of different types", data: store, expr: "$.store.book[?(@.price < 'F')]", want: `[]`},
+		{name: "filter literal on the left", data: store, expr: "$.store.book[?(10 > @.price)].price", want: `[8.95,8.99]`},
+		{name: "filter root reference", data: store, expr: "$.store.book[?(@.price > $.expensive)].price", want: `[12.99,22.99]`},
+		{name: "filter of object members", data: store, expr: "$.store[?(@.color == 'red')].price", want: `[19.95]`},
+		{name: "filter unanchored match", data: store, expr: "$.store.book[?(@.author =~ 'Tolk')].price", want: `[22.99]`},
+		{name: "filter anchored match", data: store, expr: "$.store.book[?(@.author =~ '^E.*h$')].price", want: `[12.99]`},
+		{name: "filter match of number", data: store, expr: "$.store.book[?(@.price =~ '8')]", want: `[]`},
+
+		// Filters with logical operators and existence tests
+		{name: "filter &&", data: store, expr: "$.store.book[?(@.category == 'fiction' && @.price < 10)].price", want: `[8.99]`},
+		{name: "filter ||", data: store, expr: "$.store.book[?(@.price < 9 || @.price > 20)].price", want: `[8.95,8.99,22.99]`},
+		{name: "filter !", data: store, expr: "$.store.book[?(!(@.price < 10))].price", want: `[12.99,22.99]`},
+		{name: "filter precedence", data: store, expr: "$.store.book[?(@.price > 20 || @.isbn && @.price < 10)].price", want: `[8.99,22.99]`},
+		{name: "filter parentheses", data: store, expr: "$.store.book[?((@.price > 20 || @.isbn) && @.price < 10)].price", want: `[8.99]`},
+		{name: "filter existence", data: store, expr: "$.store.book[?(@.isbn)].price", want: `[8.99,22.99]`},
+		{name: "filter non-existence", data: store, expr: "$.store.book[?(!@.isbn)].price", want: `[8.95,12.99]`},
+		{name: "filter recursive", data: store, expr: "$..[?(@.price > 19)].price", want: `[19.95,22.99]`},
+
+		// Filters with literals
+		{name: "filter true", data: items, expr: "$.items[?(@.ok == true)].id", want: `[1,-3]`},
+		{name: "filter false", data: items, expr: "$.items[?(@.ok == false)].id", want: `[2]`},
+		{name: "filter null", data: items, expr: "$.items[?(@.v == null)].id", want: `[1]`},
+		{name: "filter missing", data: items, expr: "$.items[?(@.v != null)].id", want: `[2,-3]`},
+		{name: "filter negative number", data: items, expr: "$.items[?(@.id < -1)].id", want: `[-3]`},
+		{name: "filter exponent", data: items, expr: "$.items[?(@.id >= 2e0)].id", want: `[2]`},
+		{name: "filter string with spaces", data: items, expr: `$.items[?(@.name == "a b")].id`, want: `[1]`},
+		{name: "filter unicode string", data: items, expr: `$.items[?(@.name == 'ünïcode')].id`, want: `[-3]`},
+		{name: "big number is kept", data: items, expr: "$.items[?(@.big)].big", want: `[12345678901234567890]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Select([]byte(test.data), test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("%s\n got: %s\nwant: %s", test.expr, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSelectMultiplePaths(t *testing.T) {
+	got, err := Select([]byte(store), "$.store.bicycle.color", "$.expensive", "$.nothing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["red",10]`; string(got) != want {
+		t.Fatalf("got: %s want: %s", got, want)
+	}
+}
+
+func TestSelectInvalidJSON(t *testing.T) {
+	_, err := Select([]byte(`{"a":`), "$.a")
+	if err == nil {
+		t.Fatal("invalid JSON is selected")
+	}
+	if errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("invalid JSON is reported as invalid path: %v", err)
+	}
+}
+
+func TestCompilePathInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: ""},
+		{name: "no root", expr: "store.book"},
+		{name: "current node as root", expr: "@.store"},
+		{name: "trailing dot", expr: "$."},
+		{name: "invalid member", expr: "$.+"},
+		{name: "trailing garbage", expr: "$.store book"},
+		{name: "unterminated bracket", expr: "$.store[0"},
+		{name: "empty bracket", expr: "$.store[]"},
+		{name: "empty union item", expr: "$.store[0,]"},
+		{name: "unterminated string", expr: "$['store"},
+		{name: "unterminated escape", expr: `$['store\`},
+		{name: "invalid escape", expr: `$['\q']`},
+		{name: "invalid unicode escape", expr: `$['\u12']`},
+		{name: "non-hex unicode escape", expr: `$['\uzzzz']`},
+		{name: "integer overflow", expr: "$[99999999999999999999]"},
+		{name: "unterminated filter", expr: "$[?(@.a == 1]"},
+		{name: "filter without operand", expr: "$[?()]"},
+		{name: "filter without right operand", expr: "$[?(@.a == )]"},
+		{name: "filter without operator", expr: "$[?(1)]"},
+		{name: "filter with unknown operator", expr: "$[?(@.a === 1)]"},
+		{name: "filter invalid number", expr: "$[?(@.a == 1.2.3)]"},
+		{name: "filter regular expression not string", expr: "$[?(@.a =~ 1)]"},
+		{name: "filter regular expression path", expr: "$[?(@.a =~ @.b)]"},
+		{name: "filter invalid regular expression", expr: "$[?(@.a =~ '(')]"},
+		{name: "filter dangling &&", expr: "$[?(@.a &&)]"},
+		{name: "filter dangling ||", expr: "$[?(@.a ||)]"},
+		{name: "filter dangling !", expr: "$[?(!)]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := CompilePath(test.expr)
+			if !errors.Is(err, ErrInvalidPath) {
+				t.Fatalf("%q is compiled into %v, err: %v", test.expr, path, err)
+			}
+		})
+	}
+}
+
+func TestCompilePaths(t *testing.T) {
+	paths, err := CompilePaths([]string{"$.a", "$.b[0]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (len(paths) != 2) || (paths[0].String() != "$.a") || (paths[1].String() != "$.b[0]") {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+
+	if _, err := CompilePaths([]string{"$.a", "$.b[", "$.c"}); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("unexpected err %v", err)
+	}
+}
+
+func TestPathEvaluate(t *testing.T) {
+	path, err := CompilePath("$.a[?(@.n > 1)].n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"n": 1.0},
+			map[string]interface{}{"n": 2.0},
+		},
+	}
+	if got := path.Evaluate(doc); (len(got) != 1) || (got[0] != 2.0) {
+		t.Fatalf("unexpected values %v", got)
+	}
+}
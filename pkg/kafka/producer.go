@@ -130,14 +130,22 @@ func (p *Producer) Close() {
 
 // Send
 func (p *Producer) Send(data []byte) error {
+	return p.SendMessage(nil, nil, data)
+}
+
+// SendMessage sends data with the key and headers. Messages with the same key land in the same partition,
+// thus are consumed in the same order as sent. Headers are relayed to consumer.
+func (p *Producer) SendMessage(key []byte, headers []sarama.RecordHeader, data []byte) error {
 
 	msg := &sarama.ProducerMessage{
-		Topic: p.address.Topic,
-		Value: sarama.ByteEncoder(data),
-		// Key
-		// Headers - relayed to consumer
+		Topic:   p.address.Topic,
+		Value:   sarama.ByteEncoder(data),
+		Headers: headers,
 		// Metadata - relayed to the Successes and Errors channels
 	}
+	if key != nil {
+		msg.Key = sarama.ByteEncoder(key)
+	}
 
 	_, _, err := p.producer.SendMessage(msg)
 	if err != nil {
@@ -16,20 +16,40 @@ package kafka
 
 import (
 	"io"
+	"strconv"
 
+	"github.com/Shopify/sarama"
 	"github.com/golang/protobuf/proto"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/sunsingerus/tbox/pkg/api/common"
 )
 
+// Headers of the messages of the data chunk stream
+const (
+	// HeaderStreamUUID specifies UUID of the stream the message belongs to. The same UUID is used as the message key
+	HeaderStreamUUID = "stream_uuid"
+	// HeaderOffset specifies offset of the data chunk within the stream
+	HeaderOffset = "offset"
+	// HeaderLast specifies whether the data chunk is the last one of the stream
+	HeaderLast = "last"
+	// HeaderFilename specifies filename from payload metadata. Provided with the first data chunk only
+	HeaderFilename = "filename"
+)
+
 // DataChunkTransport defines transport level interface
 // Has the following functions:
 //   Send(*DataChunk) error
 //   Recv() (*DataChunk, error)
-
+//
+// Each message sent is keyed by UUID of the stream, thus all messages of the stream land in the same partition
+// and multiple streams can be sent into the same topic concurrently. Stream description is provided in headers.
+// Receiving transport with stream UUID specified skips messages of other streams.
+// See StreamDemux to receive multiple interleaved streams.
 type DataChunkTransport struct {
 	Transport
+	// stream specifies UUID of the stream
+	stream *common.UUID
 }
 
 // NewDataChunkTransport
@@ -38,7 +58,7 @@ func NewDataChunkTransport(producer *Producer, consumer *Consumer, close bool) *
 	defer log.Infof("kafka.NewDataChunkTransport() - end")
 
 	return &DataChunkTransport{
-		Transport{
+		Transport: Transport{
 			producer: producer,
 			consumer: consumer,
 			close:    close,
@@ -46,16 +66,46 @@ func NewDataChunkTransport(producer *Producer, consumer *Consumer, close bool) *
 	}
 }
 
+// SetStreamUUID sets UUID of the stream. Random UUID is generated on the first send in case none specified
+func (t *DataChunkTransport) SetStreamUUID(stream *common.UUID) *DataChunkTransport {
+	if t == nil {
+		return nil
+	}
+	t.stream = stream
+	return t
+}
+
+// GetStreamUUID gets UUID of the stream
+func (t *DataChunkTransport) GetStreamUUID() *common.UUID {
+	if t == nil {
+		return nil
+	}
+	return t.stream
+}
+
 // Send
 func (t *DataChunkTransport) Send(dataChunk *common.DataPacket) error {
 	log.Infof("kafka.DataChunkTransport.Send() - start")
 	defer log.Infof("kafka.DataChunkTransport.Send() - end")
 
-	if buf, err := proto.Marshal(dataChunk); err == nil {
-		return t.producer.Send(buf)
-	} else {
+	buf, err := proto.Marshal(dataChunk)
+	if err != nil {
 		return err
 	}
+
+	if t.stream == nil {
+		t.stream = common.NewUuidRandom()
+	}
+	key := []byte(t.stream.String())
+	headers := []sarama.RecordHeader{
+		{Key: []byte(HeaderStreamUUID), Value: key},
+		{Key: []byte(HeaderOffset), Value: []byte(strconv.FormatInt(dataChunk.GetOffset(), 10))},
+		{Key: []byte(HeaderLast), Value: []byte(strconv.FormatBool(dataChunk.GetLast()))},
+	}
+	if filename := dataChunk.GetPayloadMetadata().GetFilename(); filename != "" {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(HeaderFilename), Value: []byte(filename)})
+	}
+	return t.producer.SendMessage(key, headers, buf)
 }
 
 // Recv
@@ -63,11 +113,27 @@ func (t *DataChunkTransport) Recv() (*common.DataPacket, error) {
 	log.Infof("kafka.DataChunkTransport.Recv() - start")
 	defer log.Infof("kafka.DataChunkTransport.Recv() - end")
 
-	msg := t.consumer.Recv()
-	if msg == nil {
-		// TODO not sure
-		return nil, io.EOF
+	for {
+		msg := t.consumer.Recv()
+		if msg == nil {
+			// TODO not sure
+			return nil, io.EOF
+		}
+		if (t.stream != nil) && (string(msg.Key) != t.stream.String()) {
+			// Message of another stream
+			continue
+		}
+		chunk := common.NewDataPacket()
+		return chunk, proto.Unmarshal(msg.Value, chunk)
+	}
+}
+
+// GetHeader gets value of the header of the message. Returns empty string in case no header found
+func GetHeader(msg *sarama.ConsumerMessage, key string) string {
+	for _, header := range msg.Headers {
+		if (header != nil) && (string(header.Key) == key) {
+			return string(header.Value)
+		}
 	}
-	chunk := common.NewDataPacket()
-	return chunk, proto.Unmarshal(msg.Value, chunk)
+	return ""
 }
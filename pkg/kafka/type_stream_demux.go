@@ -0,0 +1,366 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+var (
+	// ErrStreamTimeout is reported by the stream in case no data chunks received within timeout
+	ErrStreamTimeout = errors.New("stream timeout")
+	// ErrStreamDemuxClosed is reported by streams left open on StreamDemux close
+	ErrStreamDemuxClosed = errors.New("stream demux closed")
+)
+
+const (
+	// DefaultStreamCapacity specifies how many data chunks each stream keeps, which are not received yet
+	DefaultStreamCapacity = 64
+	// finishedMax specifies how many finished streams are remembered at most
+	finishedMax = 4096
+)
+
+// StreamDemuxHandler is called for each new stream in a separate goroutine.
+// Stream is expected to be read till io.EOF or an error.
+type StreamDemuxHandler func(stream *DemuxStream)
+
+// StreamDemux demultiplexes messages of interleaved data chunk streams, as sent by DataChunkTransport.
+// Messages are routed into streams by the message key, which is UUID of the stream.
+// Stream with no data chunks received within timeout is considered abandoned and is failed with ErrStreamTimeout.
+// Feed blocks while the stream the message belongs to keeps capacity of data chunks, which are not received yet,
+// thus slow stream slows down consumption of all streams, instead of piling data chunks up in memory.
+type StreamDemux struct {
+	mutex    sync.Mutex
+	timeout  time.Duration
+	capacity int
+	handler  StreamDemuxHandler
+	// streams are the streams being received, by UUID
+	streams map[string]*DemuxStream
+	// finished are the streams completed or failed, by UUID, with the time of completion.
+	// Late messages of finished streams are dropped.
+	finished map[string]time.Time
+	// finishedOrder lists finished streams in order of completion, so the oldest ones are forgotten first
+	finishedOrder []finishedStream
+	done          chan struct{}
+	closed        bool
+}
+
+// finishedStream is a stream completed or failed at the time
+type finishedStream struct {
+	key string
+	at  time.Time
+}
+
+// NewStreamDemux creates new StreamDemux. Zero timeout means streams never time out
+func NewStreamDemux(timeout time.Duration, handler StreamDemuxHandler) *StreamDemux {
+	log.Infof("kafka.NewStreamDemux() - start")
+	defer log.Infof("kafka.NewStreamDemux() - end")
+
+	d := &StreamDemux{
+		timeout:  timeout,
+		capacity: DefaultStreamCapacity,
+		handler:  handler,
+		streams:  make(map[string]*DemuxStream),
+		finished: make(map[string]time.Time),
+		done:     make(chan struct{}),
+	}
+	if timeout > 0 {
+		go d.janitor()
+	}
+	return d
+}
+
+// SetStreamCapacity sets how many data chunks each stream keeps, which are not received yet.
+// Applies to streams started afterwards
+func (d *StreamDemux) SetStreamCapacity(capacity int) *StreamDemux {
+	if d == nil {
+		return nil
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.capacity = capacity
+	return d
+}
+
+// Feed routes the message into the stream it belongs to. New stream is started in case of unknown stream UUID.
+// Blocks while the stream keeps capacity of data chunks, which are not received yet.
+func (d *StreamDemux) Feed(msg *sarama.ConsumerMessage) {
+	if msg == nil {
+		return
+	}
+	key := string(msg.Key)
+	if key == "" {
+		log.Warnf("kafka.StreamDemux.Feed() - message without stream UUID dropped. %s", MsgAddressPrintable(msg))
+		return
+	}
+
+	packet := common.NewDataPacket()
+	if err := proto.Unmarshal(msg.Value, packet); err != nil {
+		log.Warnf("kafka.StreamDemux.Feed() - unable to unmarshal message. %s err: %v", MsgAddressPrintable(msg), err)
+		return
+	}
+
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return
+	}
+	if _, ok := d.finished[key]; ok {
+		d.mutex.Unlock()
+		log.Warnf("kafka.StreamDemux.Feed() - message of finished stream %s dropped", key)
+		return
+	}
+	stream, ok := d.streams[key]
+	if !ok {
+		stream = newDemuxStream(common.NewUuidFromString(key), msg.Headers, d.capacity)
+		d.streams[key] = stream
+	}
+	if packet.GetLast() {
+		delete(d.streams, key)
+		d.finish(key, time.Now())
+	}
+	d.mutex.Unlock()
+
+	stream.push(packet)
+	if !ok && (d.handler != nil) {
+		go d.handler(stream)
+	}
+}
+
+// ConsumeMessage feeds the message. Compatible with ConsumeMessageFunction, thus can be used with ConsumerGroup
+func (d *StreamDemux) ConsumeMessage(_ context.Context, msg *sarama.ConsumerMessage) bool {
+	d.Feed(msg)
+	return true
+}
+
+// Run feeds messages received by the consumer, till the consumer is closed
+func (d *StreamDemux) Run(consumer *Consumer) {
+	log.Infof("kafka.StreamDemux.Run() - start")
+	defer log.Infof("kafka.StreamDemux.Run() - end")
+
+	for {
+		msg := consumer.Recv()
+		if msg == nil {
+			return
+		}
+		d.Feed(msg)
+	}
+}
+
+// Close fails all streams being received with ErrStreamDemuxClosed
+func (d *StreamDemux) Close() {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.done)
+	streams := d.streams
+	d.streams = make(map[string]*DemuxStream)
+	d.mutex.Unlock()
+
+	for _, stream := range streams {
+		stream.fail(ErrStreamDemuxClosed)
+	}
+}
+
+// finish remembers the stream as finished at the time, in order to drop late messages, such as redelivered ones.
+// Only finishedMax streams finished the last are remembered, so streams are not remembered forever,
+// even though janitor, which forgets them after timeout, does not run.
+// Expected to be called under the mutex
+func (d *StreamDemux) finish(key string, at time.Time) {
+	d.finished[key] = at
+	d.finishedOrder = append(d.finishedOrder, finishedStream{key: key, at: at})
+	for len(d.finishedOrder) > finishedMax {
+		d.forgetOldest()
+	}
+}
+
+// forgetOldest forgets the stream finished the first. Expected to be called under the mutex
+func (d *StreamDemux) forgetOldest() {
+	oldest := d.finishedOrder[0]
+	d.finishedOrder[0] = finishedStream{}
+	d.finishedOrder = d.finishedOrder[1:]
+	if at, ok := d.finished[oldest.key]; ok && at.Equal(oldest.at) {
+		delete(d.finished, oldest.key)
+	}
+}
+
+// janitor fails abandoned streams and forgets finished ones
+func (d *StreamDemux) janitor() {
+	ticker := time.NewTicker(d.timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case now := <-ticker.C:
+			d.expire(now)
+		}
+	}
+}
+
+// expire fails streams with no data chunks received within timeout
+func (d *StreamDemux) expire(now time.Time) {
+	var expired []*DemuxStream
+
+	d.mutex.Lock()
+	for key, stream := range d.streams {
+		if now.Sub(stream.getUpdated()) > d.timeout {
+			expired = append(expired, stream)
+			delete(d.streams, key)
+			d.finish(key, now)
+		}
+	}
+	// Finished streams are remembered for a while, in order to drop late messages, such as redelivered ones
+	for (len(d.finishedOrder) > 0) && (now.Sub(d.finishedOrder[0].at) > d.timeout) {
+		d.forgetOldest()
+	}
+	d.mutex.Unlock()
+
+	for _, stream := range expired {
+		log.Warnf("kafka.StreamDemux - stream %s timed out", stream.GetUUID())
+		stream.fail(ErrStreamTimeout)
+	}
+}
+
+// DemuxStream is a stream of data chunks demultiplexed by StreamDemux
+type DemuxStream struct {
+	uuid    *common.UUID
+	headers []*sarama.RecordHeader
+
+	mutex sync.Mutex
+	// cond signals both data chunks pushed and data chunks received
+	cond     *sync.Cond
+	packets  []*common.DataPacket
+	capacity int
+	err      error
+	updated  time.Time
+}
+
+// Ensure interface compatibility
+var _ common.DataPacketReader = &DemuxStream{}
+
+// newDemuxStream creates new DemuxStream
+func newDemuxStream(uuid *common.UUID, headers []*sarama.RecordHeader, capacity int) *DemuxStream {
+	s := &DemuxStream{
+		uuid:     uuid,
+		headers:  headers,
+		capacity: capacity,
+		updated:  time.Now(),
+	}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
+}
+
+// GetUUID gets UUID of the stream
+func (s *DemuxStream) GetUUID() *common.UUID {
+	if s == nil {
+		return nil
+	}
+	return s.uuid
+}
+
+// GetHeader gets header of the first message of the stream. Returns empty string in case no header found
+func (s *DemuxStream) GetHeader(key string) string {
+	if s == nil {
+		return ""
+	}
+	return GetHeader(&sarama.ConsumerMessage{Headers: s.headers}, key)
+}
+
+// GetFilename gets filename of the stream, as provided in headers
+func (s *DemuxStream) GetFilename() string {
+	return s.GetHeader(HeaderFilename)
+}
+
+// Recv receives the next data chunk of the stream. Blocks till data chunk is available.
+// Returns io.EOF after the last data chunk of the stream is received.
+func (s *DemuxStream) Recv() (*common.DataPacket, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for (len(s.packets) == 0) && (s.err == nil) {
+		s.cond.Wait()
+	}
+	if len(s.packets) > 0 {
+		packet := s.packets[0]
+		s.packets[0] = nil
+		s.packets = s.packets[1:]
+		// Wake up push waiting for capacity
+		s.cond.Broadcast()
+		return packet, nil
+	}
+	return nil, s.err
+}
+
+// Open opens the stream as a file. Options are expected to match the options the stream is sent with
+func (s *DemuxStream) Open(options *common.DataPacketFileOptions) (*common.DataPacketFileWithOptions, error) {
+	return common.OpenDataPacketFileWOptions(nil, s, options)
+}
+
+// push appends data chunk to the stream. Blocks while the stream keeps capacity of data chunks, which are not
+// received yet, till either data chunks are received or the stream fails. The last data chunk is appended anyway,
+// since finished stream can not be failed by timeout anymore.
+func (s *DemuxStream) push(packet *common.DataPacket) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for (len(s.packets) >= s.capacity) && (s.err == nil) && !packet.GetLast() {
+		s.cond.Wait()
+	}
+	if s.err != nil {
+		return
+	}
+	s.packets = append(s.packets, packet)
+	s.updated = time.Now()
+	if packet.GetLast() {
+		s.err = io.EOF
+	}
+	s.cond.Broadcast()
+}
+
+// fail fails the stream with the error. Data chunks received already are still available
+func (s *DemuxStream) fail(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	s.err = err
+	s.cond.Broadcast()
+}
+
+// getUpdated gets time of the last data chunk received
+func (s *DemuxStream) getUpdated() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.updated
+}
@@ -0,0 +1,146 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// newStreamMessage creates message of the stream with data chunk
+func newStreamMessage(t *testing.T, key string, data string, last bool) *sarama.ConsumerMessage {
+	packet := common.NewDataPacket()
+	packet.SetData([]byte(data))
+	packet.SetLast(last)
+	value, err := proto.Marshal(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &sarama.ConsumerMessage{Key: []byte(key), Value: value}
+}
+
+func TestStreamDemuxBackpressure(t *testing.T) {
+	streams := make(chan *DemuxStream, 1)
+	d := NewStreamDemux(0, func(stream *DemuxStream) {
+		streams <- stream
+	}).SetStreamCapacity(2)
+	defer d.Close()
+	key := common.NewUuidRandom().String()
+
+	// Feed blocks on the third data chunk, which is over capacity, till data chunk is received
+	fed := make(chan struct{})
+	go func() {
+		for _, data := range []string{"1", "2", "3"} {
+			d.Feed(newStreamMessage(t, key, data, false))
+		}
+		d.Feed(newStreamMessage(t, key, "4", true))
+		close(fed)
+	}()
+	stream := <-streams
+	select {
+	case <-fed:
+		t.Fatal("feed is not blocked")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	for _, data := range []string{"1", "2", "3", "4"} {
+		packet, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(packet.GetData()) != data {
+			t.Fatalf("unexpected data %q", packet.GetData())
+		}
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("unexpected err %v", err)
+	}
+	<-fed
+}
+
+func TestStreamDemuxCloseUnblocksFeed(t *testing.T) {
+	d := NewStreamDemux(0, nil).SetStreamCapacity(1)
+	key := common.NewUuidRandom().String()
+	d.Feed(newStreamMessage(t, key, "1", false))
+
+	// Stream is not read, thus feed is blocked till stream fails
+	fed := make(chan struct{})
+	go func() {
+		d.Feed(newStreamMessage(t, key, "2", false))
+		close(fed)
+	}()
+	d.Close()
+	select {
+	case <-fed:
+	case <-time.After(time.Second):
+		t.Fatal("feed is still blocked")
+	}
+}
+
+func TestStreamDemuxFinishedBounded(t *testing.T) {
+	d := NewStreamDemux(0, nil)
+	defer d.Close()
+
+	first := common.NewUuidRandom().String()
+	d.Feed(newStreamMessage(t, first, "", true))
+	// Late message of the finished stream is dropped
+	d.Feed(newStreamMessage(t, first, "late", false))
+	if len(d.streams) != 0 {
+		t.Fatalf("late message starts stream")
+	}
+
+	for i := 0; i < finishedMax+10; i++ {
+		d.Feed(newStreamMessage(t, common.NewUuidRandom().String(), "", true))
+	}
+	if (len(d.finished) != finishedMax) || (len(d.finishedOrder) != finishedMax) {
+		t.Fatalf("%d finished streams remembered", len(d.finished))
+	}
+	if _, ok := d.finished[first]; ok {
+		t.Fatalf("the oldest finished stream is remembered")
+	}
+}
+
+func TestStreamDemuxExpire(t *testing.T) {
+	d := NewStreamDemux(time.Hour, nil)
+	defer d.Close()
+
+	key := common.NewUuidRandom().String()
+	d.Feed(newStreamMessage(t, key, "1", false))
+	stream := d.streams[key]
+	d.Feed(newStreamMessage(t, common.NewUuidRandom().String(), "", true))
+
+	// Abandoned stream times out and is remembered as finished, while the stream finished long ago is forgotten
+	now := time.Now().Add(90 * time.Minute)
+	d.expire(now)
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("data chunk received already is not available. err: %v", err)
+	}
+	if _, err := stream.Recv(); err != ErrStreamTimeout {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if _, ok := d.finished[key]; !ok || (len(d.finished) != 1) {
+		t.Fatalf("%d finished streams remembered", len(d.finished))
+	}
+	d.expire(now.Add(90 * time.Minute))
+	if (len(d.finished) != 0) || (len(d.finishedOrder) != 0) {
+		t.Fatalf("%d finished streams remembered", len(d.finished))
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mem
+
+import (
+	"io"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// DataChunkTransport is an in-memory loopback transport of DataPackets.
+// Transports are created in pairs: packets sent by one transport of the pair are received by the other one.
+// Packets are copied on send, thus sender is free to reuse them, the same way as with network transports.
+// Send and Close of the same transport are not expected to be called concurrently.
+// Transport, which is not going to receive packets anymore, is expected to CloseRecv, so peer does not block on Send.
+type DataChunkTransport struct {
+	in     <-chan *common.DataPacket
+	out    chan<- *common.DataPacket
+	closed bool
+
+	// inClosed is closed as soon as the transport stops receiving
+	inClosed     chan struct{}
+	inClosedOnce *sync.Once
+	// outClosed is closed as soon as the peer stops receiving
+	outClosed <-chan struct{}
+}
+
+// Ensure interface compatibility
+var (
+	_ common.DataPacketReader = &DataChunkTransport{}
+	_ common.DataPacketWriter = &DataChunkTransport{}
+)
+
+// NewDataChunkTransportPair creates pair of connected transports.
+// Capacity specifies how many packets can be sent without being received. Zero capacity means synchronous transfer.
+func NewDataChunkTransportPair(capacity int) (*DataChunkTransport, *DataChunkTransport) {
+	log.Tracef("mem.NewDataChunkTransportPair() - start")
+	defer log.Tracef("mem.NewDataChunkTransportPair() - end")
+
+	a2b := make(chan *common.DataPacket, capacity)
+	b2a := make(chan *common.DataPacket, capacity)
+	aClosed := make(chan struct{})
+	bClosed := make(chan struct{})
+	a := &DataChunkTransport{
+		in:           b2a,
+		out:          a2b,
+		inClosed:     aClosed,
+		inClosedOnce: &sync.Once{},
+		outClosed:    bClosed,
+	}
+	b := &DataChunkTransport{
+		in:           a2b,
+		out:          b2a,
+		inClosed:     bClosed,
+		inClosedOnce: &sync.Once{},
+		outClosed:    aClosed,
+	}
+	return a, b
+}
+
+// Send sends copy of the packet to the peer transport. Blocks in case capacity is exhausted.
+// Returns io.ErrClosedPipe in case either the transport is closed or peer does not receive anymore.
+func (t *DataChunkTransport) Send(packet *common.DataPacket) error {
+	log.Tracef("mem.DataChunkTransport.Send() - start")
+	defer log.Tracef("mem.DataChunkTransport.Send() - end")
+
+	if t.closed {
+		return io.ErrClosedPipe
+	}
+	// Packet is not sent to the peer, which already stopped receiving, even though capacity is available
+	select {
+	case <-t.outClosed:
+		return io.ErrClosedPipe
+	default:
+	}
+
+	select {
+	case t.out <- proto.Clone(packet).(*common.DataPacket):
+		return nil
+	case <-t.outClosed:
+		return io.ErrClosedPipe
+	}
+}
+
+// Recv receives packet sent by the peer transport. Returns io.EOF in case peer is closed and all packets are received.
+// Returns io.ErrClosedPipe in case receiving side of the transport is closed.
+func (t *DataChunkTransport) Recv() (*common.DataPacket, error) {
+	log.Tracef("mem.DataChunkTransport.Recv() - start")
+	defer log.Tracef("mem.DataChunkTransport.Recv() - end")
+
+	select {
+	case <-t.inClosed:
+		return nil, io.ErrClosedPipe
+	default:
+	}
+	packet, ok := <-t.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return packet, nil
+}
+
+// Close closes sending side of the transport, thus peer receives io.EOF after all packets sent are received.
+// Receiving side of the transport remains open.
+func (t *DataChunkTransport) Close() error {
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	close(t.out)
+	return nil
+}
+
+// CloseRecv closes receiving side of the transport. Packets sent, but not received yet, are dropped and
+// Send of the peer, blocked or further one, fails with io.ErrClosedPipe instead of waiting for packets to be received.
+// Sending side of the transport remains open. May be called concurrently with Send of the peer.
+func (t *DataChunkTransport) CloseRecv() error {
+	t.inClosedOnce.Do(func() {
+		close(t.inClosed)
+	})
+	return nil
+}
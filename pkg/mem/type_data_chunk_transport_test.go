@@ -0,0 +1,164 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mem
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// sendFile sends data as one stream over the transport and closes sending side of the transport
+func sendFile(t *DataChunkTransport, data []byte, options *common.DataPacketFileOptions) error {
+	f, err := common.OpenDataPacketFileWOptions(t, nil, options)
+	if err != nil {
+		return err
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return t.Close()
+}
+
+// recvFile receives the whole stream from the transport
+func recvFile(t *DataChunkTransport, options *common.DataPacketFileOptions) ([]byte, error) {
+	f, err := common.OpenDataPacketFileWOptions(nil, t, options)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(f)
+}
+
+func TestDataChunkTransportLoopback(t *testing.T) {
+	request := bytes.Repeat([]byte("0123456789abcdef"), 10*1024)
+	response := []byte("response")
+	options := func() *common.DataPacketFileOptions {
+		return common.NewDataPacketFileOptions().
+			SetChunkSize(4096).
+			SetStreamDigest(common.DigestType_DIGEST_SHA256).
+			SetVerifyDigest(true)
+	}
+
+	for _, capacity := range []int{0, 1, 100} {
+		client, server := NewDataChunkTransportPair(capacity)
+
+		// Server receives request and sends response back, after client closed its sending side
+		errs := make(chan error, 1)
+		go func() {
+			received, err := recvFile(server, options())
+			if err == nil && !bytes.Equal(received, request) {
+				err = io.ErrUnexpectedEOF
+			}
+			if err == nil {
+				err = sendFile(server, response, options())
+			}
+			errs <- err
+		}()
+
+		if err := sendFile(client, request, options()); err != nil {
+			t.Fatalf("capacity %d: %v", capacity, err)
+		}
+		received, err := recvFile(client, options())
+		if err != nil {
+			t.Fatalf("capacity %d: %v", capacity, err)
+		}
+		if err := <-errs; err != nil {
+			t.Fatalf("capacity %d: server: %v", capacity, err)
+		}
+		if !bytes.Equal(received, response) {
+			t.Fatalf("capacity %d: unexpected response %q", capacity, received)
+		}
+	}
+}
+
+func TestDataChunkTransportClose(t *testing.T) {
+	a, b := NewDataChunkTransportPair(1)
+	if err := a.Send(common.NewDataPacket()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Send(common.NewDataPacket()); err != io.ErrClosedPipe {
+		t.Fatalf("send to closed transport. err: %v", err)
+	}
+	// Packets sent before close are received
+	if _, err := b.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Recv(); err != io.EOF {
+		t.Fatalf("receive from closed peer. err: %v", err)
+	}
+	// Receiving side of the closed transport remains open
+	if err := b.Send(common.NewDataPacket()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Recv(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDataChunkTransportCloseRecv(t *testing.T) {
+	a, b := NewDataChunkTransportPair(0)
+
+	// Send blocks, since peer does not receive, till peer closes receiving side
+	errs := make(chan error, 1)
+	go func() {
+		errs <- a.Send(common.NewDataPacket())
+	}()
+	select {
+	case err := <-errs:
+		t.Fatalf("send is not blocked. err: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := b.CloseRecv(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if err != io.ErrClosedPipe {
+			t.Fatalf("unexpected err %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send is still blocked")
+	}
+
+	// Further sends fail as well, even though capacity would not be an issue
+	if err := a.Send(common.NewDataPacket()); err != io.ErrClosedPipe {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if _, err := b.Recv(); err != io.ErrClosedPipe {
+		t.Fatalf("unexpected err %v", err)
+	}
+	// Close of receiving side is idempotent and keeps sending side open
+	if err := b.CloseRecv(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		errs <- b.Send(common.NewDataPacket())
+	}()
+	if _, err := a.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
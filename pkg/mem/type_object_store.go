@@ -0,0 +1,212 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mem
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/store"
+)
+
+// object is an object kept in memory
+type object struct {
+	data         []byte
+	metadata     *common.Metadata
+	etag         string
+	lastModified time.Time
+}
+
+// ObjectStore is an in-memory ObjectStore, typically used in tests and in single-process deployments.
+// Data and metadata are copied on put and on get, thus callers are free to reuse them.
+type ObjectStore struct {
+	mutex   sync.RWMutex
+	buckets map[string]map[string]*object
+}
+
+// Ensure interface compatibility
+var _ store.ObjectStore = &ObjectStore{}
+
+// NewObjectStore creates new empty ObjectStore
+func NewObjectStore() *ObjectStore {
+	return &ObjectStore{
+		buckets: make(map[string]map[string]*object),
+	}
+}
+
+// cloneMetadata makes a deep copy of the metadata
+func cloneMetadata(metadata *common.Metadata) *common.Metadata {
+	if metadata == nil {
+		return nil
+	}
+	return proto.Clone(metadata).(*common.Metadata)
+}
+
+// get gets the object. Expected to be called under lock
+func (s *ObjectStore) get(addr *common.S3Address) (*object, error) {
+	if obj, ok := s.buckets[addr.GetBucket()][addr.GetObject()]; ok {
+		return obj, nil
+	}
+	return nil, store.NotFound(addr)
+}
+
+// set sets the object. Expected to be called under lock
+func (s *ObjectStore) set(addr *common.S3Address, obj *object) {
+	bucket, ok := s.buckets[addr.GetBucket()]
+	if !ok {
+		bucket = make(map[string]*object)
+		s.buckets[addr.GetBucket()] = bucket
+	}
+	bucket[addr.GetObject()] = obj
+}
+
+// newObjectInfo builds info of the object
+func newObjectInfo(addr *common.S3Address, obj *object) *store.ObjectInfo {
+	return &store.ObjectInfo{
+		Address:      addr,
+		Size:         int64(len(obj.data)),
+		ETag:         obj.etag,
+		LastModified: obj.lastModified,
+		Metadata:     cloneMetadata(obj.metadata),
+	}
+}
+
+// Put is an ObjectStore interface function
+func (s *ObjectStore) Put(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (int64, error) {
+	log.Tracef("mem.ObjectStore.Put() - start")
+	defer log.Tracef("mem.ObjectStore.Put() - end")
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return int64(len(data)), err
+	}
+	sum := md5.Sum(data)
+	obj := &object{
+		data:         data,
+		metadata:     cloneMetadata(metadata),
+		etag:         hex.EncodeToString(sum[:]),
+		lastModified: time.Now(),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.set(addr, obj)
+	return int64(len(data)), nil
+}
+
+// Get is an ObjectStore interface function
+func (s *ObjectStore) Get(addr *common.S3Address) (io.ReadCloser, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	obj, err := s.get(addr)
+	if err != nil {
+		return nil, err
+	}
+	// Data is never modified in place, thus it can be read without copying
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+// Stat is an ObjectStore interface function
+func (s *ObjectStore) Stat(addr *common.S3Address) (*store.ObjectInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	obj, err := s.get(addr)
+	if err != nil {
+		return nil, err
+	}
+	return newObjectInfo(addr, obj), nil
+}
+
+// List is an ObjectStore interface function
+func (s *ObjectStore) List(bucket, prefix string, n int) ([]*store.ObjectInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var keys []string
+	for key := range s.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if (n >= 0) && (len(keys) > n) {
+		keys = keys[:n]
+	}
+
+	res := make([]*store.ObjectInfo, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, newObjectInfo(common.NewS3Address(bucket, key), s.buckets[bucket][key]))
+	}
+	return res, nil
+}
+
+// Copy is an ObjectStore interface function
+func (s *ObjectStore) Copy(dst, src *common.S3Address) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	obj, err := s.get(src)
+	if err != nil {
+		return err
+	}
+	s.set(dst, &object{
+		data:         obj.data,
+		metadata:     cloneMetadata(obj.metadata),
+		etag:         obj.etag,
+		lastModified: time.Now(),
+	})
+	return nil
+}
+
+// Move is an ObjectStore interface function
+func (s *ObjectStore) Move(dst, src *common.S3Address) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	obj, err := s.get(src)
+	if err != nil {
+		return err
+	}
+	delete(s.buckets[src.GetBucket()], src.GetObject())
+	s.set(dst, obj)
+	return nil
+}
+
+// Remove is an ObjectStore interface function
+func (s *ObjectStore) Remove(addr *common.S3Address) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.buckets[addr.GetBucket()], addr.GetObject())
+	return nil
+}
+
+// Digest is an ObjectStore interface function
+func (s *ObjectStore) Digest(addr *common.S3Address, _type common.DigestType) (*common.Digest, error) {
+	return store.CalculateDigest(s, addr, _type)
+}
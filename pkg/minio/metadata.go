@@ -0,0 +1,274 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// Keys of S3 user metadata Metadata is stored in. Keys are in canonical form of HTTP header,
+// as S3 user metadata is transferred in X-Amz-Meta-* headers.
+const (
+	metaType        = "Tbox-Type"
+	metaName        = "Tbox-Name"
+	metaVersion     = "Tbox-Version"
+	metaDescription = "Tbox-Description"
+	metaStatus      = "Tbox-Status"
+	metaMode        = "Tbox-Mode"
+	metaTs          = "Tbox-Ts"
+	metaAddresses   = "Tbox-Addresses"
+	metaTypes       = "Tbox-Types"
+	metaVersions    = "Tbox-Versions"
+	metaStatuses    = "Tbox-Statuses"
+	metaModes       = "Tbox-Modes"
+
+	// userMetadataPrefix is the prefix of the headers of S3 user metadata
+	userMetadataPrefix = "X-Amz-Meta-"
+)
+
+// Keys of S3 object tags Metadata is tagged with. Tags can be used to filter objects, such as by lifecycle rules
+const (
+	// TagType specifies tag with type of the object
+	TagType = "type"
+	// TagName specifies tag with name of the object
+	TagName = "name"
+	// TagVersion specifies tag with version of the object
+	TagVersion = "version"
+)
+
+// MetadataToUserMetadata maps descriptive fields of the Metadata onto S3 user metadata.
+// Strings are escaped, since S3 user metadata is limited to US-ASCII, and addresses are stored as
+// base64 serialized AddressMap. Properties of the data, such as offset and len, are not stored,
+// since they describe data chunk rather than the object.
+func MetadataToUserMetadata(metadata *common.Metadata) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	res := make(map[string]string)
+	if metadata.HasType() {
+		res[metaType] = strconv.FormatInt(int64(metadata.GetType()), 10)
+	}
+	if metadata.HasName() {
+		res[metaName] = url.QueryEscape(metadata.GetName())
+	}
+	if metadata.HasVersion() {
+		res[metaVersion] = strconv.FormatInt(int64(metadata.GetVersion()), 10)
+	}
+	if metadata.HasDescription() {
+		res[metaDescription] = url.QueryEscape(metadata.GetDescription())
+	}
+	if metadata.HasStatus() {
+		res[metaStatus] = strconv.FormatInt(int64(metadata.GetStatus()), 10)
+	}
+	if metadata.HasMode() {
+		res[metaMode] = strconv.FormatInt(int64(metadata.GetMode()), 10)
+	}
+	if metadata.HasTimestamp() {
+		res[metaTs] = metadata.GetTs().AsTime().Format(time.RFC3339Nano)
+	}
+	if metadata.HasAddresses() {
+		if buf, err := proto.Marshal(metadata.GetAddresses()); err == nil {
+			res[metaAddresses] = base64.StdEncoding.EncodeToString(buf)
+		} else {
+			log.Warnf("unable to marshal addresses. err: %v", err)
+		}
+	}
+	if metadata.HasTypes() {
+		res[metaTypes] = formatSliceInt32(metadata.GetTypes())
+	}
+	if metadata.HasVersions() {
+		res[metaVersions] = formatSliceInt32(metadata.GetVersions())
+	}
+	if metadata.HasStatuses() {
+		res[metaStatuses] = formatSliceInt32(metadata.GetStatuses())
+	}
+	if metadata.HasModes() {
+		res[metaModes] = formatSliceInt32(metadata.GetModes())
+	}
+
+	return res
+}
+
+// MetadataToTags maps identifying fields of the Metadata, such as type, name and version, onto S3 object tags.
+// Name is tagged only in case it consists of characters allowed in tags.
+func MetadataToTags(metadata *common.Metadata) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	res := make(map[string]string)
+	if metadata.HasType() {
+		res[TagType] = strconv.FormatInt(int64(metadata.GetType()), 10)
+	}
+	if metadata.HasName() && isTagValueValid(metadata.GetName()) {
+		res[TagName] = metadata.GetName()
+	}
+	if metadata.HasVersion() {
+		res[TagVersion] = strconv.FormatInt(int64(metadata.GetVersion()), 10)
+	}
+
+	return res
+}
+
+// MetadataFromUserMetadata reconstructs Metadata out of S3 user metadata.
+// Keys may be provided either with or without X-Amz-Meta- prefix, such as by stat and list calls respectively.
+// Returns nil in case user metadata has no Metadata stored.
+func MetadataFromUserMetadata(userMetadata map[string]string) *common.Metadata {
+	values := make(map[string]string)
+	for key, value := range userMetadata {
+		key = http.CanonicalHeaderKey(key)
+		values[strings.TrimPrefix(key, userMetadataPrefix)] = value
+	}
+
+	metadata := common.NewMetadata()
+	found := false
+	if value, ok := values[metaType]; ok {
+		if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+			metadata.SetType(int32(i))
+			found = true
+		}
+	}
+	if value, ok := values[metaName]; ok {
+		if s, err := url.QueryUnescape(value); err == nil {
+			metadata.SetName(s)
+			found = true
+		}
+	}
+	if value, ok := values[metaVersion]; ok {
+		if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+			metadata.SetVersion(int32(i))
+			found = true
+		}
+	}
+	if value, ok := values[metaDescription]; ok {
+		if s, err := url.QueryUnescape(value); err == nil {
+			metadata.SetDescription(s)
+			found = true
+		}
+	}
+	if value, ok := values[metaStatus]; ok {
+		if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+			metadata.SetStatus(int32(i))
+			found = true
+		}
+	}
+	if value, ok := values[metaMode]; ok {
+		if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+			metadata.SetMode(int32(i))
+			found = true
+		}
+	}
+	if value, ok := values[metaTs]; ok {
+		if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			metadata.SetTimestamp(ts.Unix(), int32(ts.Nanosecond()))
+			found = true
+		}
+	}
+	if value, ok := values[metaAddresses]; ok {
+		addresses := new(common.AddressMap)
+		if buf, err := base64.StdEncoding.DecodeString(value); err != nil {
+			log.Warnf("unable to decode addresses. err: %v", err)
+		} else if err := proto.Unmarshal(buf, addresses); err != nil {
+			log.Warnf("unable to unmarshal addresses. err: %v", err)
+		} else {
+			metadata.SetAddresses(addresses)
+			found = true
+		}
+	}
+	if value, ok := values[metaTypes]; ok {
+		metadata.SetTypes(parseSliceInt32(value))
+		found = true
+	}
+	if value, ok := values[metaVersions]; ok {
+		metadata.SetVersions(parseSliceInt32(value))
+		found = true
+	}
+	if value, ok := values[metaStatuses]; ok {
+		metadata.SetStatuses(parseSliceInt32(value))
+		found = true
+	}
+	if value, ok := values[metaModes]; ok {
+		metadata.SetModes(parseSliceInt32(value))
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return metadata
+}
+
+// MetadataFromObjectInfo reconstructs Metadata out of the info of the object.
+// Size of the object is provided as total in properties.
+// Returns nil in case the object has no Metadata stored.
+func MetadataFromObjectInfo(info minio.ObjectInfo) *common.Metadata {
+	metadata := MetadataFromUserMetadata(info.UserMetadata)
+	if metadata == nil {
+		return nil
+	}
+	metadata.EnsureProperties().SetTotal(info.Size)
+	return metadata
+}
+
+// formatSliceInt32 formats slice as comma-separated list
+func formatSliceInt32(slice *common.SliceInt32) string {
+	items := make([]string, 0, slice.Len())
+	for _, item := range slice.GetAll() {
+		items = append(items, strconv.FormatInt(int64(item), 10))
+	}
+	return strings.Join(items, ",")
+}
+
+// parseSliceInt32 parses comma-separated list. Malformed items are skipped
+func parseSliceInt32(value string) *common.SliceInt32 {
+	slice := common.NewSliceInt32()
+	for _, item := range strings.Split(value, ",") {
+		if i, err := strconv.ParseInt(strings.TrimSpace(item), 10, 32); err == nil {
+			slice.Add(int32(i))
+		}
+	}
+	return slice
+}
+
+// isTagValueValid checks whether value can be used as S3 object tag value.
+// Tag values are limited to 256 letters, digits, spaces and + - = . _ : / @ characters.
+func isTagValueValid(value string) bool {
+	if len(value) > 256 {
+		return false
+	}
+	for _, r := range value {
+		switch {
+		case (r >= 'a') && (r <= 'z'):
+		case (r >= 'A') && (r <= 'Z'):
+		case (r >= '0') && (r <= '9'):
+		case strings.ContainsRune(" +-=._:/@", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
@@ -123,6 +124,29 @@ func NewMinIOFromConfig(cfg sections.MinIOConfigurator) (*MinIO, error) {
 
 // Put creates specified object from the reader
 func (m *MinIO) Put(bucketName, objectName string, reader io.Reader) (int64, error) {
+	return m.PutStream(bucketName, objectName, reader, 0)
+}
+
+// PutStream creates specified object from the reader of unknown size, streaming it as a multipart upload.
+// Memory used is bounded by the part size. Zero part size means default part size.
+// Upload is aborted in case the reader fails.
+func (m *MinIO) PutStream(bucketName, objectName string, reader io.Reader, partSize uint64) (int64, error) {
+	return m.put(bucketName, objectName, reader, partSize, nil)
+}
+
+// PutMetadata creates specified object from the reader. Metadata is stored as S3 user metadata and object tags,
+// thus it can be reconstructed with GetMetadata and ListMetadata
+func (m *MinIO) PutMetadata(bucketName, objectName string, reader io.Reader, metadata *common.Metadata) (int64, error) {
+	return m.put(bucketName, objectName, reader, 0, metadata)
+}
+
+// PutMetadataA creates specified object from the reader. Metadata is stored as S3 user metadata and object tags
+func (m *MinIO) PutMetadataA(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (int64, error) {
+	return m.PutMetadata(addr.Bucket, addr.Object, reader, metadata)
+}
+
+// put creates specified object from the reader of unknown size with metadata
+func (m *MinIO) put(bucketName, objectName string, reader io.Reader, partSize uint64, metadata *common.Metadata) (int64, error) {
 	if m.client == nil {
 		return 0, errorNotConnected
 	}
@@ -137,7 +161,10 @@ func (m *MinIO) Put(bucketName, objectName string, reader io.Reader) (int64, err
 	// Specify -1 in case object size in unknown in advance
 	size := int64(-1)
 	options := minio.PutObjectOptions{
-		ContentType: "application/octet-stream",
+		ContentType:  "application/octet-stream",
+		PartSize:     partSize,
+		UserMetadata: MetadataToUserMetadata(metadata),
+		UserTags:     MetadataToTags(metadata),
 	}
 
 	info, err := m.client.PutObject(ctx, bucketName, objectName, reader, size, options)
@@ -164,6 +191,17 @@ func (m *MinIO) PutUUIDA(addr *common.S3Address, reader io.Reader) (*common.S3Ad
 	return target, n, err
 }
 
+// PutUUIDMetadataA creates object in specified bucket named by generated UUID from the reader.
+// Metadata is stored as S3 user metadata and object tags
+func (m *MinIO) PutUUIDMetadataA(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (*common.S3Address, int64, error) {
+	target := &common.S3Address{
+		Bucket: addr.Bucket,
+		Object: PathJoin(addr.Object, common.NewUuidRandom().String()),
+	}
+	n, err := m.PutMetadataA(target, reader, metadata)
+	return target, n, err
+}
+
 // FPut creates specified object from the file
 func (m *MinIO) FPut(bucketName, objectName, fileName string) (int64, error) {
 	if m.client == nil {
@@ -222,6 +260,118 @@ func (m *MinIO) GetA(addr *common.S3Address) (io.Reader, error) {
 	return m.Get(addr.Bucket, addr.Object)
 }
 
+// GetRange returns reader for the range of specified object.
+// Range starts at offset and is length bytes long. Negative length means till the end of the object
+func (m *MinIO) GetRange(bucketName, objectName string, offset, length int64) (io.Reader, error) {
+	if m.client == nil {
+		return nil, errorNotConnected
+	}
+	if length == 0 {
+		return bytes.NewReader(nil), nil
+	}
+
+	ctx := context.Background()
+	opts := minio.GetObjectOptions{}
+	var err error
+	switch {
+	case length > 0:
+		err = opts.SetRange(offset, offset+length-1)
+	case offset > 0:
+		// Till the end of the object
+		err = opts.SetRange(offset, 0)
+	default:
+		// The whole object
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.client.GetObject(ctx, bucketName, objectName, opts)
+}
+
+// GetRangeA returns reader for the range of specified object
+func (m *MinIO) GetRangeA(addr *common.S3Address, offset, length int64) (io.Reader, error) {
+	return m.GetRange(addr.Bucket, addr.Object, offset, length)
+}
+
+// Stat returns info of specified object, such as size, ETag and user metadata
+func (m *MinIO) Stat(bucketName, objectName string) (minio.ObjectInfo, error) {
+	if m.client == nil {
+		return minio.ObjectInfo{}, errorNotConnected
+	}
+
+	ctx := context.Background()
+	return m.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+}
+
+// StatA returns info of specified object, such as size, ETag and user metadata
+func (m *MinIO) StatA(addr *common.S3Address) (minio.ObjectInfo, error) {
+	return m.Stat(addr.Bucket, addr.Object)
+}
+
+// Size returns size of specified object
+func (m *MinIO) Size(bucketName, objectName string) (int64, error) {
+	info, err := m.Stat(bucketName, objectName)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+// SizeA returns size of specified object
+func (m *MinIO) SizeA(addr *common.S3Address) (int64, error) {
+	return m.Size(addr.Bucket, addr.Object)
+}
+
+// GetMetadata returns Metadata stored with specified object. Returns nil Metadata in case none stored
+func (m *MinIO) GetMetadata(bucketName, objectName string) (*common.Metadata, error) {
+	info, err := m.Stat(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	return MetadataFromObjectInfo(info), nil
+}
+
+// GetMetadataA returns Metadata stored with specified object. Returns nil Metadata in case none stored
+func (m *MinIO) GetMetadataA(addr *common.S3Address) (*common.Metadata, error) {
+	return m.GetMetadata(addr.Bucket, addr.Object)
+}
+
+// PresignedGet returns URL to get specified object directly, valid for expiry
+func (m *MinIO) PresignedGet(bucketName, objectName string, expiry time.Duration) (*url.URL, error) {
+	if m.client == nil {
+		return nil, errorNotConnected
+	}
+
+	ctx := context.Background()
+	return m.client.PresignedGetObject(ctx, bucketName, objectName, expiry, nil)
+}
+
+// PresignedGetA returns URL to get specified object directly, valid for expiry
+func (m *MinIO) PresignedGetA(addr *common.S3Address, expiry time.Duration) (*url.URL, error) {
+	return m.PresignedGet(addr.Bucket, addr.Object, expiry)
+}
+
+// PresignedPut returns URL to put specified object directly, valid for expiry
+func (m *MinIO) PresignedPut(bucketName, objectName string, expiry time.Duration) (*url.URL, error) {
+	if m.client == nil {
+		return nil, errorNotConnected
+	}
+
+	if m.BucketAutoCreate {
+		if err := m.CreateBucket(bucketName); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	return m.client.PresignedPutObject(ctx, bucketName, objectName, expiry)
+}
+
+// PresignedPutA returns URL to put specified object directly, valid for expiry
+func (m *MinIO) PresignedPutA(addr *common.S3Address, expiry time.Duration) (*url.URL, error) {
+	return m.PresignedPut(addr.Bucket, addr.Object, expiry)
+}
+
 // BufferGet downloads specified object into memory buffer
 func (m *MinIO) BufferGet(bucketName, objectName string) (*bytes.Buffer, error) {
 	// Obtain reader
@@ -348,6 +498,36 @@ func (m *MinIO) Copy(dstBucketName, dstObjectName, srcBucketName, srcObjectName
 	return err
 }
 
+// CopyMetadataA copies specified src object into specified dst object.
+// Metadata of the src object is replaced with specified metadata, stored as S3 user metadata and object tags
+func (m *MinIO) CopyMetadataA(dst, src *common.S3Address, metadata *common.Metadata) error {
+	if m.client == nil {
+		return errorNotConnected
+	}
+
+	if m.BucketAutoCreate {
+		if err := m.CreateBucket(dst.Bucket); err != nil {
+			return err
+		}
+	}
+
+	_src := minio.CopySrcOptions{
+		Bucket: src.Bucket,
+		Object: src.Object,
+	}
+	_dst := minio.CopyDestOptions{
+		Bucket:          dst.Bucket,
+		Object:          dst.Object,
+		UserMetadata:    MetadataToUserMetadata(metadata),
+		ReplaceMetadata: true,
+		UserTags:        MetadataToTags(metadata),
+		ReplaceTags:     true,
+	}
+
+	_, err := m.client.CopyObject(context.Background(), _dst, _src)
+	return err
+}
+
 // CopyA copies specified src object into specified dst object
 func (m *MinIO) CopyA(dst, src *common.S3Address) error {
 	return m.Copy(dst.Bucket, dst.Object, src.Bucket, src.Object)
@@ -366,6 +546,43 @@ func (m *MinIO) MoveA(dst, src *common.S3Address) error {
 	return m.Move(dst.Bucket, dst.Object, src.Bucket, src.Object)
 }
 
+// Compose composes specified dst object by concatenating specified src objects of the src bucket
+func (m *MinIO) Compose(dstBucketName, dstObjectName, srcBucketName string, srcObjectNames []string) error {
+	src := make([]*common.S3Address, 0, len(srcObjectNames))
+	for _, srcObjectName := range srcObjectNames {
+		src = append(src, common.NewS3Address(srcBucketName, srcObjectName))
+	}
+	return m.ComposeA(common.NewS3Address(dstBucketName, dstObjectName), src)
+}
+
+// ComposeA composes specified dst object by concatenating specified src objects
+func (m *MinIO) ComposeA(dst *common.S3Address, src []*common.S3Address) error {
+	if m.client == nil {
+		return errorNotConnected
+	}
+
+	if m.BucketAutoCreate {
+		if err := m.CreateBucket(dst.Bucket); err != nil {
+			return err
+		}
+	}
+
+	sources := make([]minio.CopySrcOptions, 0, len(src))
+	for _, addr := range src {
+		sources = append(sources, minio.CopySrcOptions{
+			Bucket: addr.Bucket,
+			Object: addr.Object,
+		})
+	}
+	_dst := minio.CopyDestOptions{
+		Bucket: dst.Bucket,
+		Object: dst.Object,
+	}
+
+	_, err := m.client.ComposeObject(context.Background(), _dst, sources...)
+	return err
+}
+
 // Digest calculates digest of the specified object
 func (m *MinIO) Digest(bucketName, objectName string, _type common.DigestType) (*common.Digest, error) {
 	reader, err := m.Get(bucketName, objectName)
@@ -471,6 +688,39 @@ func (m *MinIO) List(bucket, prefix string, n int) ([]minio.ObjectInfo, error) {
 	return res, nil
 }
 
+// ListMetadata lists at max n objects from a bucket having specified name prefix along with Metadata stored with them.
+// Metadata is nil for objects with no Metadata stored. Negative n means all objects.
+func (m *MinIO) ListMetadata(bucket, prefix string, n int) ([]minio.ObjectInfo, []*common.Metadata, error) {
+	if m.client == nil {
+		return nil, nil, errorNotConnected
+	}
+
+	var infos []minio.ObjectInfo
+	var metadata []*common.Metadata
+
+	// Listing is cancelled in case it is stopped early
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := minio.ListObjectsOptions{
+		Recursive:    true,
+		Prefix:       prefix,
+		WithMetadata: true,
+	}
+	for object := range m.client.ListObjects(ctx, bucket, opts) {
+		if object.Err != nil {
+			return nil, nil, object.Err
+		}
+		infos = append(infos, object)
+		metadata = append(metadata, MetadataFromObjectInfo(object))
+		if (n >= 0) && (len(infos) >= n) {
+			break
+		}
+	}
+
+	return infos, metadata, nil
+}
+
 // PathJoin joins object path components
 func PathJoin(elem ...string) string {
 	return filepath.ToSlash(filepath.Join(elem...))
@@ -16,25 +16,44 @@ package minio
 
 import (
 	"bytes"
-	"context"
 	"fmt"
+	"io"
 
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/sunsingerus/tbox/pkg/api/common"
 )
 
+// accessorStorage stores chunks of the accessor and composes objects out of them. Is implemented by MinIO
+type accessorStorage interface {
+	Put(bucketName, objectName string, reader io.Reader) (int64, error)
+	Compose(dstBucketName, dstObjectName, srcBucketName string, srcObjectNames []string) error
+	Remove(bucketName, objectName string) error
+}
+
+// Ensure interface compatibility
+var (
+	_ accessorStorage = &MinIO{}
+)
+
 type Accessor struct {
 	mi        *MinIO
 	s3address *common.S3Address
+	// storage stores chunks and composes the object out of them, MinIO by default
+	storage accessorStorage
 
 	// Chunks names targeted as a new destination object. Used for incoming
 	chunks []string
+	// compactions counts compactions of the chunks into intermediate objects
+	compactions int
+	// err specifies the failure the accessor failed with. Failed accessor accepts no more chunks
+	err error
 }
 
 const (
+	// maxChunks specifies how many chunks are accumulated before they are compacted into an intermediate object.
+	// Compose is limited to 10K sources, thus chunks are compacted way before the limit is reached.
 	maxChunks = 1000
 )
 
@@ -54,6 +73,7 @@ func NewAccessor(mi *MinIO, s3address *common.S3Address) (*Accessor, error) {
 	return &Accessor{
 		mi:        mi,
 		s3address: s3address,
+		storage:   mi,
 	}, nil
 }
 
@@ -62,7 +82,14 @@ func (a *Accessor) Close() error {
 	log.Tracef("minio.Accessor.Close() - start")
 	defer log.Tracef("minio.Accessor.Close() - end")
 
-	return a.composeObject()
+	if a.err != nil {
+		return a.err
+	}
+	if err := a.composeObject(); err != nil {
+		a.fail(err)
+		return err
+	}
+	return nil
 }
 
 // writeChunk writes chunk of data as separate object
@@ -70,23 +97,67 @@ func (a *Accessor) writeChunk(data []byte) (int, error) {
 	log.Tracef("minio.Accessor.writeChunk() - start")
 	defer log.Tracef("minio.Accessor.writeChunk() - end")
 
+	if a.err != nil {
+		return 0, a.err
+	}
+
 	chunkObjectName := a.makeUniqueChunkName()
-	n, err := a.mi.Put(a.s3address.Bucket, chunkObjectName, bytes.NewBuffer(data))
+	n, err := a.storage.Put(a.s3address.Bucket, chunkObjectName, bytes.NewBuffer(data))
 	if err != nil {
 		log.Errorf("unable to put chunk. err:%v", err)
+		// Chunk may be partially written
+		_ = a.storage.Remove(a.s3address.Bucket, chunkObjectName)
+		a.fail(err)
 		return int(n), err
 	}
 
 	a.chunks = append(a.chunks, chunkObjectName)
-	if len(a.chunks) > maxChunks {
-		// TODO compact
-		// there is limitation in 10K chunks in
-		// err = f.mi.client.ComposeObject(dst, sources)
-		// need to implement compaction
-	}
 	log.Infof("put chunk %s/%s size %d", a.s3address.Bucket, chunkObjectName, n)
 
-	return int(n), err
+	if len(a.chunks) >= maxChunks {
+		if err := a.compact(); err != nil {
+			a.fail(err)
+			return int(n), err
+		}
+	}
+
+	return int(n), nil
+}
+
+// compact composes accumulated chunks into an intermediate object, which replaces them as the first chunk.
+// Thus number of chunks stays below maxChunks regardless of the stream length.
+func (a *Accessor) compact() error {
+	log.Tracef("minio.Accessor.compact() - start")
+	defer log.Tracef("minio.Accessor.compact() - end")
+
+	intermediate := a.makeUniqueCompactedName()
+	if err := a.storage.Compose(a.s3address.Bucket, intermediate, a.s3address.Bucket, a.chunks); err != nil {
+		log.Errorf("unable to compact %d chunks into %s/%s err:%v", len(a.chunks), a.s3address.Bucket, intermediate, err)
+		return err
+	}
+	log.Infof("compacted %d chunks into %s/%s", len(a.chunks), a.s3address.Bucket, intermediate)
+
+	a.removeChunks()
+	a.chunks = []string{intermediate}
+	a.compactions++
+
+	return nil
+}
+
+// fail marks accessor as failed and removes chunks accumulated, since they can not be composed into an object anymore
+func (a *Accessor) fail(err error) {
+	a.err = err
+	a.removeChunks()
+	a.chunks = nil
+}
+
+// removeChunks removes chunk objects. Failures are logged only, since there is nothing to do with them
+func (a *Accessor) removeChunks() {
+	for _, chunk := range a.chunks {
+		if err := a.storage.Remove(a.s3address.Bucket, chunk); err != nil {
+			log.Errorf("unable to RemoveObject() %s/%s err:%v", a.s3address.Bucket, chunk, err)
+		}
+	}
 }
 
 // makeUniqueChunkName makes unique object name for this DataChunk
@@ -105,6 +176,15 @@ func (a *Accessor) makeUniqueChunkName() string {
 	return fmt.Sprintf("%s_%s_%s", objectPart, indexPart, uuidPart)
 }
 
+// makeUniqueCompactedName makes unique object name for the intermediate object of compacted chunks
+func (a *Accessor) makeUniqueCompactedName() string {
+	var uuidPart string
+	if _uuid, err := uuid.NewUUID(); err == nil {
+		uuidPart = _uuid.String()
+	}
+	return fmt.Sprintf("%s_compacted_%d_%s", a.s3address.Object, a.compactions, uuidPart)
+}
+
 // composeObject composes new destination object out of chunks
 func (a *Accessor) composeObject() error {
 	// Compose single object out of slice of chunks targeted to be the object
@@ -118,34 +198,15 @@ func (a *Accessor) composeObject() error {
 
 	log.Infof("compose object out of %d chunks", len(a.chunks))
 
-	// Slice of sources.
-	sources := make([]minio.CopySrcOptions, 0)
-	for _, chunk := range a.chunks {
-		sources = append(sources, minio.CopySrcOptions{
-			Bucket: a.s3address.Bucket,
-			Object: chunk,
-		})
-	}
-
-	// Create destination info
-	dst := minio.CopyDestOptions{
-		Bucket: a.s3address.Bucket,
-		Object: a.s3address.Object,
-	}
-
 	// Compose object by concatenating multiple source files.
-	_, err := a.mi.client.ComposeObject(context.Background(), dst, sources...)
+	err := a.storage.Compose(a.s3address.Bucket, a.s3address.Object, a.s3address.Bucket, a.chunks)
 	if err != nil {
 		log.Errorf("unable to ComposeObject() err:%v", err)
 		return err
 	}
 	log.Infof("composed object %s/%s", a.s3address.Bucket, a.s3address.Object)
 
-	for _, chunk := range a.chunks {
-		if err = a.mi.Remove(a.s3address.Bucket, chunk); err != nil {
-			log.Errorf("unable to RemoveObject() %s/%s err:%v", a.s3address.Bucket, chunk, err)
-		}
-	}
+	a.removeChunks()
 
 	// Chunks are empty from this moment, since we've just created object out of these chunks
 	a.chunks = nil
@@ -0,0 +1,165 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// accessorStorageMap is an accessorStorage, which keeps objects of one bucket in memory
+type accessorStorageMap struct {
+	objects map[string][]byte
+	// failPut specifies which put fails, counted from 1, after it has written part of the data. Zero means none
+	failPut int
+	puts    int
+	// failCompose specifies which compose fails, counted from 1. Zero means none
+	failCompose int
+	composes    int
+}
+
+// newAccessor creates accessor with storage in memory
+func newAccessor(storage *accessorStorageMap) *Accessor {
+	storage.objects = map[string][]byte{}
+	return &Accessor{
+		s3address: common.NewS3Address("bucket", "object"),
+		storage:   storage,
+	}
+}
+
+// Put is an accessorStorage interface function
+func (s *accessorStorageMap) Put(_, objectName string, reader io.Reader) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+	s.puts++
+	if s.puts == s.failPut {
+		s.objects[objectName] = data[:len(data)/2]
+		return int64(len(data) / 2), fmt.Errorf("put failed")
+	}
+	s.objects[objectName] = data
+	return int64(len(data)), nil
+}
+
+// Compose is an accessorStorage interface function
+func (s *accessorStorageMap) Compose(_, dstObjectName, _ string, srcObjectNames []string) error {
+	s.composes++
+	if s.composes == s.failCompose {
+		return fmt.Errorf("compose failed")
+	}
+	var data []byte
+	for _, src := range srcObjectNames {
+		object, ok := s.objects[src]
+		if !ok {
+			return fmt.Errorf("no object %s", src)
+		}
+		data = append(data, object...)
+	}
+	s.objects[dstObjectName] = data
+	return nil
+}
+
+// Remove is an accessorStorage interface function
+func (s *accessorStorageMap) Remove(_, objectName string) error {
+	delete(s.objects, objectName)
+	return nil
+}
+
+func TestAccessor(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage *accessorStorageMap
+		chunks  int
+		// chunksLeft specifies number of chunks kept by the accessor before Close
+		chunksLeft  int
+		compactions int
+		err         bool
+	}{
+		{name: "one chunk", storage: &accessorStorageMap{}, chunks: 1, chunksLeft: 1},
+		{name: "below limit", storage: &accessorStorageMap{}, chunks: maxChunks - 1, chunksLeft: maxChunks - 1},
+		{name: "limit", storage: &accessorStorageMap{}, chunks: maxChunks, chunksLeft: 1, compactions: 1},
+		{name: "above limit", storage: &accessorStorageMap{}, chunks: maxChunks + 5, chunksLeft: 6, compactions: 1},
+		{name: "compacted twice", storage: &accessorStorageMap{}, chunks: 2*maxChunks - 1, chunksLeft: 1, compactions: 2},
+		{name: "put failed", storage: &accessorStorageMap{failPut: 3}, chunks: 5, err: true},
+		{name: "compaction failed", storage: &accessorStorageMap{failCompose: 1}, chunks: maxChunks + 1, err: true},
+		{name: "compose failed", storage: &accessorStorageMap{failCompose: 1}, chunks: 3, chunksLeft: 3, err: true},
+	}
+	for _, test := range tests {
+		a := newAccessor(test.storage)
+		var data []byte
+		var err error
+		for i := 0; (i < test.chunks) && (err == nil); i++ {
+			chunk := []byte(fmt.Sprintf("chunk %d;", i))
+			data = append(data, chunk...)
+			_, err = a.writeChunk(chunk)
+		}
+		if err == nil {
+			if (len(a.chunks) != test.chunksLeft) || (a.compactions != test.compactions) {
+				t.Fatalf("%s: %d chunks left after %d compactions", test.name, len(a.chunks), a.compactions)
+			}
+			if len(test.storage.objects) != test.chunksLeft {
+				t.Fatalf("%s: %d objects stored for %d chunks", test.name, len(test.storage.objects), test.chunksLeft)
+			}
+			err = a.Close()
+		}
+		if (err != nil) != test.err {
+			t.Fatalf("%s: unexpected err: %v", test.name, err)
+		}
+
+		if test.err {
+			// Failed accessor removes all chunks, including the one failed to be put, and accepts no more chunks
+			if len(test.storage.objects) != 0 {
+				t.Fatalf("%s: %d objects left after failure", test.name, len(test.storage.objects))
+			}
+			if _, e := a.writeChunk([]byte("more")); e != err {
+				t.Fatalf("%s: failed accessor accepts chunks. err: %v", test.name, e)
+			}
+			if e := a.Close(); e != err {
+				t.Fatalf("%s: failed accessor is closed with err: %v", test.name, e)
+			}
+			continue
+		}
+		// The object is the only one left
+		if (len(test.storage.objects) != 1) || !bytes.Equal(test.storage.objects["object"], data) {
+			t.Fatalf("%s: %d objects left, object has %d bytes of %d", test.name, len(test.storage.objects), len(test.storage.objects["object"]), len(data))
+		}
+	}
+}
+
+func TestAccessorNames(t *testing.T) {
+	a := newAccessor(&accessorStorageMap{})
+	names := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		a.compactions = i % 3
+		for _, name := range []string{a.makeUniqueChunkName(), a.makeUniqueCompactedName()} {
+			if !strings.HasPrefix(name, "object_") {
+				t.Fatalf("name %s is not prefixed with the object", name)
+			}
+			if names[name] {
+				t.Fatalf("name %s is not unique", name)
+			}
+			names[name] = true
+		}
+	}
+	if name := a.makeUniqueCompactedName(); !strings.HasPrefix(name, fmt.Sprintf("object_compacted_%d_", a.compactions)) {
+		t.Fatalf("compacted name %s does not specify compaction", name)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// DedupIndex keeps index of deduplicated blobs in MinIO.
+// Each blob is indexed by a small object, which holds address of the blob.
+// Index object is named by the key of the digest of the blob's data, such as prefix/sha256/<hex>.
+type DedupIndex struct {
+	mi     *MinIO
+	bucket string
+	prefix string
+}
+
+// NewDedupIndex creates new DedupIndex
+func NewDedupIndex(mi *MinIO, bucket, prefix string) *DedupIndex {
+	return &DedupIndex{
+		mi:     mi,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+// entryName builds name of the object of the index entry
+func (i *DedupIndex) entryName(digest *common.Digest) string {
+	return PathJoin(i.prefix, digest.GetKey())
+}
+
+// Lookup looks for the blob with the data of the digest. Returns nil address in case no blob found
+func (i *DedupIndex) Lookup(digest *common.Digest) (*common.S3Address, error) {
+	reader, err := i.mi.Get(i.bucket, i.entryName(digest))
+	if err != nil {
+		return nil, err
+	}
+	// Object is requested lazily, thus missing object is reported on read
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return common.NewS3AddressFromString(strings.TrimSpace(string(data))), nil
+}
+
+// Add adds blob with the data of the digest into the index
+func (i *DedupIndex) Add(digest *common.Digest, addr *common.S3Address) error {
+	_, err := i.mi.Put(i.bucket, i.entryName(digest), strings.NewReader(addr.String()))
+	return err
+}
@@ -15,18 +15,45 @@
 package minio
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
+	"github.com/minio/minio-go/v7"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/sunsingerus/tbox/pkg/api/common"
 )
 
+// DefaultFilePartSize specifies default size of the part of the multipart upload the File writes
+const DefaultFilePartSize = 16 * 1024 * 1024
+
+var (
+	// ErrFileWriting is reported in case File being written is read or seeked
+	ErrFileWriting = errors.New("minio file is being written")
+	// ErrFileClosed is reported in case closed File is accessed
+	ErrFileClosed = errors.New("minio file is closed")
+)
+
 // File
 // Inspired by os.File handler and is expected to be used in the same context.
+// Writes are streamed into a multipart upload, which is completed on Close. Memory used is bounded by the part size.
+// Reads are served by ranged requests, starting at the current offset, thus File can be seeked and read at.
 type File struct {
 	Accessor
+
+	// partSize specifies size of the part of the multipart upload
+	partSize uint64
+	// offset specifies offset of the next read
+	offset int64
+	// reader reads object starting at offset. Opened lazily and reopened after seek
+	reader io.Reader
+
+	// writer writes into multipart upload. Opened by the first write
+	writer *io.PipeWriter
+	// written is the result of the multipart upload, available after upload is completed
+	written chan error
+	closed  bool
 }
 
 // OpenFile
@@ -39,17 +66,126 @@ func OpenFile(mi *MinIO, s3address *common.S3Address) (*File, error) {
 	} else {
 		return &File{
 			Accessor: *accessor,
+			partSize: DefaultFilePartSize,
 		}, nil
 	}
 }
 
-// Read
+// SetPartSize sets size of the part of the multipart upload. Has to be set before the first write
+func (f *File) SetPartSize(partSize uint64) *File {
+	if f == nil {
+		return nil
+	}
+	f.partSize = partSize
+	return f
+}
+
+// Close completes multipart upload in case file is written
+func (f *File) Close() error {
+	log.Tracef("minio.File.Close() - start")
+	defer log.Tracef("minio.File.Close() - end")
+
+	if f.closed {
+		return ErrFileClosed
+	}
+	f.closed = true
+	f.closeReader()
+
+	if f.writer == nil {
+		return nil
+	}
+	// Reader of the pipe gets EOF, thus the last part is uploaded and upload is completed
+	_ = f.writer.Close()
+	return <-f.written
+}
+
+// Stat returns info of the object, such as size, ETag and user metadata
+func (f *File) Stat() (minio.ObjectInfo, error) {
+	return f.mi.Stat(f.s3address.Bucket, f.s3address.Object)
+}
+
+// Read reads object starting at the current offset
 func (f *File) Read(p []byte) (int, error) {
 	log.Tracef("minio.File.Read() - start")
 	defer log.Tracef("minio.File.Read() - end")
 
-	log.Errorf("unimplemented method minio.File.Read()")
-	return 0, fmt.Errorf("unimplemented method minio.File.Read()")
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+	if f.reader == nil {
+		reader, err := f.mi.GetRange(f.s3address.Bucket, f.s3address.Object, f.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		f.reader = reader
+	}
+
+	n, err := f.reader.Read(p)
+	f.offset += int64(n)
+	return n, rangeError(err)
+}
+
+// ReadAt reads len(p) bytes of the object starting at offset. Does not affect the current offset
+func (f *File) ReadAt(p []byte, offset int64) (int, error) {
+	log.Tracef("minio.File.ReadAt() - start")
+	defer log.Tracef("minio.File.ReadAt() - end")
+
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("minio.File.ReadAt() negative offset %d", offset)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	reader, err := f.mi.GetRange(f.s3address.Bucket, f.s3address.Object, offset, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	n, err := io.ReadFull(reader, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		// Range is beyond the end of the object
+		err = io.EOF
+	}
+	return n, rangeError(err)
+}
+
+// Seek sets offset of the next read
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	log.Tracef("minio.File.Seek() - start")
+	defer log.Tracef("minio.File.Seek() - end")
+
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		info, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		offset += info.Size
+	default:
+		return 0, fmt.Errorf("minio.File.Seek() invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("minio.File.Seek() negative offset %d", offset)
+	}
+
+	if offset != f.offset {
+		f.closeReader()
+		f.offset = offset
+	}
+	return f.offset, nil
 }
 
 // ReadFrom reads data from src
@@ -57,27 +193,81 @@ func (f *File) ReadFrom(src io.Reader) (int64, error) {
 	log.Tracef("minio.File.ReadFrom() - start")
 	defer log.Tracef("minio.File.ReadFrom() - end")
 
-	return f.mi.Put(f.s3address.Bucket, f.s3address.Object, src)
+	if f.closed {
+		return 0, ErrFileClosed
+	}
+	if f.writer != nil {
+		// Upload is started already, thus data is appended to it
+		return io.Copy(writerOnly{f}, src)
+	}
+	return f.mi.PutStream(f.s3address.Bucket, f.s3address.Object, src, f.partSize)
 }
 
-// Write
+// Write writes data into multipart upload. Upload is started by the first write
 func (f *File) Write(p []byte) (int, error) {
 	log.Tracef("minio.File.Write() - start")
 	defer log.Tracef("minio.File.Write() - end")
 
-	return f.writeChunk(p)
+	if f.closed {
+		return 0, ErrFileClosed
+	}
+	if f.writer == nil {
+		f.closeReader()
+		reader, writer := io.Pipe()
+		f.writer = writer
+		f.written = make(chan error, 1)
+		go func() {
+			_, err := f.mi.PutStream(f.s3address.Bucket, f.s3address.Object, reader, f.partSize)
+			// Unblock writer in case upload failed before all data is read
+			_ = reader.CloseWithError(err)
+			f.written <- err
+		}()
+	}
+
+	return f.writer.Write(p)
 }
 
-// WriteTo writes data to dst
+// WriteTo writes data starting at the current offset to dst
 func (f *File) WriteTo(dst io.Writer) (int64, error) {
 	log.Tracef("minio.File.WriteTo() - start")
 	defer log.Tracef("minio.File.WriteTo() - end")
 
-	r, err := f.mi.Get(f.s3address.Bucket, f.s3address.Object)
-	if err != nil {
-		log.Errorf("got error from MinIO: %v", err)
-		return 0, err
+	return io.Copy(dst, readerOnly{f})
+}
+
+// checkRead checks whether file can be read
+func (f *File) checkRead() error {
+	if f.closed {
+		return ErrFileClosed
+	}
+	if f.writer != nil {
+		return ErrFileWriting
 	}
+	return nil
+}
+
+// rangeError reports range starting beyond the end of the object as io.EOF, the same way os.File does
+func rangeError(err error) error {
+	if (err != nil) && (minio.ToErrorResponse(err).Code == "InvalidRange") {
+		return io.EOF
+	}
+	return err
+}
+
+// closeReader closes reader of the object, in case it is opened
+func (f *File) closeReader() {
+	if closer, ok := f.reader.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	f.reader = nil
+}
+
+// readerOnly hides ReadFrom/WriteTo of the File from io.Copy, which would otherwise recurse into them
+type readerOnly struct {
+	io.Reader
+}
 
-	return io.Copy(dst, r)
+// writerOnly hides ReadFrom/WriteTo of the File from io.Copy, which would otherwise recurse into them
+type writerOnly struct {
+	io.Writer
 }
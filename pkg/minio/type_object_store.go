@@ -0,0 +1,151 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+	"github.com/sunsingerus/tbox/pkg/config/sections"
+	"github.com/sunsingerus/tbox/pkg/store"
+)
+
+// ObjectStore is an ObjectStore backed by MinIO/S3.
+// Metadata is stored as S3 user metadata and object tags.
+type ObjectStore struct {
+	mi *MinIO
+}
+
+// Ensure interface compatibility
+var _ store.ObjectStore = &ObjectStore{}
+
+// NewObjectStore creates new ObjectStore backed by MinIO
+func NewObjectStore(mi *MinIO) *ObjectStore {
+	return &ObjectStore{
+		mi: mi,
+	}
+}
+
+// NewObjectStoreFromConfig creates new ObjectStore backed by MinIO specified by config
+func NewObjectStoreFromConfig(cfg sections.MinIOConfigurator) (*ObjectStore, error) {
+	mi, err := NewMinIOFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewObjectStore(mi), nil
+}
+
+// GetMinIO gets MinIO the store is backed by
+func (s *ObjectStore) GetMinIO() *MinIO {
+	if s == nil {
+		return nil
+	}
+	return s.mi
+}
+
+// Put is an ObjectStore interface function
+func (s *ObjectStore) Put(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (int64, error) {
+	return s.mi.PutMetadataA(addr, reader, metadata)
+}
+
+// Get is an ObjectStore interface function
+func (s *ObjectStore) Get(addr *common.S3Address) (io.ReadCloser, error) {
+	if s.mi.client == nil {
+		return nil, errorNotConnected
+	}
+	object, err := s.mi.client.GetObject(context.Background(), addr.Bucket, addr.Object, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storeError(addr, err)
+	}
+	// Object is requested lazily, thus missing object is reported by the first request
+	if _, err := object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, storeError(addr, err)
+	}
+	return object, nil
+}
+
+// Stat is an ObjectStore interface function
+func (s *ObjectStore) Stat(addr *common.S3Address) (*store.ObjectInfo, error) {
+	info, err := s.mi.StatA(addr)
+	if err != nil {
+		return nil, storeError(addr, err)
+	}
+	return newObjectInfo(addr.Bucket, info), nil
+}
+
+// List is an ObjectStore interface function
+func (s *ObjectStore) List(bucket, prefix string, n int) ([]*store.ObjectInfo, error) {
+	infos, _, err := s.mi.ListMetadata(bucket, prefix, n)
+	if err != nil {
+		return nil, storeError(common.NewS3Address(bucket, prefix), err)
+	}
+	res := make([]*store.ObjectInfo, 0, len(infos))
+	for _, info := range infos {
+		res = append(res, newObjectInfo(bucket, info))
+	}
+	return res, nil
+}
+
+// Copy is an ObjectStore interface function
+func (s *ObjectStore) Copy(dst, src *common.S3Address) error {
+	return storeError(src, s.mi.CopyA(dst, src))
+}
+
+// Move is an ObjectStore interface function
+func (s *ObjectStore) Move(dst, src *common.S3Address) error {
+	if dst.String() == src.String() {
+		// Object is moved onto itself, it only has to exist
+		_, err := s.Stat(src)
+		return err
+	}
+	return storeError(src, s.mi.MoveA(dst, src))
+}
+
+// Remove is an ObjectStore interface function
+func (s *ObjectStore) Remove(addr *common.S3Address) error {
+	return s.mi.RemoveA(addr)
+}
+
+// Digest is an ObjectStore interface function
+func (s *ObjectStore) Digest(addr *common.S3Address, _type common.DigestType) (*common.Digest, error) {
+	return store.CalculateDigest(s, addr, _type)
+}
+
+// newObjectInfo converts MinIO object info
+func newObjectInfo(bucket string, info minio.ObjectInfo) *store.ObjectInfo {
+	return &store.ObjectInfo{
+		Address:      common.NewS3Address(bucket, info.Key),
+		Size:         info.Size,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Metadata:     MetadataFromUserMetadata(info.UserMetadata),
+	}
+}
+
+// storeError reports missing objects and buckets as store.ErrNotFound
+func storeError(addr *common.S3Address, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return store.NotFound(addr)
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minio
+
+import (
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// UploadParts stores parts of parallel uploads as separate objects and composes destination object out of them.
+// Parts of the upload are stored as prefix/uploadID/offset objects in the bucket.
+// MinIO requires each part, except the last one, to be at least 5MB.
+type UploadParts struct {
+	mi     *MinIO
+	bucket string
+	prefix string
+
+	// Destination provides address of the object to be composed out of parts of the upload
+	Destination func(uploadID string, metadata *common.Metadata) *common.S3Address
+}
+
+// NewUploadParts creates new UploadParts
+func NewUploadParts(
+	mi *MinIO,
+	bucket string,
+	prefix string,
+	destination func(uploadID string, metadata *common.Metadata) *common.S3Address,
+) *UploadParts {
+	return &UploadParts{
+		mi:          mi,
+		bucket:      bucket,
+		prefix:      prefix,
+		Destination: destination,
+	}
+}
+
+// partName builds name of the object of the part
+func (p *UploadParts) partName(uploadID string, offset int64) string {
+	// Zero-padded offset keeps parts sorted in listings
+	return PathJoin(p.prefix, uploadID, fmt.Sprintf("%020d", offset))
+}
+
+// PutPart stores data of the part of the upload as a separate object
+func (p *UploadParts) PutPart(uploadID string, offset int64, reader io.Reader) (int64, error) {
+	return p.mi.Put(p.bucket, p.partName(uploadID, offset), reader)
+}
+
+// Compose composes destination object out of parts of the upload and removes the parts
+func (p *UploadParts) Compose(uploadID string, metadata *common.Metadata, offsets []int64) error {
+	log.Tracef("minio.UploadParts.Compose() - start")
+	defer log.Tracef("minio.UploadParts.Compose() - end")
+
+	if p.Destination == nil {
+		return fmt.Errorf("no destination for upload %s", uploadID)
+	}
+	dst := p.Destination(uploadID, metadata)
+	if dst == nil {
+		return fmt.Errorf("no destination for upload %s", uploadID)
+	}
+
+	parts := make([]string, 0, len(offsets))
+	for _, offset := range offsets {
+		parts = append(parts, p.partName(uploadID, offset))
+	}
+
+	log.Infof("compose object %s/%s out of %d parts", dst.Bucket, dst.Object, len(parts))
+	if err := p.mi.Compose(dst.Bucket, dst.Object, p.bucket, parts); err != nil {
+		log.Errorf("unable to compose object %s/%s err:%v", dst.Bucket, dst.Object, err)
+		return err
+	}
+
+	if err := p.RemoveParts(uploadID); err != nil {
+		log.Errorf("unable to remove parts of upload %s err:%v", uploadID, err)
+	}
+
+	return nil
+}
+
+// RemoveParts removes all parts of the upload stored so far, including parts, which are not received completely
+func (p *UploadParts) RemoveParts(uploadID string) error {
+	objects, err := p.mi.List(p.bucket, PathJoin(p.prefix, uploadID)+"/", -1)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if e := p.mi.Remove(p.bucket, object.Key); e != nil {
+			log.Errorf("unable to remove part %s/%s err:%v", p.bucket, object.Key, e)
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright The TBox Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sunsingerus/tbox/pkg/api/common"
+)
+
+// ErrNotFound is reported in case object or bucket does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes an object in the store
+type ObjectInfo struct {
+	// Address specifies bucket and name of the object
+	Address *common.S3Address
+	// Size specifies size of the object's data in bytes
+	Size int64
+	// ETag specifies tag of the object's content, which changes when the content changes.
+	// Format depends on the store, thus tags can be compared within the same store only
+	ETag string
+	// LastModified specifies time the object was last written
+	LastModified time.Time
+	// Metadata specifies descriptive metadata stored with the object. Nil in case none stored
+	Metadata *common.Metadata
+}
+
+// ObjectStore is an abstraction over storage of the objects, addressed by bucket and object name, the same as in S3.
+// Buckets are created on demand. Object names may contain slashes, which are treated as folders by listing.
+type ObjectStore interface {
+	// Put creates the object out of the reader's data along with the metadata. Metadata is optional
+	Put(addr *common.S3Address, reader io.Reader, metadata *common.Metadata) (int64, error)
+	// Get opens the object for reading. Reports ErrNotFound in case the object does not exist
+	Get(addr *common.S3Address) (io.ReadCloser, error)
+	// Stat describes the object. Reports ErrNotFound in case the object does not exist
+	Stat(addr *common.S3Address) (*ObjectInfo, error)
+	// List lists at max n objects of the bucket with names having the prefix, in name order.
+	// Negative n means all objects.
+	List(bucket, prefix string, n int) ([]*ObjectInfo, error)
+	// Copy copies the src object along with its metadata into the dst object
+	Copy(dst, src *common.S3Address) error
+	// Move moves the src object along with its metadata into the dst object.
+	// Object moved onto itself is kept intact
+	Move(dst, src *common.S3Address) error
+	// Remove removes the object. Removing missing object is not an error, the same way as in S3
+	Remove(addr *common.S3Address) error
+	// Digest calculates digest of the object's data
+	Digest(addr *common.S3Address, _type common.DigestType) (*common.Digest, error)
+}
+
+// NotFound wraps ErrNotFound with the address of the object
+func NotFound(addr *common.S3Address) error {
+	return fmt.Errorf("%w: %s", ErrNotFound, addr)
+}
+
+// IsNotFound checks whether error reports missing object
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// CalculateDigest calculates digest of the object's data by reading the object from the store.
+// Can be used by stores with no better means to calculate digest.
+func CalculateDigest(s ObjectStore, addr *common.S3Address, _type common.DigestType) (*common.Digest, error) {
+	if !common.IsDigestTypeSupported(_type) {
+		return nil, fmt.Errorf("unable to calc digest - unknown digest type %v", _type)
+	}
+	reader, err := s.Get(addr)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	digest := common.NewDigest().SetType(_type)
+	if _, err := digest.CalculateReader(reader); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}